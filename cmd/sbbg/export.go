@@ -0,0 +1,98 @@
+package main
+
+import (
+	"bytes"
+	"flag"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"strings"
+
+	"networkgaming.co.uk/techtest/pkg/analytics"
+)
+
+// export - sbbg export [flags]
+// Writes a dataset of the games archived in an analytics database, the cursor to
+// carry on from next time goes to stderr (and the cursor file if given)
+func export(args []string) int {
+	flags := flag.NewFlagSet("export", flag.ContinueOnError)
+	db := flags.String("db", os.Getenv("ANALYTICS_DB"), "analytics SQLite database, ANALYTICS_DB if not given")
+	dataset := flags.String("dataset", "games", "dataset: games, rounds or results")
+	format := flags.String("format", "csv", "output format: csv, jsonl or parquet")
+	from := flags.String("from", "", "only games completed at or after, RFC 3339 or YYYY-MM-DD")
+	to := flags.String("to", "", "only games completed before, RFC 3339 or YYYY-MM-DD")
+	cursor := flags.String("cursor", "", "only games archived after an earlier export's cursor")
+	cursorFile := flags.String("cursor-file", "", "read the cursor from, and write the next one to, this file")
+	limit := flags.Int("limit", 0, "most games to export, zero for every game")
+	out := flags.String("out", "", "file to write to, stdout if not given")
+	if err := flags.Parse(args); err != nil {
+		return 2
+	}
+
+	if *db == "" {
+		fmt.Fprintln(os.Stderr, "export: -db or ANALYTICS_DB is needed")
+		return 2
+	}
+	if err := analytics.Validate(analytics.Dataset(*dataset), analytics.Format(*format)); err != nil {
+		fmt.Fprintf(os.Stderr, "export: %s\n", err)
+		return 2
+	}
+	if *cursorFile != "" && *cursor == "" {
+		saved, err := ioutil.ReadFile(*cursorFile)
+		if err != nil && !os.IsNotExist(err) {
+			fmt.Fprintf(os.Stderr, "export: %s\n", err)
+			return 1
+		}
+		*cursor = strings.TrimSpace(string(saved))
+	}
+
+	query := analytics.Query{Limit: *limit}
+	var err error
+	if query.From, err = analytics.ParseTime(*from); err != nil {
+		fmt.Fprintf(os.Stderr, "export: %s\n", err)
+		return 2
+	}
+	if query.To, err = analytics.ParseTime(*to); err != nil {
+		fmt.Fprintf(os.Stderr, "export: %s\n", err)
+		return 2
+	}
+	if query.After, err = analytics.ParseCursor(*cursor); err != nil {
+		fmt.Fprintf(os.Stderr, "export: %s\n", err)
+		return 2
+	}
+
+	store, err := analytics.NewSQLiteStore(*db)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "export: %s\n", err)
+		return 1
+	}
+	defer store.Close()
+
+	games, next, err := analytics.Export(store, query)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "export: %s\n", err)
+		return 1
+	}
+	body := &bytes.Buffer{}
+	if err := analytics.Write(body, analytics.Dataset(*dataset), analytics.Format(*format), games); err != nil {
+		fmt.Fprintf(os.Stderr, "export: %s\n", err)
+		return 1
+	}
+
+	if *out == "" {
+		os.Stdout.Write(body.Bytes())
+	} else if err := ioutil.WriteFile(*out, body.Bytes(), 0644); err != nil {
+		fmt.Fprintf(os.Stderr, "export: %s\n", err)
+		return 1
+	}
+	// Only moved on once the export is safely written
+	if *cursorFile != "" {
+		if err := ioutil.WriteFile(*cursorFile, []byte(next+"\n"), 0644); err != nil {
+			fmt.Fprintf(os.Stderr, "export: %s\n", err)
+			return 1
+		}
+	}
+	fmt.Fprintf(os.Stderr, "cursor %s\n", next)
+
+	return 0
+}
@@ -6,6 +6,7 @@ import (
 	"net/http"
 	"os"
 	"os/signal"
+	"strings"
 	"syscall"
 	"time"
 
@@ -16,12 +17,31 @@ import (
 	"github.com/rs/zerolog/hlog"
 	"github.com/rs/zerolog/log"
 
+	"networkgaming.co.uk/techtest/pkg/analytics"
 	"networkgaming.co.uk/techtest/pkg/game"
+	"networkgaming.co.uk/techtest/pkg/matchmaking"
+	"networkgaming.co.uk/techtest/pkg/names"
+	"networkgaming.co.uk/techtest/pkg/ratelimit"
 	"networkgaming.co.uk/techtest/pkg/socket"
+	"networkgaming.co.uk/techtest/pkg/tournament"
+	"networkgaming.co.uk/techtest/pkg/wallet"
+	"networkgaming.co.uk/techtest/pkg/webhook"
 )
 
 func main() {
 
+	// Sub commands
+	if len(os.Args) > 1 {
+		switch os.Args[1] {
+		case "simulate":
+			os.Exit(simulate(os.Args[2:]))
+		case "states":
+			os.Exit(states(os.Args[2:]))
+		case "export":
+			os.Exit(export(os.Args[2:]))
+		}
+	}
+
 	zerolog.TimeFieldFormat = zerolog.TimeFormatUnix
 	log.Info().Msg("Starting server")
 
@@ -33,26 +53,155 @@ func main() {
 
 	ctx, cancel := context.WithCancel(context.Background())
 
-	simple := game.NewGame(game.NewRNG(game.MaxNum))
 	engineConfig := &game.EngineConfig{
 		GameSpeed:    1 * time.Second,
 		WaitingCount: 10,
 		ManualRun:    false,
+		// Draw a little quicker every round, and leave the winner up for 10 seconds
+		RoundAcceleration: 0.02,
+		Intermission:      10 * time.Second,
+		// Fill the table if someone's been waiting on their own for 30 seconds
+		BotAfterTicks: 30,
+		BotStrategy:   "adaptive",
+		// Free up names of players who've sat at an empty table for 10 minutes
+		SeatTimeout: 10 * time.Minute,
+	}
+
+	// Wallet, in memory unless given a SQLite file to keep the ledger in
+	var walletStore wallet.Store = wallet.NewMemoryStore()
+	if path := os.Getenv("WALLET_DB"); path != "" {
+		sqliteStore, err := wallet.NewSQLiteStore(path)
+		if err != nil {
+			log.Fatal().Msg(err.Error())
+		}
+		walletStore = sqliteStore
+	}
+	gameWallet := wallet.NewWallet(walletStore, 1000)
+
+	// Completed games are archived for export, in memory unless given a SQLite file
+	var analyticsStore analytics.Store = analytics.NewMemoryStore()
+	if path := os.Getenv("ANALYTICS_DB"); path != "" {
+		sqliteStore, err := analytics.NewSQLiteStore(path)
+		if err != nil {
+			log.Fatal().Msg(err.Error())
+		}
+		analyticsStore = sqliteStore
+	}
+	archive := analytics.NewArchive(analyticsStore)
+	ratings := matchmaking.NewRatings()
+
+	// Words masked in chat, comma separated
+	chatFilter := game.NewMaskFilter(strings.Split(os.Getenv("CHAT_BLOCKLIST"), ",")...)
+
+	// Words no player name may contain, comma separated
+	namePolicy := names.DefaultPolicy()
+	namePolicy.Blocked = strings.Split(os.Getenv("NAME_BLOCKLIST"), ",")
+
+	// Every room's game is checkpointed here, games interrupted in play in the main room
+	// are resumed when it opens again unless RESTORE_POLICY is void. Only with a wallet
+	// kept in WALLET_DB, the stakes of a game in play are lost with an in-memory one.
+	var checkpoints game.CheckpointStore
+	if os.Getenv("WALLET_DB") != "" {
+		checkpointDir := os.Getenv("CHECKPOINT_DIR")
+		if checkpointDir == "" {
+			checkpointDir = "checkpoints"
+		}
+		checkpoints = game.NewFileCheckpoints(checkpointDir)
+	} else {
+		log.Warn().Msg("Checkpoints are off without WALLET_DB, games in play are called off on shutdown")
+	}
+	restorePolicy := game.RestoreResume
+	if os.Getenv("RESTORE_POLICY") == "void" {
+		restorePolicy = game.RestoreVoid
+	}
+
+	// Game events are posted to every webhook subscribed to them, deliveries that
+	// still fail after a couple of minutes of retrying are kept as dead letters
+	webhooks := webhook.NewWebhooks(&webhook.Config{
+		Workers:     4,
+		QueueSize:   1000,
+		MaxAttempts: 8,
+		Backoff:     1 * time.Second,
+		MaxBackoff:  1 * time.Minute,
+		Timeout:     5 * time.Second,
+	}, webhook.NewMemoryDeadLetters())
+	webhooks.Start(ctx)
+
+	// Every room is played for stakes and rated, tournament tables report back to their tournament
+	lobby := game.NewLobby(ctx, engineConfig)
+	lobby.Names = namePolicy
+	lobby.Checkpoints = checkpoints
+	lobby.Restore = restorePolicy
+	lobby.IdleTimeout = 5 * time.Minute
+	tournaments := tournament.NewTournaments(ctx, lobby)
+	lobby.Setup = func(room *game.Room) {
+		room.Engine.Stakes = wallet.NewPot(gameWallet, &wallet.PotConfig{
+			Stake:        10,
+			RakePercent:  5,
+			Split:        wallet.SplitEven,
+			HouseAccount: "house",
+		})
+		room.Engine.Listeners = append(room.Engine.Listeners, ratings, tournaments, archive)
+		room.Engine.Watchers = append(room.Engine.Watchers, webhooks)
+		room.Chat.Filter = chatFilter
+	}
+	// Rooms other than main don't open again, their games are called off and refunded
+	if err := lobby.Recover(game.MainRoom); err != nil {
+		log.Fatal().Msg(err.Error())
 	}
-	gameEngine := game.NewEngine(simple, engineConfig)
-	gameBroadcaster := game.NewBroadcaster(gameEngine.Event)
-	gameBroadcaster.Start(ctx)
-	gameEngine.Start()
+	mainRoom, err := lobby.Open(game.MainRoom)
+	if err != nil {
+		log.Fatal().Msg(err.Error())
+	}
+	gameEngine := mainRoom.Engine
+	lobby.Start(ctx, 1*time.Minute)
+
+	matchmaker := matchmaking.NewMatchmaker(lobby, ratings, &matchmaking.Config{
+		MatchSize:      4,
+		MinPlayers:     game.MinPlayersRequired,
+		FillAfter:      30 * time.Second,
+		InitialWindow:  100,
+		WidenPerSecond: 10,
+		MaxWindow:      800,
+		Interval:       1 * time.Second,
+	})
+	matchmaker.Start(ctx)
 
-	webSocketHandler := socket.New(gameBroadcaster)
-	joinGameHandler := game.NewJoinGameHandler(gameEngine.Action)
+	webSocketHandler := socket.New(mainRoom.Broadcaster)
+	webSocketHandler.Lobby = lobby
+	webSocketHandler.Commands = ratelimit.Rate{PerSecond: 2, Burst: 10}
+	ticketSocketHandler := socket.NewTicketHandler(matchmaker)
+	tournamentSocketHandler := socket.NewTournamentHandler(tournaments)
+	joinGameHandler := game.NewJoinGameHandler(gameEngine)
+	joinGameHandler.Accounts = gameWallet
+	joinGameHandler.Lobby = lobby
+	walletHandler := wallet.NewWalletHandler(gameWallet)
+	adminHandler := game.NewAdminHandler(gameEngine, os.Getenv("ADMIN_TOKEN"))
+	adjustGameHandler := game.NewAdjustGameHandler(lobby)
+	matchmakingHandler := matchmaking.NewMatchmakingHandler(matchmaker, ratings)
+	matchmakingHandler.Accounts = gameWallet
+	tournamentHandler := tournament.NewTournamentHandler(tournaments, adminHandler)
+	tournamentHandler.Accounts = gameWallet
+	roomHandler := game.NewRoomHandler(lobby)
+	roomHandler.Accounts = gameWallet
+	chatHandler := game.NewChatHandler(lobby, adminHandler)
+	historyHandler := game.NewHistoryHandler(lobby, adminHandler)
+	webhookHandler := webhook.NewWebhookHandler(webhooks, adminHandler)
+	exportHandler := analytics.NewExportHandler(analyticsStore, adminHandler)
+
+	// Per IP, joins of any kind and websocket connects
+	joinLimit := ratelimit.NewLimiter(ratelimit.Rate{PerSecond: 1, Burst: 5})
+	// Per IP, every wallet opened comes with a starting balance so only a few, then one an hour
+	walletLimit := ratelimit.NewLimiter(ratelimit.Rate{PerSecond: 1.0 / 3600, Burst: 3})
+	connectLimit := ratelimit.NewLimiter(ratelimit.Rate{PerSecond: 1, Burst: 10})
+	socketConns := ratelimit.NewConns(10)
 
 	var allowedOrigins []string
 	allowedOrigins = append(allowedOrigins, "http://localhost:8091")
 
 	crossOrigin := cors.New(cors.Options{
 		AllowedOrigins: allowedOrigins,
-		AllowedMethods: []string{"GET", "POST", "OPTIONS"},
+		AllowedMethods: []string{"GET", "POST", "DELETE", "OPTIONS"},
 		AllowedHeaders: []string{"Accept", "Authorization", "Content-Type"},
 		MaxAge:         300,
 	})
@@ -66,11 +215,77 @@ func main() {
 	})
 
 	router.Route("/subscribe", func(r chi.Router) {
-		r.Get("/", webSocketHandler.Subscribe)
+		r.With(connectLimit.Middleware, socketConns.Middleware).Get("/", webSocketHandler.Subscribe)
 	})
 
 	router.Route("/join", func(r chi.Router) {
-		r.Post("/", joinGameHandler.JoinGame)
+		r.With(joinLimit.Middleware).Post("/", joinGameHandler.JoinGame)
+	})
+
+	router.Route("/adjust", func(r chi.Router) {
+		r.With(joinLimit.Middleware).Post("/", adjustGameHandler.AdjustGame)
+	})
+
+	router.Route("/rooms", func(r chi.Router) {
+		r.Post("/", roomHandler.Create)
+		r.Delete("/{code}", roomHandler.Close)
+		r.With(joinLimit.Middleware).Post("/{code}/join", roomHandler.Join)
+		r.Post("/{code}/start", roomHandler.Start)
+		r.Post("/{code}/kick", roomHandler.Kick)
+		r.Post("/{code}/settings", roomHandler.Configure)
+	})
+
+	router.Route("/chat", func(r chi.Router) {
+		r.Post("/{room}/mute", chatHandler.Mute)
+		r.Post("/{room}/unmute", chatHandler.Unmute)
+		r.Post("/{room}/ban", chatHandler.Ban)
+	})
+
+	router.Route("/history", func(r chi.Router) {
+		r.Get("/{room}/log", historyHandler.Log)
+		r.Get("/{room}/rounds/{round}", historyHandler.Round)
+		r.Get("/{room}/leaderboard", historyHandler.Leaderboard)
+		r.Get("/{room}/stats", historyHandler.Stats)
+	})
+
+	router.Route("/webhooks", func(r chi.Router) {
+		r.Get("/", webhookHandler.List)
+		r.Post("/", webhookHandler.Subscribe)
+		r.Delete("/{id}", webhookHandler.Unsubscribe)
+		r.Get("/{id}/deliveries", webhookHandler.Deliveries)
+		r.Get("/{id}/dead-letters", webhookHandler.DeadLetters)
+		r.Post("/{id}/dead-letters/{delivery}/redeliver", webhookHandler.Redeliver)
+	})
+
+	router.Route("/export", func(r chi.Router) {
+		r.Get("/{dataset}", exportHandler.Export)
+	})
+
+	router.Route("/admin", func(r chi.Router) {
+		r.Post("/bots", adminHandler.AddBots)
+	})
+
+	router.Route("/matchmaking", func(r chi.Router) {
+		r.With(joinLimit.Middleware).Post("/", matchmakingHandler.Enqueue)
+		r.Delete("/", matchmakingHandler.Cancel)
+		r.Get("/rating", matchmakingHandler.GetRating)
+		r.With(connectLimit.Middleware, socketConns.Middleware).Get("/subscribe", ticketSocketHandler.Subscribe)
+	})
+
+	router.Route("/tournaments", func(r chi.Router) {
+		r.Get("/", tournamentHandler.List)
+		r.Post("/", tournamentHandler.Create)
+		r.Get("/{id}", tournamentHandler.Get)
+		r.Delete("/{id}", tournamentHandler.Cancel)
+		r.With(joinLimit.Middleware).Post("/{id}/register", tournamentHandler.Register)
+		r.Post("/{id}/start", tournamentHandler.Start)
+		r.With(connectLimit.Middleware, socketConns.Middleware).Get("/{id}/subscribe", tournamentSocketHandler.Subscribe)
+	})
+
+	router.Route("/wallet", func(r chi.Router) {
+		r.With(walletLimit.Middleware).Post("/", walletHandler.Open)
+		r.Get("/", walletHandler.GetBalance)
+		r.Get("/transactions", walletHandler.GetTransactions)
 	})
 
 	srv := &http.Server{
@@ -89,17 +304,29 @@ func main() {
 		}
 	}()
 
-	// Graceful Shutdown
-	waitForShutdown(srv, cancel)
+	// Graceful Shutdown, games in play get SHUTDOWN_WAIT to finish, 30s by default, 0 doesn't wait
+	shutdownWait := 30 * time.Second
+	if wait, err := time.ParseDuration(os.Getenv("SHUTDOWN_WAIT")); err == nil {
+		shutdownWait = wait
+	}
+	waitForShutdown(srv, lobby, shutdownWait, cancel)
 }
 
-func waitForShutdown(srv *http.Server, cancel context.CancelFunc) {
+func waitForShutdown(srv *http.Server, lobby *game.Lobby, wait time.Duration, cancel context.CancelFunc) {
 
 	interruptChan := make(chan os.Signal, 1)
 	signal.Notify(interruptChan, os.Interrupt, syscall.SIGINT, syscall.SIGTERM)
 
 	// Block until we receive our signal.
 	<-interruptChan
+	log.Info().Msg("Draining")
+	// Rooms first, while their subscribers can still hear why
+	drain, cancelDrain := context.WithTimeout(context.Background(), wait)
+	defer cancelDrain()
+	if err := lobby.Shutdown(drain, game.ShutdownReason, wait > 0); err != nil {
+		log.Warn().Msg("Out of time to drain, any game still in play was checkpointed")
+	}
+	// Then matchmaking and tournaments
 	cancel()
 	// Create a deadline to wait for.
 	ctx, cancel := context.WithTimeout(context.Background(), time.Second*10)
@@ -0,0 +1,135 @@
+package main
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+
+	"networkgaming.co.uk/techtest/pkg/game"
+)
+
+// simulate - sbbg simulate [flags]
+// Plays headless games for every scoring and win condition combination
+func simulate(args []string) int {
+	flags := flag.NewFlagSet("simulate", flag.ContinueOnError)
+	games := flags.Int("games", 10000, "games to play per rule set")
+	seed := flags.Int64("seed", 1, "seed for the first game, each game after adds one")
+	strategies := flags.String("strategies", "wide,greedy,adaptive,random", "comma separated bot strategies, one seat each (fixed:3-7 always plays 3 to 7)")
+	scoring := flags.String("scoring", "classic", "comma separated scoring strategies: "+strings.Join(game.ScoringStrategyNames(), ", "))
+	condition := flags.String("condition", "max-rounds", "comma separated win conditions: "+strings.Join(game.WinConditionNames(), ", "))
+	picks := flags.Int("picks", game.DefaultPicksPerPlayer, "picks per player")
+	format := flags.String("format", "table", "output format: table, csv or json")
+	if err := flags.Parse(args); err != nil {
+		return 2
+	}
+
+	results := []game.SimulationResult{}
+	for _, scoringName := range split(*scoring) {
+		for _, conditionName := range split(*condition) {
+			result, err := game.Simulate(game.SimulationConfig{
+				Games:          *games,
+				Seed:           *seed,
+				Strategies:     split(*strategies),
+				Scoring:        scoringName,
+				Condition:      conditionName,
+				PicksPerPlayer: *picks,
+			})
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "simulate %s/%s: %s\n", scoringName, conditionName, err.Error())
+				return 1
+			}
+			results = append(results, result)
+		}
+	}
+
+	var err error
+	switch *format {
+	case "table":
+		err = writeSimulationTable(os.Stdout, results)
+	case "csv":
+		err = writeSimulationCSV(os.Stdout, results)
+	case "json":
+		encoder := json.NewEncoder(os.Stdout)
+		encoder.SetIndent("", "  ")
+		err = encoder.Encode(results)
+	default:
+		fmt.Fprintf(os.Stderr, "simulate: unknown format %q\n", *format)
+		return 2
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "simulate: %s\n", err.Error())
+		return 1
+	}
+
+	return 0
+}
+
+var simulationColumns = []string{
+	"scoring", "condition", "games", "rogue_rate", "mean_rounds",
+	"strategy", "seats", "wins", "win_rate", "rogue_wins",
+	"mean_score", "min_score", "p25_score", "median_score", "p75_score", "max_score",
+}
+
+// simulationRows - One row per strategy per rule set
+func simulationRows(results []game.SimulationResult) [][]string {
+	rows := [][]string{}
+	for _, result := range results {
+		for _, stats := range result.Strategies {
+			rows = append(rows, []string{
+				result.Scoring,
+				result.Condition,
+				strconv.Itoa(result.Games),
+				strconv.FormatFloat(result.RogueRate, 'f', 4, 64),
+				strconv.FormatFloat(result.MeanRounds, 'f', 2, 64),
+				stats.Strategy,
+				strconv.Itoa(stats.Seats),
+				strconv.Itoa(stats.Wins),
+				strconv.FormatFloat(stats.WinRate, 'f', 4, 64),
+				strconv.Itoa(stats.RogueWins),
+				strconv.FormatFloat(stats.MeanScore, 'f', 2, 64),
+				strconv.Itoa(stats.MinScore),
+				strconv.Itoa(stats.LowerScore),
+				strconv.Itoa(stats.MedScore),
+				strconv.Itoa(stats.UpperScore),
+				strconv.Itoa(stats.MaxScore),
+			})
+		}
+	}
+
+	return rows
+}
+
+func writeSimulationTable(w io.Writer, results []game.SimulationResult) error {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', tabwriter.AlignRight)
+	fmt.Fprintln(tw, strings.Join(simulationColumns, "\t")+"\t")
+	for _, row := range simulationRows(results) {
+		fmt.Fprintln(tw, strings.Join(row, "\t")+"\t")
+	}
+
+	return tw.Flush()
+}
+
+func writeSimulationCSV(w io.Writer, results []game.SimulationResult) error {
+	cw := csv.NewWriter(w)
+	cw.Write(simulationColumns)
+	cw.WriteAll(simulationRows(results))
+
+	return cw.Error()
+}
+
+func split(list string) []string {
+	parts := []string{}
+	for _, part := range strings.Split(list, ",") {
+		if part = strings.TrimSpace(part); part != "" {
+			parts = append(parts, part)
+		}
+	}
+
+	return parts
+}
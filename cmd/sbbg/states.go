@@ -0,0 +1,31 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+
+	"networkgaming.co.uk/techtest/pkg/game"
+)
+
+// states - sbbg states [flags]
+// Prints the game's state transition table as a diagram
+func states(args []string) int {
+	flags := flag.NewFlagSet("states", flag.ContinueOnError)
+	format := flags.String("format", "mermaid", "output format: mermaid or dot")
+	if err := flags.Parse(args); err != nil {
+		return 2
+	}
+
+	switch *format {
+	case "mermaid":
+		fmt.Print(game.MermaidDiagram())
+	case "dot":
+		fmt.Print(game.StateDiagram())
+	default:
+		fmt.Fprintf(os.Stderr, "states: unknown format %q\n", *format)
+		return 2
+	}
+
+	return 0
+}
@@ -1,13 +1,23 @@
 module networkgaming.co.uk/techtest
 
-go 1.12
+go 1.19
 
 require (
 	github.com/go-chi/chi v4.0.3+incompatible
 	github.com/go-chi/cors v1.0.1
 	github.com/gorilla/websocket v1.4.2
+	github.com/mattn/go-sqlite3 v1.14.0
 	github.com/rs/zerolog v1.18.0
 	github.com/stretchr/testify v1.5.1
+	golang.org/x/text v0.3.0
+)
+
+require (
+	github.com/davecgh/go-spew v1.1.0 // indirect
+	github.com/pmezard/go-difflib v1.0.0 // indirect
+	github.com/rs/xid v1.2.1 // indirect
+	github.com/zenazn/goji v0.9.0 // indirect
+	gopkg.in/yaml.v2 v2.2.2 // indirect
 )
 
 replace gopkg.in/urfave/cli.v1 => github.com/urfave/cli v1.21.0
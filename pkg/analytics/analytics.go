@@ -0,0 +1,145 @@
+package analytics
+
+import (
+	"log"
+	"sort"
+	"time"
+
+	"networkgaming.co.uk/techtest/pkg/game"
+)
+
+// Game - A completed game as it's exported. ID counts up in the order games
+// were archived, which is what export cursors follow.
+type Game struct {
+	ID          int64     `json:"game_id"`
+	Room        string    `json:"room"`
+	Scoring     string    `json:"scoring"`
+	Condition   string    `json:"condition"`
+	SuddenDeath bool      `json:"sudden_death"`
+	Winner      string    `json:"winner"`
+	StartedAt   time.Time `json:"started_at"`
+	CompletedAt time.Time `json:"completed_at"`
+	Rounds      []Round   `json:"rounds"`
+	Results     []Result  `json:"results"`
+}
+
+// Round - A number drawn, and who led once it had been scored
+type Round struct {
+	Round       int       `json:"round"`
+	Number      int       `json:"number"`
+	DrawnAt     time.Time `json:"drawn_at"`
+	Leader      string    `json:"leader"`
+	LeaderScore int       `json:"leader_score"`
+}
+
+// Result - How a player finished, players on the same score share a rank
+type Result struct {
+	Player     string `json:"player"`
+	Bot        bool   `json:"bot"`
+	Score      int    `json:"score"`
+	Rank       int    `json:"rank"`
+	Winner     bool   `json:"winner"`
+	Bust       bool   `json:"bust"`
+	Eliminated bool   `json:"eliminated"`
+}
+
+// FromResult - The game a result describes, rounds are replayed from its log.
+// A game resumed after a restart only has the rounds played since.
+func FromResult(result game.GameResult) Game {
+	g := Game{
+		Room:        result.Room,
+		Scoring:     orDefault(result.Scoring, "classic"),
+		Condition:   orDefault(result.Condition, "max-rounds"),
+		SuddenDeath: result.SuddenDeath,
+		Winner:      result.Winner.Name,
+		StartedAt:   result.StartedAt.UTC(),
+		CompletedAt: result.CompletedAt.UTC(),
+		Rounds:      rounds(result.Log),
+		Results:     make([]Result, 0, len(result.LeaderBoard)),
+	}
+
+	board := append([]game.GamePlayer{}, result.LeaderBoard...)
+	sort.SliceStable(board, func(i, j int) bool {
+		return board[i].Score > board[j].Score
+	})
+	for i, player := range board {
+		rank := i + 1
+		if i > 0 && player.Score == board[i-1].Score {
+			rank = g.Results[i-1].Rank
+		}
+		g.Results = append(g.Results, Result{
+			Player:     player.Name,
+			Bot:        player.Bot,
+			Score:      player.Score,
+			Rank:       rank,
+			Winner:     player.Name == result.Winner.Name,
+			Bust:       player.Bust,
+			Eliminated: player.Eliminated,
+		})
+	}
+
+	return g
+}
+
+// rounds - Each round in the log with the leader once it was scored
+func rounds(events []game.DomainEvent) []Round {
+	played := []Round{}
+	scores := make(map[string]int)
+	for _, event := range events {
+		switch event.Type {
+		case game.DomainGameRestored:
+			for name, player := range event.Snapshot.Players {
+				scores[name] = player.Score
+			}
+		case game.DomainPlayerSeated, game.DomainScoreChanged, game.DomainWinnerNominated:
+			scores[event.Player.Name] = event.Player.Score
+		case game.DomainPlayerLeft:
+			delete(scores, event.Player.Name)
+		case game.DomainNumberDrawn:
+			if len(played) > 0 {
+				lead(&played[len(played)-1], scores)
+			}
+			played = append(played, Round{Round: event.Round, Number: event.Number, DrawnAt: event.At.UTC()})
+		}
+	}
+	if len(played) > 0 {
+		lead(&played[len(played)-1], scores)
+	}
+
+	return played
+}
+
+// lead - Set the round's leader, ties go to the first name alphabetically
+func lead(round *Round, scores map[string]int) {
+	for name, score := range scores {
+		if round.Leader == "" || score > round.LeaderScore || (score == round.LeaderScore && name < round.Leader) {
+			round.Leader = name
+			round.LeaderScore = score
+		}
+	}
+}
+
+func orDefault(name string, fallback string) string {
+	if name == "" {
+		return fallback
+	}
+
+	return name
+}
+
+// Archive - Keeps every completed game for export.
+// Implements game.ResultListener, add it to the listeners of every room to archive.
+type Archive struct {
+	store Store
+}
+
+func NewArchive(store Store) *Archive {
+	return &Archive{store}
+}
+
+// GameCompleted - See game.ResultListener
+func (a *Archive) GameCompleted(result game.GameResult) {
+	if _, err := a.store.Append(FromResult(result)); err != nil {
+		log.Printf("Unable to archive game in %s: %s\n", result.Room, err.Error())
+	}
+}
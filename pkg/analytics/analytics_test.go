@@ -0,0 +1,284 @@
+package analytics
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/go-chi/chi"
+	"github.com/stretchr/testify/assert"
+	"networkgaming.co.uk/techtest/pkg/game"
+)
+
+var start = time.Date(2026, 10, 19, 12, 0, 0, 0, time.UTC)
+
+// archived - n games completed a minute apart, each with two rounds and two players
+func archived(t *testing.T, store Store, n int) {
+	for i := 0; i < n; i++ {
+		at := start.Add(time.Duration(i) * time.Minute)
+		_, err := store.Append(Game{
+			Room:        "main",
+			Scoring:     "classic",
+			Condition:   "max-rounds",
+			Winner:      "Steve",
+			StartedAt:   at.Add(-30 * time.Second),
+			CompletedAt: at,
+			Rounds: []Round{
+				{Round: 1, Number: 3, DrawnAt: at.Add(-20 * time.Second), Leader: "Steve", LeaderScore: 1},
+				{Round: 2, Number: 7, DrawnAt: at.Add(-10 * time.Second), Leader: "Steve", LeaderScore: 2},
+			},
+			Results: []Result{
+				{Player: "Steve", Score: 2, Rank: 1, Winner: true},
+				{Player: "Sarah", Bot: true, Score: -2, Rank: 2},
+			},
+		})
+		assert.Nil(t, err)
+	}
+}
+
+func TestArchive(t *testing.T) {
+	assert := assert.New(t)
+
+	clock := game.NewFakeClock(start)
+	g := game.NewGame(game.NewSeededRNG(game.MaxNum, 5))
+	g.Clock = clock
+	engine := game.NewEngine(g, &game.EngineConfig{GameSpeed: 1 * time.Second, WaitingCount: 3})
+	engine.Clock = clock
+	engine.Room = game.MainRoom
+	store := NewMemoryStore()
+	engine.Listeners = append(engine.Listeners, NewArchive(store))
+	engine.Start(context.Background())
+	go func() {
+		for range engine.Event {
+		}
+	}()
+
+	ctx := context.Background()
+	engine.Do(ctx, &game.Action{Type: game.ActionTypeJoinGame, Player: &game.Player{Name: "Steve", First: 3, Second: 7}})
+	engine.Do(ctx, &game.Action{Type: game.ActionTypeJoinGame, Player: &game.Player{Name: "Sarah", First: 4, Second: 8}})
+	clock.Advance(10 * time.Minute)
+	engine.Stop(ctx)
+
+	games, _ := store.List(Query{})
+	assert.True(len(games) > 0)
+	archived := games[0]
+	assert.Equal(int64(1), archived.ID)
+	assert.Equal(game.MainRoom, archived.Room)
+	assert.Equal("classic", archived.Scoring)
+	assert.Equal("max-rounds", archived.Condition)
+	assert.True(archived.StartedAt.After(start))
+	assert.True(archived.CompletedAt.After(archived.StartedAt))
+
+	assert.NotEmpty(archived.Rounds)
+	for i, round := range archived.Rounds {
+		assert.Equal(i+1, round.Round)
+		assert.True(round.Number >= game.MinNum && round.Number <= game.MaxNum)
+		assert.Contains([]string{"Steve", "Sarah"}, round.Leader)
+	}
+
+	assert.Len(archived.Results, 2)
+	assert.Equal(1, archived.Results[0].Rank)
+	assert.True(archived.Results[0].Score >= archived.Results[1].Score)
+	assert.Equal(archived.Winner, archived.Results[0].Player)
+	assert.True(archived.Results[0].Winner)
+	assert.False(archived.Results[1].Winner)
+}
+
+func TestFromResultRanks(t *testing.T) {
+	assert := assert.New(t)
+
+	g := FromResult(game.GameResult{
+		Room:   "main",
+		Winner: game.GamePlayer{Name: "Sue"},
+		LeaderBoard: []game.GamePlayer{
+			{Name: "Steve", Score: 4},
+			{Name: "Sue", Score: 9},
+			{Name: "Sarah", Score: 4, Bust: true},
+			{Name: "Simon", Score: 1},
+		},
+		Scoring: "blackjack",
+	})
+
+	ranks := map[string]int{}
+	for _, result := range g.Results {
+		ranks[result.Player] = result.Rank
+	}
+	assert.Equal(map[string]int{"Sue": 1, "Steve": 2, "Sarah": 2, "Simon": 4}, ranks)
+	assert.Equal("blackjack", g.Scoring)
+	assert.Equal("max-rounds", g.Condition)
+	assert.Empty(g.Rounds)
+}
+
+func TestStores(t *testing.T) {
+	assert := assert.New(t)
+
+	sqlite, err := NewSQLiteStore(filepath.Join(t.TempDir(), "analytics.db"))
+	assert.Nil(err)
+	defer sqlite.Close()
+
+	for _, store := range []Store{NewMemoryStore(), sqlite} {
+		archived(t, store, 5)
+
+		games, err := store.List(Query{})
+		assert.Nil(err)
+		assert.Len(games, 5)
+		assert.Equal(int64(1), games[0].ID)
+		assert.Equal(start, games[0].CompletedAt)
+		assert.Equal(2, len(games[0].Rounds))
+		assert.Equal("Sarah", games[0].Results[1].Player)
+
+		// Completed from 12:01 up to but not including 12:04
+		games, _ = store.List(Query{From: start.Add(time.Minute), To: start.Add(4 * time.Minute)})
+		assert.Len(games, 3)
+		assert.Equal(int64(2), games[0].ID)
+
+		games, _ = store.List(Query{After: 3})
+		assert.Len(games, 2)
+		games, _ = store.List(Query{After: 1, Limit: 2})
+		assert.Len(games, 2)
+		assert.Equal(int64(3), games[1].ID)
+	}
+}
+
+func TestExportCursor(t *testing.T) {
+	assert := assert.New(t)
+	store := NewMemoryStore()
+	archived(t, store, 3)
+
+	games, cursor, err := Export(store, Query{Limit: 2})
+	assert.Nil(err)
+	assert.Len(games, 2)
+	assert.Equal("2", cursor)
+
+	after, err := ParseCursor(cursor)
+	assert.Nil(err)
+	games, cursor, _ = Export(store, Query{After: after})
+	assert.Len(games, 1)
+	assert.Equal("3", cursor)
+
+	// Nothing new, the cursor stays put
+	games, cursor, _ = Export(store, Query{After: 3})
+	assert.Empty(games)
+	assert.Equal("3", cursor)
+
+	_, err = ParseCursor("-1")
+	assert.Equal(ErrInvalidCursor, err)
+	_, err = ParseCursor("soon")
+	assert.Equal(ErrInvalidCursor, err)
+}
+
+func TestParseTime(t *testing.T) {
+	assert := assert.New(t)
+
+	day, err := ParseTime("2026-10-19")
+	assert.Nil(err)
+	assert.Equal(time.Date(2026, 10, 19, 0, 0, 0, 0, time.UTC), day)
+	at, err := ParseTime("2026-10-19T13:00:00+01:00")
+	assert.Nil(err)
+	assert.Equal(start, at)
+	_, err = ParseTime("19/10/2026")
+	assert.Equal(ErrInvalidTime, err)
+}
+
+func TestWriteCSV(t *testing.T) {
+	assert := assert.New(t)
+	store := NewMemoryStore()
+	archived(t, store, 2)
+	games, _ := store.List(Query{})
+
+	out := &bytes.Buffer{}
+	assert.Nil(Write(out, DatasetGames, FormatCSV, games))
+	records, err := csv.NewReader(out).ReadAll()
+	assert.Nil(err)
+	assert.Len(records, 3)
+	assert.Equal([]string{"game_id", "room", "scoring", "condition", "sudden_death", "started_at", "completed_at", "rounds", "players", "winner"}, records[0])
+	assert.Equal([]string{"1", "main", "classic", "max-rounds", "false", "2026-10-19T11:59:30Z", "2026-10-19T12:00:00Z", "2", "2", "Steve"}, records[1])
+
+	out.Reset()
+	assert.Nil(Write(out, DatasetResults, FormatCSV, games))
+	records, _ = csv.NewReader(out).ReadAll()
+	assert.Len(records, 5)
+	assert.Equal([]string{"2", "Sarah", "true", "-2", "2", "false", "false", "false"}, records[4])
+
+	assert.Equal(ErrUnknownDataset, Write(out, Dataset("draws"), FormatCSV, games))
+	assert.Equal(ErrUnknownFormat, Write(out, DatasetGames, Format("xlsx"), games))
+}
+
+func TestWriteJSONL(t *testing.T) {
+	assert := assert.New(t)
+	store := NewMemoryStore()
+	archived(t, store, 2)
+	games, _ := store.List(Query{})
+
+	out := &bytes.Buffer{}
+	assert.Nil(Write(out, DatasetRounds, FormatJSONL, games))
+	lines := []string{}
+	scanner := bufio.NewScanner(out)
+	for scanner.Scan() {
+		lines = append(lines, scanner.Text())
+	}
+	assert.Len(lines, 4)
+	assert.Equal(`{"game_id":1,"round":1,"number":3,"drawn_at":"2026-10-19T11:59:40Z","leader":"Steve","leader_score":1}`, lines[0])
+
+	row := map[string]interface{}{}
+	assert.Nil(json.Unmarshal([]byte(lines[3]), &row))
+	assert.Equal(float64(2), row["game_id"])
+	assert.Equal(float64(7), row["number"])
+}
+
+func TestExportHandler(t *testing.T) {
+	assert := assert.New(t)
+	store := NewMemoryStore()
+	archived(t, store, 3)
+	handler := NewExportHandler(store, game.NewAdminHandler(nil, "admin-token"))
+
+	router := chi.NewRouter()
+	router.Get("/export/{dataset}", handler.Export)
+	request := func(path string, token string) *httptest.ResponseRecorder {
+		r := httptest.NewRequest("GET", path, nil)
+		if token != "" {
+			r.Header.Set("Authorization", "Bearer "+token)
+		}
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, r)
+		return w
+	}
+
+	w := request("/export/games", "")
+	assert.Equal(http.StatusUnauthorized, w.Code)
+	w = request("/export/draws", "admin-token")
+	assert.Equal(http.StatusNotFound, w.Code)
+	w = request("/export/games?format=xml", "admin-token")
+	assert.Equal(http.StatusUnprocessableEntity, w.Code)
+	w = request("/export/games?from=yesterday", "admin-token")
+	assert.Equal(http.StatusBadRequest, w.Code)
+	w = request("/export/games?limit=-1", "admin-token")
+	assert.Equal(http.StatusBadRequest, w.Code)
+
+	w = request("/export/games?limit=2", "admin-token")
+	assert.Equal(http.StatusOK, w.Code)
+	assert.Equal("text/csv", w.Header().Get("Content-Type"))
+	assert.Equal("2", w.Header().Get(CursorHeader))
+	assert.Equal("1", w.Header().Get(SchemaHeader))
+	assert.Equal(3, strings.Count(w.Body.String(), "\n"))
+
+	w = request("/export/results?format=jsonl&cursor=2", "admin-token")
+	assert.Equal(http.StatusOK, w.Code)
+	assert.Equal("application/x-ndjson", w.Header().Get("Content-Type"))
+	assert.Equal("3", w.Header().Get(CursorHeader))
+	assert.Equal(2, strings.Count(w.Body.String(), "\n"))
+
+	w = request("/export/rounds?format=parquet&from=2026-10-19T12:01:00Z&to=2026-10-19T12:02:00Z", "admin-token")
+	assert.Equal(http.StatusOK, w.Code)
+	assert.Equal("application/vnd.apache.parquet", w.Header().Get("Content-Type"))
+	assert.Equal("2", w.Header().Get(CursorHeader))
+	assert.True(strings.HasPrefix(w.Body.String(), parquetMagic))
+}
@@ -0,0 +1,266 @@
+package analytics
+
+import (
+	"bufio"
+	"encoding/csv"
+	"encoding/json"
+	"io"
+	"net/http"
+	"strconv"
+	"time"
+
+	"networkgaming.co.uk/techtest/pkg/problem"
+)
+
+const (
+	// SchemaVersion - Bumped whenever a column is renamed, retyped or removed.
+	// New columns are only ever added at the end.
+	SchemaVersion = 1
+)
+
+var (
+	ErrUnknownDataset = problem.New("unknown_dataset", http.StatusNotFound, "Invalid export: Dataset must be games, rounds or results")
+	ErrUnknownFormat  = problem.New("unknown_format", http.StatusUnprocessableEntity, "Invalid export: Format must be csv, jsonl or parquet")
+	ErrInvalidCursor  = problem.New("invalid_cursor", http.StatusBadRequest, "Invalid export: Cursor must be one returned by an earlier export")
+	ErrInvalidTime    = problem.New("invalid_time", http.StatusBadRequest, "Invalid export: Times must be RFC 3339 or YYYY-MM-DD")
+)
+
+// Dataset - A table of the export
+type Dataset string
+
+const (
+	DatasetGames   Dataset = "games"
+	DatasetRounds  Dataset = "rounds"
+	DatasetResults Dataset = "results"
+)
+
+// Format - How a dataset is written
+type Format string
+
+const (
+	FormatCSV     Format = "csv"
+	FormatJSONL   Format = "jsonl"
+	FormatParquet Format = "parquet"
+)
+
+// ContentType - The media type of each format
+var ContentType = map[Format]string{
+	FormatCSV:     "text/csv",
+	FormatJSONL:   "application/x-ndjson",
+	FormatParquet: "application/vnd.apache.parquet",
+}
+
+// ColumnType - What a column holds. Times are UTC, RFC 3339 strings in CSV and
+// JSON Lines and millisecond timestamps in Parquet.
+type ColumnType int
+
+const (
+	ColumnString ColumnType = iota
+	ColumnInt
+	ColumnBool
+	ColumnTime
+)
+
+// Column - A named, typed column of a dataset
+type Column struct {
+	Name string
+	Type ColumnType
+}
+
+// Schemas - Every dataset's columns, in the order they're written
+var Schemas = map[Dataset][]Column{
+	DatasetGames: {
+		{"game_id", ColumnInt},
+		{"room", ColumnString},
+		{"scoring", ColumnString},
+		{"condition", ColumnString},
+		{"sudden_death", ColumnBool},
+		{"started_at", ColumnTime},
+		{"completed_at", ColumnTime},
+		{"rounds", ColumnInt},
+		{"players", ColumnInt},
+		{"winner", ColumnString},
+	},
+	DatasetRounds: {
+		{"game_id", ColumnInt},
+		{"round", ColumnInt},
+		{"number", ColumnInt},
+		{"drawn_at", ColumnTime},
+		{"leader", ColumnString},
+		{"leader_score", ColumnInt},
+	},
+	DatasetResults: {
+		{"game_id", ColumnInt},
+		{"player", ColumnString},
+		{"bot", ColumnBool},
+		{"score", ColumnInt},
+		{"rank", ColumnInt},
+		{"winner", ColumnBool},
+		{"bust", ColumnBool},
+		{"eliminated", ColumnBool},
+	},
+}
+
+// Validate - Whether dataset and format can be exported
+func Validate(dataset Dataset, format Format) error {
+	if _, ok := Schemas[dataset]; !ok {
+		return ErrUnknownDataset
+	}
+	if _, ok := ContentType[format]; !ok {
+		return ErrUnknownFormat
+	}
+
+	return nil
+}
+
+// Export - The games query picks and the cursor to export from next time,
+// which is the cursor given back if there were none
+func Export(store Store, query Query) ([]Game, string, error) {
+	games, err := store.List(query)
+	if err != nil {
+		return nil, "", err
+	}
+	after := query.After
+	if len(games) > 0 {
+		after = games[len(games)-1].ID
+	}
+
+	return games, strconv.FormatInt(after, 10), nil
+}
+
+// ParseCursor - The game ID a cursor picks up after, empty is from the start
+func ParseCursor(cursor string) (int64, error) {
+	if cursor == "" {
+		return 0, nil
+	}
+	after, err := strconv.ParseInt(cursor, 10, 64)
+	if err != nil || after < 0 {
+		return 0, ErrInvalidCursor
+	}
+
+	return after, nil
+}
+
+// ParseTime - An RFC 3339 time or a UTC date, empty is the zero time
+func ParseTime(value string) (time.Time, error) {
+	if value == "" {
+		return time.Time{}, nil
+	}
+	if t, err := time.Parse(time.RFC3339, value); err == nil {
+		return t.UTC(), nil
+	}
+	if t, err := time.Parse("2006-01-02", value); err == nil {
+		return t, nil
+	}
+
+	return time.Time{}, ErrInvalidTime
+}
+
+// Write - The dataset's rows for games in format
+func Write(w io.Writer, dataset Dataset, format Format, games []Game) error {
+	if err := Validate(dataset, format); err != nil {
+		return err
+	}
+	columns := Schemas[dataset]
+	table := rows(dataset, games)
+
+	switch format {
+	case FormatCSV:
+		return writeCSV(w, columns, table)
+	case FormatJSONL:
+		return writeJSONL(w, columns, table)
+	}
+
+	return writeParquet(w, columns, table)
+}
+
+// rows - One value per column for every row of the dataset
+func rows(dataset Dataset, games []Game) [][]interface{} {
+	table := [][]interface{}{}
+	for _, g := range games {
+		switch dataset {
+		case DatasetGames:
+			table = append(table, []interface{}{
+				g.ID, g.Room, g.Scoring, g.Condition, g.SuddenDeath,
+				g.StartedAt, g.CompletedAt, int64(len(g.Rounds)), int64(len(g.Results)), g.Winner,
+			})
+		case DatasetRounds:
+			for _, r := range g.Rounds {
+				table = append(table, []interface{}{
+					g.ID, int64(r.Round), int64(r.Number), r.DrawnAt, r.Leader, int64(r.LeaderScore),
+				})
+			}
+		case DatasetResults:
+			for _, r := range g.Results {
+				table = append(table, []interface{}{
+					g.ID, r.Player, r.Bot, int64(r.Score), int64(r.Rank), r.Winner, r.Bust, r.Eliminated,
+				})
+			}
+		}
+	}
+
+	return table
+}
+
+// text - A value as it's written in CSV
+func text(value interface{}) string {
+	switch v := value.(type) {
+	case string:
+		return v
+	case int64:
+		return strconv.FormatInt(v, 10)
+	case bool:
+		return strconv.FormatBool(v)
+	case time.Time:
+		return v.UTC().Format(time.RFC3339Nano)
+	}
+
+	return ""
+}
+
+// writeCSV - A header of column names, then every row
+func writeCSV(w io.Writer, columns []Column, table [][]interface{}) error {
+	out := csv.NewWriter(w)
+	header := make([]string, len(columns))
+	for i, column := range columns {
+		header[i] = column.Name
+	}
+	out.Write(header)
+	for _, row := range table {
+		record := make([]string, len(row))
+		for i, value := range row {
+			record[i] = text(value)
+		}
+		out.Write(record)
+	}
+	out.Flush()
+
+	return out.Error()
+}
+
+// writeJSONL - A JSON object per row, keys in column order
+func writeJSONL(w io.Writer, columns []Column, table [][]interface{}) error {
+	out := bufio.NewWriter(w)
+	for _, row := range table {
+		out.WriteByte('{')
+		for i, value := range row {
+			if i > 0 {
+				out.WriteByte(',')
+			}
+			key, _ := json.Marshal(columns[i].Name)
+			out.Write(key)
+			out.WriteByte(':')
+			if t, ok := value.(time.Time); ok {
+				value = text(t)
+			}
+			encoded, err := json.Marshal(value)
+			if err != nil {
+				return err
+			}
+			out.Write(encoded)
+		}
+		out.WriteString("}\n")
+	}
+
+	return out.Flush()
+}
@@ -0,0 +1,101 @@
+package analytics
+
+import (
+	"bytes"
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/go-chi/chi"
+	"networkgaming.co.uk/techtest/pkg/game"
+	"networkgaming.co.uk/techtest/pkg/problem"
+)
+
+const (
+	// CursorHeader - The cursor to pass for the next export
+	CursorHeader = "X-Export-Cursor"
+	// SchemaHeader - The SchemaVersion the export was written with
+	SchemaHeader = "X-Export-Schema"
+)
+
+var (
+	ErrInvalidLimit = problem.New("invalid_limit", http.StatusBadRequest, "Invalid export: Limit must be a number of games, zero for every game")
+)
+
+// ExportHandler - GET /export/{dataset}, needs the admin token
+type ExportHandler struct {
+	store Store
+	admin *game.AdminHandler
+}
+
+func NewExportHandler(store Store, admin *game.AdminHandler) *ExportHandler {
+	return &ExportHandler{store, admin}
+}
+
+// Export - GET /export/{dataset}?format=csv&from=&to=&cursor=&limit=
+// Format is csv, jsonl or parquet, csv if not given. From and to bound when games completed.
+func (h *ExportHandler) Export(w http.ResponseWriter, r *http.Request) {
+
+	if !h.admin.Authorized(w, r) {
+		return
+	}
+
+	w.Header().Set("Server", "NG: Small Browser Based Game Server")
+
+	query, dataset, format, err := exportRequest(r)
+	if err != nil {
+		problem.Write(w, err)
+		return
+	}
+	games, cursor, err := Export(h.store, query)
+	if err != nil {
+		problem.Write(w, err)
+		return
+	}
+	// Written in full first so a failure can still be reported as a problem
+	body := &bytes.Buffer{}
+	if err := Write(body, dataset, format, games); err != nil {
+		problem.Write(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", ContentType[format])
+	w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%q", fmt.Sprintf("%s-%s.%s", dataset, cursor, format)))
+	w.Header().Set(CursorHeader, cursor)
+	w.Header().Set(SchemaHeader, strconv.Itoa(SchemaVersion))
+	w.WriteHeader(http.StatusOK)
+	w.Write(body.Bytes())
+}
+
+// exportRequest - What's being exported, checked before anything is read
+func exportRequest(r *http.Request) (Query, Dataset, Format, error) {
+	params := r.URL.Query()
+	query := Query{}
+
+	dataset := Dataset(chi.URLParam(r, "dataset"))
+	format := Format(params.Get("format"))
+	if format == "" {
+		format = FormatCSV
+	}
+	if err := Validate(dataset, format); err != nil {
+		return query, dataset, format, err
+	}
+
+	var err error
+	if query.From, err = ParseTime(params.Get("from")); err != nil {
+		return query, dataset, format, err
+	}
+	if query.To, err = ParseTime(params.Get("to")); err != nil {
+		return query, dataset, format, err
+	}
+	if query.After, err = ParseCursor(params.Get("cursor")); err != nil {
+		return query, dataset, format, err
+	}
+	if limit := params.Get("limit"); limit != "" {
+		if query.Limit, err = strconv.Atoi(limit); err != nil || query.Limit < 0 {
+			return query, dataset, format, ErrInvalidLimit
+		}
+	}
+
+	return query, dataset, format, nil
+}
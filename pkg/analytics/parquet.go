@@ -0,0 +1,259 @@
+package analytics
+
+import (
+	"bytes"
+	"encoding/binary"
+	"io"
+	"time"
+)
+
+// Just enough of Parquet to write a flat table: one row group, one plain encoded,
+// uncompressed data page per column and every column required.
+// See https://github.com/apache/parquet-format for the format and its Thrift definitions.
+
+const parquetMagic = "PAR1"
+
+// Parquet physical types, encodings and converted types used here
+const (
+	parquetBoolean   = 0
+	parquetInt64     = 2
+	parquetByteArray = 6
+
+	encodingPlain = 0
+	encodingRLE   = 3
+
+	convertedUTF8            = 0
+	convertedTimestampMillis = 9
+)
+
+// Thrift compact protocol field types
+const (
+	thriftI32    = 5
+	thriftI64    = 6
+	thriftBinary = 8
+	thriftList   = 9
+	thriftStruct = 12
+)
+
+// writeParquet - The table as a Parquet file
+func writeParquet(w io.Writer, columns []Column, table [][]interface{}) error {
+	file := bytes.NewBufferString(parquetMagic)
+
+	chunks := make([]columnChunk, len(columns))
+	for i, column := range columns {
+		page := plain(column, i, table)
+
+		header := newThrift()
+		header.i32(1, 0) // DATA_PAGE
+		header.i32(2, int32(len(page)))
+		header.i32(3, int32(len(page)))
+		header.begin(5)
+		header.i32(1, int32(len(table)))
+		header.i32(2, encodingPlain)
+		header.i32(3, encodingRLE)
+		header.i32(4, encodingRLE)
+		header.end()
+		header.stop()
+
+		chunks[i] = columnChunk{
+			offset: int64(file.Len()),
+			size:   int64(header.Len() + len(page)),
+		}
+		file.Write(header.Bytes())
+		file.Write(page)
+	}
+
+	footer := newThrift()
+	footer.i32(1, 1)
+	// Schema, the root then each column
+	footer.list(2, thriftStruct, len(columns)+1)
+	footer.element()
+	footer.binary(4, "schema")
+	footer.i32(5, int32(len(columns)))
+	footer.end()
+	for _, column := range columns {
+		footer.element()
+		footer.i32(1, physical(column.Type))
+		footer.i32(3, 0) // REQUIRED
+		footer.binary(4, column.Name)
+		switch column.Type {
+		case ColumnString:
+			footer.i32(6, convertedUTF8)
+		case ColumnTime:
+			footer.i32(6, convertedTimestampMillis)
+		}
+		footer.end()
+	}
+	footer.i64(3, int64(len(table)))
+	// One row group
+	footer.list(4, thriftStruct, 1)
+	footer.element()
+	footer.list(1, thriftStruct, len(columns))
+	var total int64
+	for i, column := range columns {
+		footer.element()
+		footer.i64(2, chunks[i].offset)
+		footer.begin(3)
+		footer.i32(1, physical(column.Type))
+		footer.list(2, thriftI32, 2)
+		footer.varint(encodingPlain)
+		footer.varint(encodingRLE)
+		footer.list(3, thriftBinary, 1)
+		footer.bytes(column.Name)
+		footer.i32(4, 0) // UNCOMPRESSED
+		footer.i64(5, int64(len(table)))
+		footer.i64(6, chunks[i].size)
+		footer.i64(7, chunks[i].size)
+		footer.i64(9, chunks[i].offset)
+		footer.end()
+		footer.end()
+		total += chunks[i].size
+	}
+	footer.i64(2, total)
+	footer.i64(3, int64(len(table)))
+	footer.end()
+	footer.binary(6, "sbbg")
+	footer.stop()
+
+	file.Write(footer.Bytes())
+	binary.Write(file, binary.LittleEndian, uint32(footer.Len()))
+	file.WriteString(parquetMagic)
+
+	_, err := w.Write(file.Bytes())
+
+	return err
+}
+
+// columnChunk - Where a column's page was written
+type columnChunk struct {
+	offset int64
+	size   int64
+}
+
+// physical - How a column type is stored
+func physical(columnType ColumnType) int32 {
+	switch columnType {
+	case ColumnBool:
+		return parquetBoolean
+	case ColumnString:
+		return parquetByteArray
+	}
+
+	return parquetInt64
+}
+
+// plain - Column i of the table, plain encoded
+func plain(column Column, i int, table [][]interface{}) []byte {
+	page := &bytes.Buffer{}
+	if column.Type == ColumnBool {
+		// Bit packed, first value in the lowest bit
+		packed := make([]byte, (len(table)+7)/8)
+		for r, row := range table {
+			if row[i].(bool) {
+				packed[r/8] |= 1 << uint(r%8)
+			}
+		}
+		page.Write(packed)
+		return page.Bytes()
+	}
+
+	for _, row := range table {
+		switch value := row[i].(type) {
+		case string:
+			binary.Write(page, binary.LittleEndian, uint32(len(value)))
+			page.WriteString(value)
+		case int64:
+			binary.Write(page, binary.LittleEndian, value)
+		case time.Time:
+			binary.Write(page, binary.LittleEndian, value.UnixMilli())
+		}
+	}
+
+	return page.Bytes()
+}
+
+// thrift - Writes Thrift's compact protocol, tracking the last field ID of each open struct
+type thrift struct {
+	bytes.Buffer
+	last []int16
+}
+
+func newThrift() *thrift {
+	return &thrift{last: []int16{0}}
+}
+
+// field - A field header, the ID as a delta from the last where it fits
+func (t *thrift) field(id int16, fieldType byte) {
+	last := t.last[len(t.last)-1]
+	if delta := id - last; delta > 0 && delta <= 15 {
+		t.WriteByte(byte(delta)<<4 | fieldType)
+	} else {
+		t.WriteByte(fieldType)
+		t.varint(int64(id))
+	}
+	t.last[len(t.last)-1] = id
+}
+
+// varint - A zigzag encoded varint, as i16, i32 and i64 are written
+func (t *thrift) varint(n int64) {
+	t.uvarint(uint64((n << 1) ^ (n >> 63)))
+}
+
+func (t *thrift) uvarint(n uint64) {
+	buf := make([]byte, binary.MaxVarintLen64)
+	t.Write(buf[:binary.PutUvarint(buf, n)])
+}
+
+func (t *thrift) i32(id int16, n int32) {
+	t.field(id, thriftI32)
+	t.varint(int64(n))
+}
+
+func (t *thrift) i64(id int16, n int64) {
+	t.field(id, thriftI64)
+	t.varint(n)
+}
+
+func (t *thrift) binary(id int16, s string) {
+	t.field(id, thriftBinary)
+	t.bytes(s)
+}
+
+// bytes - A length prefixed string, as a binary field or list element
+func (t *thrift) bytes(s string) {
+	t.uvarint(uint64(len(s)))
+	t.WriteString(s)
+}
+
+// list - A list field's header, its elements follow
+func (t *thrift) list(id int16, elementType byte, size int) {
+	t.field(id, thriftList)
+	if size < 15 {
+		t.WriteByte(byte(size)<<4 | elementType)
+	} else {
+		t.WriteByte(0xf0 | elementType)
+		t.uvarint(uint64(size))
+	}
+}
+
+// begin - A struct field, its fields follow until end
+func (t *thrift) begin(id int16) {
+	t.field(id, thriftStruct)
+	t.element()
+}
+
+// element - A struct in a list, its fields follow until end
+func (t *thrift) element() {
+	t.last = append(t.last, 0)
+}
+
+// end - Close the open struct
+func (t *thrift) end() {
+	t.WriteByte(0)
+	t.last = t.last[:len(t.last)-1]
+}
+
+// stop - Close the top level struct
+func (t *thrift) stop() {
+	t.WriteByte(0)
+}
@@ -0,0 +1,230 @@
+package analytics
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestThrift(t *testing.T) {
+	assert := assert.New(t)
+
+	out := newThrift()
+	out.i32(1, 1)       // delta 1, i32, zigzag 2
+	out.i64(20, -1)     // too far for a delta, zigzag 1
+	out.binary(21, "a") // delta 1, length prefixed
+	out.begin(22)
+	out.i32(1, 3)
+	out.end()
+	out.list(23, thriftI32, 2)
+	out.varint(0)
+	out.varint(3)
+	out.stop()
+
+	assert.Equal([]byte{
+		0x15, 0x02,
+		0x06, 0x28, 0x01,
+		0x18, 0x01, 'a',
+		0x1c, 0x15, 0x06, 0x00,
+		0x19, 0x25, 0x00, 0x06,
+		0x00,
+	}, out.Bytes())
+}
+
+func TestWriteParquet(t *testing.T) {
+	assert := assert.New(t)
+	store := NewMemoryStore()
+	archived(t, store, 2)
+	// A game resumed from an old checkpoint may not know when it started
+	store.Append(Game{Room: "main", CompletedAt: start.Add(time.Hour), Results: []Result{{Player: "Sue", Rank: 1, Winner: true}}})
+	games, _ := store.List(Query{})
+
+	for _, dataset := range []Dataset{DatasetGames, DatasetRounds, DatasetResults} {
+		out := &bytes.Buffer{}
+		assert.Nil(Write(out, dataset, FormatParquet, games))
+		columns := Schemas[dataset]
+		table := rows(dataset, games)
+
+		read, err := readParquet(out.Bytes())
+		assert.Nil(err, dataset)
+		assert.Equal(len(table), read.rows, dataset)
+		assert.Len(read.columns, len(columns))
+		for i, column := range columns {
+			assert.Equal(column.Name, read.columns[i], dataset)
+			for r, row := range table {
+				want := row[i]
+				if at, ok := want.(time.Time); ok {
+					want = at.UnixMilli()
+				}
+				assert.Equal(want, read.values[i][r], "%s %s row %d", dataset, column.Name, r)
+			}
+		}
+	}
+
+	out := &bytes.Buffer{}
+	Write(out, DatasetGames, FormatParquet, games)
+	read, _ := readParquet(out.Bytes())
+	assert.Equal(time.Time{}.UnixMilli(), read.values[5][2])
+}
+
+// parquetFile - What readParquet found, every column's values in row order
+type parquetFile struct {
+	rows    int
+	columns []string
+	values  [][]interface{}
+}
+
+// readParquet - Reads a file back through its footer, as any Parquet reader would:
+// the schema, then each column chunk's page header and plain encoded page
+func readParquet(file []byte) (*parquetFile, error) {
+	if len(file) < 12 || string(file[:4]) != parquetMagic || string(file[len(file)-4:]) != parquetMagic {
+		return nil, fmt.Errorf("not a parquet file")
+	}
+	length := int(binary.LittleEndian.Uint32(file[len(file)-8:]))
+	footer, err := readThrift(bytes.NewReader(file[len(file)-8-length : len(file)-8]))
+	if err != nil {
+		return nil, err
+	}
+
+	read := &parquetFile{rows: int(footer[3].(int64))}
+	schema := footer[2].([]interface{})
+	if int(schema[0].(map[int16]interface{})[5].(int64)) != len(schema)-1 {
+		return nil, fmt.Errorf("schema root has the wrong number of children")
+	}
+	types := []int64{}
+	for _, element := range schema[1:] {
+		read.columns = append(read.columns, element.(map[int16]interface{})[4].(string))
+		types = append(types, element.(map[int16]interface{})[1].(int64))
+	}
+
+	groups := footer[4].([]interface{})
+	if len(groups) != 1 {
+		return nil, fmt.Errorf("expected one row group, got %d", len(groups))
+	}
+	for i, chunk := range groups[0].(map[int16]interface{})[1].([]interface{}) {
+		meta := chunk.(map[int16]interface{})[3].(map[int16]interface{})
+		if meta[1].(int64) != types[i] || meta[4].(int64) != 0 {
+			return nil, fmt.Errorf("column %d: type or codec doesn't match the schema", i)
+		}
+		pages := bytes.NewReader(file[meta[9].(int64):])
+		header, err := readThrift(pages)
+		if err != nil {
+			return nil, err
+		}
+		if header[1].(int64) != 0 || header[5].(map[int16]interface{})[1].(int64) != int64(read.rows) {
+			return nil, fmt.Errorf("column %d: not a data page of every row", i)
+		}
+		page := make([]byte, header[3].(int64))
+		if _, err := io.ReadFull(pages, page); err != nil {
+			return nil, err
+		}
+		read.values = append(read.values, readPlain(types[i], read.rows, page))
+	}
+
+	return read, nil
+}
+
+// readPlain - Plain encoded values of a physical type
+func readPlain(physicalType int64, n int, page []byte) []interface{} {
+	values := make([]interface{}, 0, n)
+	for r := 0; r < n; r++ {
+		switch physicalType {
+		case parquetBoolean:
+			values = append(values, page[r/8]&(1<<uint(r%8)) != 0)
+		case parquetInt64:
+			values = append(values, int64(binary.LittleEndian.Uint64(page)))
+			page = page[8:]
+		case parquetByteArray:
+			size := binary.LittleEndian.Uint32(page)
+			values = append(values, string(page[4:4+size]))
+			page = page[4+size:]
+		}
+	}
+
+	return values
+}
+
+// readThrift - A compact protocol struct, fields by ID. Integers are int64,
+// binaries strings, lists []interface{} and structs maps.
+func readThrift(r *bytes.Reader) (map[int16]interface{}, error) {
+	fields := map[int16]interface{}{}
+	var last int16
+	for {
+		header, err := r.ReadByte()
+		if err != nil {
+			return nil, err
+		}
+		if header == 0 {
+			return fields, nil
+		}
+		id := last + int16(header>>4)
+		if header>>4 == 0 {
+			n, err := readZigzag(r)
+			if err != nil {
+				return nil, err
+			}
+			id = int16(n)
+		}
+		fieldType := header & 0x0f
+		if fieldType == 1 || fieldType == 2 {
+			// Booleans are the field type
+			fields[id] = fieldType == 1
+		} else if fields[id], err = readThriftValue(r, fieldType); err != nil {
+			return nil, err
+		}
+		last = id
+	}
+}
+
+func readThriftValue(r *bytes.Reader, valueType byte) (interface{}, error) {
+	switch valueType {
+	case 1, 2, 3:
+		b, err := r.ReadByte()
+		return int64(b), err
+	case 4, thriftI32, thriftI64:
+		return readZigzag(r)
+	case thriftBinary:
+		size, err := binary.ReadUvarint(r)
+		if err != nil {
+			return nil, err
+		}
+		b := make([]byte, size)
+		_, err = io.ReadFull(r, b)
+		return string(b), err
+	case thriftList:
+		header, err := r.ReadByte()
+		if err != nil {
+			return nil, err
+		}
+		size := uint64(header >> 4)
+		if size == 15 {
+			if size, err = binary.ReadUvarint(r); err != nil {
+				return nil, err
+			}
+		}
+		list := []interface{}{}
+		for i := uint64(0); i < size; i++ {
+			element, err := readThriftValue(r, header&0x0f)
+			if err != nil {
+				return nil, err
+			}
+			list = append(list, element)
+		}
+		return list, nil
+	case thriftStruct:
+		return readThrift(r)
+	}
+
+	return nil, fmt.Errorf("unexpected thrift type %d", valueType)
+}
+
+func readZigzag(r *bytes.Reader) (int64, error) {
+	n, err := binary.ReadUvarint(r)
+
+	return int64(n>>1) ^ -int64(n&1), err
+}
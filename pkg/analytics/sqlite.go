@@ -0,0 +1,103 @@
+package analytics
+
+import (
+	"database/sql"
+	"encoding/json"
+	"math"
+
+	// Registers the sqlite3 driver
+	_ "github.com/mattn/go-sqlite3"
+)
+
+const sqliteSchema = `
+CREATE TABLE IF NOT EXISTS games (
+	id           INTEGER PRIMARY KEY AUTOINCREMENT,
+	completed_at INTEGER NOT NULL,
+	record       TEXT NOT NULL
+);
+CREATE INDEX IF NOT EXISTS games_completed_at ON games (completed_at, id);
+`
+
+// SQLiteStore - Games archived in a SQLite database file, each kept whole as JSON
+type SQLiteStore struct {
+	db *sql.DB
+}
+
+// NewSQLiteStore - Open (or create) the archive at path
+func NewSQLiteStore(path string) (*SQLiteStore, error) {
+	db, err := sql.Open("sqlite3", path)
+	if err != nil {
+		return nil, err
+	}
+	// SQLite only allows one writer, let the pool queue them
+	db.SetMaxOpenConns(1)
+
+	if _, err := db.Exec(sqliteSchema); err != nil {
+		db.Close()
+		return nil, err
+	}
+
+	return &SQLiteStore{db}, nil
+}
+
+// Append - See Store
+func (ss *SQLiteStore) Append(g Game) (Game, error) {
+	g.ID = 0
+	record, err := json.Marshal(g)
+	if err != nil {
+		return g, err
+	}
+	result, err := ss.db.Exec(`INSERT INTO games (completed_at, record) VALUES (?, ?)`, g.CompletedAt.UnixNano(), string(record))
+	if err != nil {
+		return g, err
+	}
+	g.ID, err = result.LastInsertId()
+
+	return g, err
+}
+
+// List - See Store
+func (ss *SQLiteStore) List(query Query) ([]Game, error) {
+	from, to := int64(math.MinInt64), int64(math.MaxInt64)
+	if !query.From.IsZero() {
+		from = query.From.UnixNano()
+	}
+	if !query.To.IsZero() {
+		to = query.To.UnixNano()
+	}
+	limit := -1
+	if query.Limit > 0 {
+		limit = query.Limit
+	}
+
+	rows, err := ss.db.Query(
+		`SELECT id, record FROM games WHERE id > ? AND completed_at >= ? AND completed_at < ? ORDER BY id LIMIT ?`,
+		query.After, from, to, limit,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	games := make([]Game, 0)
+	for rows.Next() {
+		var id int64
+		var record string
+		if err := rows.Scan(&id, &record); err != nil {
+			return nil, err
+		}
+		g := Game{}
+		if err := json.Unmarshal([]byte(record), &g); err != nil {
+			return nil, err
+		}
+		g.ID = id
+		games = append(games, g)
+	}
+
+	return games, rows.Err()
+}
+
+// Close - Close the database
+func (ss *SQLiteStore) Close() error {
+	return ss.db.Close()
+}
@@ -0,0 +1,84 @@
+package analytics
+
+import (
+	"sync"
+	"time"
+)
+
+// Query - Games completed in [From, To) and archived after the game with ID After,
+// oldest first. Zero times leave that end of the range open, a zero Limit returns every game.
+type Query struct {
+	From  time.Time
+	To    time.Time
+	After int64
+	Limit int
+}
+
+// matches - Whether g falls in the query, ignoring its limit
+func (q Query) matches(g Game) bool {
+	if g.ID <= q.After {
+		return false
+	}
+	if !q.From.IsZero() && g.CompletedAt.Before(q.From) {
+		return false
+	}
+	if !q.To.IsZero() && !g.CompletedAt.Before(q.To) {
+		return false
+	}
+
+	return true
+}
+
+// Store - Append only archive of completed games
+type Store interface {
+	// Append - Archive a game, returned with the ID it was given
+	Append(g Game) (Game, error)
+	// List - The games query picks
+	List(query Query) ([]Game, error)
+	Close() error
+}
+
+// MemoryStore - Games kept in memory, lost on restart
+type MemoryStore struct {
+	games []Game
+	mu    sync.RWMutex
+}
+
+// NewMemoryStore - Empty in memory archive
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{games: make([]Game, 0)}
+}
+
+// Append - See Store
+func (ms *MemoryStore) Append(g Game) (Game, error) {
+	ms.mu.Lock()
+	defer ms.mu.Unlock()
+
+	g.ID = int64(len(ms.games) + 1)
+	ms.games = append(ms.games, g)
+
+	return g, nil
+}
+
+// List - See Store
+func (ms *MemoryStore) List(query Query) ([]Game, error) {
+	ms.mu.RLock()
+	defer ms.mu.RUnlock()
+
+	games := []Game{}
+	for _, g := range ms.games {
+		if query.Limit > 0 && len(games) == query.Limit {
+			break
+		}
+		if query.matches(g) {
+			games = append(games, g)
+		}
+	}
+
+	return games, nil
+}
+
+// Close - See Store
+func (ms *MemoryStore) Close() error {
+	return nil
+}
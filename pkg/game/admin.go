@@ -0,0 +1,96 @@
+package game
+
+import (
+	"crypto/subtle"
+	"encoding/json"
+	"log"
+	"net/http"
+
+	"networkgaming.co.uk/techtest/pkg/problem"
+)
+
+// AdminHandler - Operator controls, every request needs the admin bearer token
+type AdminHandler struct {
+	engine *Engine
+	token  string
+}
+
+// NewAdminHandler - An empty token turns the admin API off
+func NewAdminHandler(engine *Engine, token string) *AdminHandler {
+	return &AdminHandler{engine, token}
+}
+
+type AddBotsRequest struct {
+	Strategy string `json:"strategy"`
+	Count    int    `json:"count"`
+}
+
+type AddBotsResponse struct {
+	Status int      `json:"status"`
+	Type   string   `json:"type"`
+	Title  string   `json:"title"`
+	Detail string   `json:"detail"`
+	Names  []string `json:"names"`
+}
+
+// Authorized - Checks the bearer token, writing the error response if it's wrong
+func (h *AdminHandler) Authorized(w http.ResponseWriter, r *http.Request) bool {
+	if h.isAdmin(r) {
+		return true
+	}
+
+	problem.Write(w, problem.ErrUnauthorized)
+
+	return false
+}
+
+// isAdmin - Whether the request has the admin token
+func (h *AdminHandler) isAdmin(r *http.Request) bool {
+	return h.token != "" && subtle.ConstantTimeCompare([]byte(bearer(r)), []byte(h.token)) == 1
+}
+
+// AddBots - POST /admin/bots, seats count bots using strategy
+func (h *AdminHandler) AddBots(w http.ResponseWriter, r *http.Request) {
+
+	if !h.Authorized(w, r) {
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Server", "NG: Small Browser Based Game Server")
+
+	request := new(AddBotsRequest)
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		log.Printf("Add Bots - Error decoding json %s", err.Error())
+		problem.Write(w, problem.InvalidJSON(err))
+		return
+	}
+	if request.Count < 1 {
+		request.Count = 1
+	}
+
+	names := []string{}
+	for i := 0; i < request.Count; i++ {
+		ar, err := h.engine.Do(r.Context(), &Action{
+			Type:     ActionTypeAddBot,
+			Strategy: request.Strategy,
+		})
+		if err != nil {
+			log.Printf("Add Bots Error: %s, after adding %v", err.Error(), names)
+			problem.Write(w, err)
+			return
+		}
+		names = append(names, ar.Message)
+	}
+
+	response := AddBotsResponse{
+		Status: http.StatusOK,
+		Type:   "Success",
+		Title:  "Added Bots",
+		Detail: "Bots are waiting to be seated",
+		Names:  names,
+	}
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(response)
+	return
+}
@@ -0,0 +1,237 @@
+package game
+
+import (
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+
+	"networkgaming.co.uk/techtest/pkg/problem"
+)
+
+const (
+	// HistorySize - How many past draws the game keeps for adaptive bots
+	HistorySize = MaxRounds * 10
+)
+
+var (
+	ErrUnknownBotStrategy = problem.New("unknown_bot_strategy", http.StatusUnprocessableEntity, "Invalid bot: No bot strategy with that name")
+)
+
+// BotStrategy - How a server side player chooses its bounds
+type BotStrategy interface {
+	// Bounds - Choose lower and upper bounds, history is the most recent draws, oldest first
+	Bounds(history []int) (int, int)
+}
+
+// botStrategies - Named strategies for the admin API and engine config
+var botStrategies = map[string]func(rand NumberGenerator) BotStrategy{
+	"random":   func(rand NumberGenerator) BotStrategy { return NewRandomBot(rand) },
+	"wide":     func(rand NumberGenerator) BotStrategy { return NewWideBot() },
+	"greedy":   func(rand NumberGenerator) BotStrategy { return NewGreedyBot() },
+	"adaptive": func(rand NumberGenerator) BotStrategy { return NewAdaptiveBot() },
+}
+
+// NewBotStrategy - Looks up a bot strategy by name, rand is only used by random bots.
+// "fixed:3-7" always plays 3 to 7.
+func NewBotStrategy(name string, rand NumberGenerator) (BotStrategy, error) {
+	if strings.HasPrefix(name, "fixed:") {
+		var lower, upper int
+		if _, err := fmt.Sscanf(name, "fixed:%d-%d", &lower, &upper); err != nil {
+			return nil, ErrUnknownBotStrategy
+		}
+		if lower > upper || lower < MinNum || upper > MaxNum {
+			return nil, ErrInvalidRange
+		}
+		return NewFixedBot(lower, upper), nil
+	}
+
+	build, ok := botStrategies[name]
+	if !ok {
+		return nil, ErrUnknownBotStrategy
+	}
+
+	return build(rand), nil
+}
+
+// BotStrategyNames - Sorted names accepted by NewBotStrategy
+func BotStrategyNames() []string {
+	names := make([]string, 0, len(botStrategies))
+	for name := range botStrategies {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	return names
+}
+
+// BotInfo - Event data when the server seats a bot
+type BotInfo struct {
+	Name     string `json:"name"`
+	Strategy string `json:"strategy"`
+	Bot      bool   `json:"bot"`
+}
+
+// RegisterBot - Register a server side player, choosing its picks with strategy
+func (g *Game) RegisterBot(name string, strategy BotStrategy) error {
+	lower, upper := strategy.Bounds(g.history)
+
+	return g.RegisterPlayer(&Player{
+		Name:  name,
+		Picks: spread(lower, upper, g.PicksPerPlayer),
+		Range: &Range{Lower: lower, Upper: upper},
+		Bot:   true,
+	})
+}
+
+// GetHistory - Most recent draws across games, oldest first
+func (g *Game) GetHistory() []int {
+	return g.history
+}
+
+// remember - Keep a draw for adaptive bots, dropping the oldest past HistorySize
+func (g *Game) remember(number int) {
+	g.history = append(g.history, number)
+	if len(g.history) > HistorySize {
+		g.history = g.history[len(g.history)-HistorySize:]
+	}
+}
+
+// spread - n picks evenly across lower to upper
+func spread(lower int, upper int, n int) []int {
+	picks := make([]int, n)
+	for i := range picks {
+		if n == 1 {
+			picks[i] = (lower + upper) / 2
+		} else {
+			picks[i] = lower + (upper-lower)*i/(n-1)
+		}
+	}
+
+	return picks
+}
+
+// RandomBot - Any two numbers
+type RandomBot struct {
+	Rand NumberGenerator
+}
+
+// NewRandomBot - Picks with rand
+func NewRandomBot(rand NumberGenerator) *RandomBot {
+	return &RandomBot{rand}
+}
+
+// Bounds - See BotStrategy
+func (rb *RandomBot) Bounds(history []int) (int, int) {
+	first, second := rb.Rand.GetInt(), rb.Rand.GetInt()
+	if first > second {
+		return second, first
+	}
+
+	return first, second
+}
+
+// FixedBot - Always the same bounds, for comparing choices in simulations
+type FixedBot struct {
+	Lower int
+	Upper int
+}
+
+// NewFixedBot - Always plays lower to upper
+func NewFixedBot(lower int, upper int) *FixedBot {
+	return &FixedBot{lower, upper}
+}
+
+// Bounds - See BotStrategy
+func (fb *FixedBot) Bounds(history []int) (int, int) {
+	return fb.Lower, fb.Upper
+}
+
+// WideBot - The widest range that still scores when the draw lands inside it
+type WideBot struct{}
+
+// NewWideBot - Widest safe range in the middle of the numbers
+func NewWideBot() *WideBot {
+	return &WideBot{}
+}
+
+// Bounds - See BotStrategy
+func (wb *WideBot) Bounds(history []int) (int, int) {
+	width := InsideBoundsScore - 1
+	lower := (MinNum+MaxNum)/2 - width/2
+
+	return lower, lower + width
+}
+
+// GreedyBot - Everything on the most drawn number, for exact match points
+type GreedyBot struct{}
+
+// NewGreedyBot - Narrowest possible range on the hottest number
+func NewGreedyBot() *GreedyBot {
+	return &GreedyBot{}
+}
+
+// Bounds - See BotStrategy
+func (gb *GreedyBot) Bounds(history []int) (int, int) {
+	counts := frequencies(history)
+	best := (MinNum + MaxNum) / 2
+	for number := MinNum; number <= MaxNum; number++ {
+		if counts[number] > counts[best] {
+			best = number
+		}
+	}
+
+	return best, best
+}
+
+// AdaptiveBot - The range that would have scored best over the recent draws
+type AdaptiveBot struct {
+	Scoring ScoringStrategy
+}
+
+// NewAdaptiveBot - Learns from history with classic scoring
+func NewAdaptiveBot() *AdaptiveBot {
+	return &AdaptiveBot{NewClassicScoring()}
+}
+
+// Bounds - See BotStrategy
+func (ab *AdaptiveBot) Bounds(history []int) (int, int) {
+	if len(history) == 0 {
+		return NewWideBot().Bounds(history)
+	}
+
+	counts := frequencies(history)
+	bestLower, bestUpper := MinNum, MinNum
+	bestScore := 0
+	first := true
+	for lower := MinNum; lower <= MaxNum; lower++ {
+		for upper := lower; upper <= MaxNum; upper++ {
+			player := GamePlayer{Lower: lower, Upper: upper, Picks: []int{lower, upper}}
+			score := 0
+			for number, count := range counts {
+				score += count * ab.Scoring.Points(player, number, 0)
+			}
+			// Ties go to the higher bounds, as they do in NominateWinner
+			if first || score > bestScore || (score == bestScore && upper >= bestUpper && lower >= bestLower) {
+				bestLower, bestUpper, bestScore = lower, upper, score
+				first = false
+			}
+		}
+	}
+
+	return bestLower, bestUpper
+}
+
+func frequencies(history []int) map[int]int {
+	counts := make(map[int]int)
+	for _, number := range history {
+		counts[number]++
+	}
+
+	return counts
+}
+
+// botName - A name for the nth bot of a strategy
+func botName(strategy string, n int) string {
+	return fmt.Sprintf("%s bot %d", strategy, n)
+}
@@ -0,0 +1,145 @@
+package game
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBotStrategies(t *testing.T) {
+	assert := assert.New(t)
+
+	lower, upper := NewWideBot().Bounds(nil)
+	assert.Equal(InsideBoundsScore-1, upper-lower)
+
+	lower, upper = NewGreedyBot().Bounds([]int{3, 7, 7, 2})
+	assert.Equal(7, lower)
+	assert.Equal(7, upper)
+
+	lower, upper = NewRandomBot(NewSSNG([]int{8, 2})).Bounds(nil)
+	assert.Equal(2, lower)
+	assert.Equal(8, upper)
+
+	// Always 9 pays best with 9 as a pick, ties go to the higher bounds
+	lower, upper = NewAdaptiveBot().Bounds([]int{9, 9, 9, 9})
+	assert.Equal(9, lower)
+	assert.Equal(10, upper)
+	// Nothing to learn from yet
+	lower, upper = NewAdaptiveBot().Bounds(nil)
+	wideLower, wideUpper := NewWideBot().Bounds(nil)
+	assert.Equal(wideLower, lower)
+	assert.Equal(wideUpper, upper)
+}
+
+func TestRegisterBot(t *testing.T) {
+	assert := assert.New(t)
+
+	game := NewGame(NewRNG(MaxNum))
+	game.PicksPerPlayer = 3
+
+	err := game.RegisterBot("wide bot 1", NewWideBot())
+	assert.Nil(err)
+	game.AddWaitingPlayersToGame()
+
+	bot := game.Players["wide bot 1"]
+	assert.True(bot.Bot)
+	assert.Equal([]int{3, 5, 7}, bot.Picks)
+
+	_, err = NewBotStrategy("nope", nil)
+	assert.Equal(ErrUnknownBotStrategy, err)
+}
+
+func TestEngineFillsWithBots(t *testing.T) {
+	assert := assert.New(t)
+	game := NewGame(NewRNG(MaxNum))
+	engineConfig := &EngineConfig{
+		GameSpeed:     10 * time.Minute,
+		WaitingCount:  10,
+		ManualRun:     false,
+		BotAfterTicks: 2,
+		BotStrategy:   "wide",
+	}
+	engine := NewEngine(game, engineConfig)
+	clock := NewFakeClock(time.Now())
+	engine.Clock = clock
+	engine.Start(context.Background())
+
+	rc := make(chan *ActionResponse)
+	engine.Action <- &Action{Type: ActionTypeJoinGame, Player: &Player{Name: "Steve", First: 5, Second: 3}, Reply: rc}
+	<-rc
+	<-engine.Event
+
+	// Seat Steve, then wait
+	clock.Advance(engineConfig.GameSpeed)
+	<-engine.Event
+	<-engine.Event
+	// Waited long enough, a bot joins
+	clock.Advance(engineConfig.GameSpeed)
+	<-engine.Event
+	event := <-engine.Event
+	assert.Equal(BotAdded.String(), event.Type)
+	assert.Equal("wide bot 1", event.Data.(BotInfo).Name)
+	// Bot is seated and the game gets ready
+	clock.Advance(engineConfig.GameSpeed)
+	event = <-engine.Event
+	assert.Equal(PlayerJoined.String(), event.Type)
+	assert.True(event.Data.([]*GamePlayer)[0].Bot)
+	assert.Equal(GameReady.String(), (<-engine.Event).Type)
+	assert.Equal(StateChanged.String(), (<-engine.Event).Type)
+	assert.Equal(GameStateReady, game.GetState())
+
+	// On demand
+	engine.Action <- &Action{Type: ActionTypeAddBot, Strategy: "greedy", Reply: rc}
+	resp := <-rc
+	assert.True(resp.Success)
+	assert.Equal("greedy bot 2", resp.Message)
+	<-engine.Event
+}
+
+func TestEngineBotWinsStakedGame(t *testing.T) {
+	assert := assert.New(t)
+
+	// Every draw lands in the wide bot's range and outside the players'
+	numbers := make([]int, MaxRounds*2)
+	for i := range numbers {
+		numbers[i] = 5
+	}
+	game := NewGame(NewSSNG(numbers))
+	engine := NewEngine(game, &EngineConfig{GameSpeed: 10 * time.Nanosecond, WaitingCount: 10, ManualRun: true})
+	stakes := &heldStakes{}
+	engine.Stakes = stakes
+	assert.Nil(engine.stake(&Player{Name: "Steve", First: 1, Second: 2}))
+	assert.Nil(engine.stake(&Player{Name: "Sarah", First: 9, Second: 10}))
+	_, err := engine.addBot("wide")
+	assert.Nil(err)
+	assert.Equal([]string{"Steve", "Sarah"}, stakes.held)
+
+	game.AddWaitingPlayersToGame()
+	assert.Nil(game.Start())
+	for i := 0; i <= MaxRounds && game.GetState() != GameStateIntermission; i++ {
+		engine.tick()
+	}
+	assert.Equal(GameStateIntermission, game.GetState())
+	assert.True(game.Players["wide bot 1"].Winner)
+
+	// The bot staked nothing, so the players get their stakes back rather than paying it
+	assert.True(stakes.refunded)
+	assert.Empty(stakes.paid)
+}
+
+func TestTiedNamesLeavesOutBots(t *testing.T) {
+	assert := assert.New(t)
+
+	result := RoundResult{LeaderBoard: []GamePlayer{
+		{Name: "wide bot 1", Score: 9, Bot: true},
+		{Name: "Steve", Score: 9},
+		{Name: "Sarah", Score: 4},
+		{Name: "Simon", Score: 4},
+	}}
+	assert.Equal([]string{"Steve"}, tiedNames(result, result.LeaderBoard[1]))
+
+	// A rogue winner only ties with players on their own score, not the top scorers
+	assert.Equal([]string{"Sarah", "Simon"}, tiedNames(result, result.LeaderBoard[2]))
+}
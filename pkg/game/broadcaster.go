@@ -3,19 +3,82 @@ package game
 import (
 	"context"
 	"fmt"
+	"time"
 
 	"github.com/gorilla/websocket"
 )
 
+const (
+	// ChatBuffer - Chat waiting to go out, more than this and new messages are dropped
+	// rather than holding up game events
+	ChatBuffer = 64
+	// HangUpTimeout - Longest a close frame waits to be written
+	HangUpTimeout = 1 * time.Second
+)
+
+// direct - An event for one subscriber
+type direct struct {
+	socket *websocket.Conn
+	event  *Event
+}
+
 type Broadcaster struct {
 	Subscribers  []*websocket.Conn
 	SubChannel   chan *websocket.Conn
 	EventChannel chan *Event
+	ChatChannel  chan *Event
+	direct       chan direct
+	closing      chan string
+	done         chan struct{}
 }
 
 func NewBroadcaster(eventChannel chan *Event) *Broadcaster {
 	return &Broadcaster{
 		EventChannel: eventChannel,
+		ChatChannel:  make(chan *Event, ChatBuffer),
+		direct:       make(chan direct, ChatBuffer),
+		closing:      make(chan string, 1),
+		done:         make(chan struct{}),
+	}
+}
+
+// Subscribe - Send socket every event from now on, false once the broadcaster has stopped
+func (gb *Broadcaster) Subscribe(socket *websocket.Conn) bool {
+	select {
+	case gb.SubChannel <- socket:
+		return true
+	case <-gb.done:
+		return false
+	}
+}
+
+// Close - Hang up on every subscriber with reason and stop, once it's started
+func (gb *Broadcaster) Close(reason string) {
+	select {
+	case gb.closing <- reason:
+	case <-gb.done:
+	}
+	<-gb.done
+}
+
+// Chat - Queue a chat event for every subscriber, never blocks
+func (gb *Broadcaster) Chat(event *Event) bool {
+	select {
+	case gb.ChatChannel <- event:
+		return true
+	default:
+		return false
+	}
+}
+
+// Whisper - Queue an event for one subscriber, never blocks.
+// Everything written to a socket goes through the broadcaster so writes never overlap.
+func (gb *Broadcaster) Whisper(socket *websocket.Conn, event *Event) bool {
+	select {
+	case gb.direct <- direct{socket, event}:
+		return true
+	default:
+		return false
 	}
 }
 
@@ -24,6 +87,7 @@ func (gb *Broadcaster) Start(ctx context.Context) error {
 	gb.SubChannel = make(chan *websocket.Conn)
 
 	go func() {
+		defer close(gb.done)
 		fmt.Println("Starting Broadcaster...")
 		//message := []byte("Word to the purd!")
 		for {
@@ -32,22 +96,19 @@ func (gb *Broadcaster) Start(ctx context.Context) error {
 				// log.Println("Broadcaster - Adding Subscriber:")
 				gb.Subscribers = append(gb.Subscribers, socket)
 			case event := <-gb.EventChannel:
-
-				// case <-time.After(1 * time.Second):
-				for i, subscriber := range gb.Subscribers {
-					err := subscriber.WriteJSON(event)
-					if err != nil {
-						// log.Println("Broadcaster - Removing Subscriber:", err)
-						// Unsubscribe
-						gb.Subscribers[i] = gb.Subscribers[len(gb.Subscribers)-1] // Copy last element to index i.
-						gb.Subscribers[len(gb.Subscribers)-1] = nil               // Erase last element (write zero value).
-						gb.Subscribers = gb.Subscribers[:len(gb.Subscribers)-1]   // Truncate slice.
-						// TODO: Should close the socket here first?
-						subscriber.Close()
-						break
-					}
+				gb.broadcast(event)
+			case event := <-gb.ChatChannel:
+				gb.broadcast(event)
+			case message := <-gb.direct:
+				if err := message.socket.WriteJSON(message.event); err != nil {
+					message.socket.Close()
 				}
+			case reason := <-gb.closing:
+				gb.hangUp(reason)
+				fmt.Println("Broadcaster Closed.")
+				return
 			case <-ctx.Done():
+				gb.hangUp(ShutdownReason)
 				fmt.Println("Broadcaster Exited.")
 				return
 			}
@@ -56,3 +117,36 @@ func (gb *Broadcaster) Start(ctx context.Context) error {
 
 	return nil
 }
+
+// broadcast - Write event to every subscriber, dropping any that have gone
+func (gb *Broadcaster) broadcast(event *Event) {
+	// case <-time.After(1 * time.Second):
+	for i, subscriber := range gb.Subscribers {
+		err := subscriber.WriteJSON(event)
+		if err != nil {
+			// log.Println("Broadcaster - Removing Subscriber:", err)
+			// Unsubscribe
+			gb.Subscribers[i] = gb.Subscribers[len(gb.Subscribers)-1] // Copy last element to index i.
+			gb.Subscribers[len(gb.Subscribers)-1] = nil               // Erase last element (write zero value).
+			gb.Subscribers = gb.Subscribers[:len(gb.Subscribers)-1]   // Truncate slice.
+			// TODO: Should close the socket here first?
+			subscriber.Close()
+			break
+		}
+	}
+}
+
+// hangUp - Close every subscriber's socket, telling them why
+func (gb *Broadcaster) hangUp(reason string) {
+	for _, subscriber := range gb.Subscribers {
+		HangUp(subscriber, reason)
+	}
+	gb.Subscribers = nil
+}
+
+// HangUp - Send a going away close frame with reason and close the socket
+func HangUp(socket *websocket.Conn, reason string) {
+	message := websocket.FormatCloseMessage(websocket.CloseGoingAway, reason)
+	socket.WriteControl(websocket.CloseMessage, message, time.Now().Add(HangUpTimeout))
+	socket.Close()
+}
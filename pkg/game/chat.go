@@ -0,0 +1,349 @@
+package game
+
+import (
+	"fmt"
+	"net/http"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+
+	"networkgaming.co.uk/techtest/pkg/problem"
+)
+
+const (
+	ChatHistorySize = 50
+	MaxChatLength   = 280
+	// Per session, ChatRateLimit messages in any ChatRatePeriod
+	ChatRateLimit  = 5
+	ChatRatePeriod = 10 * time.Second
+)
+
+var (
+	ErrChatEmpty        = problem.New("chat_empty", http.StatusUnprocessableEntity, "Invalid message: Nothing to send")
+	ErrChatTooLong      = problem.New("chat_too_long", http.StatusUnprocessableEntity, fmt.Sprintf("Invalid message: Keep it under %d characters", MaxChatLength))
+	ErrChatRateLimited  = problem.New("rate_limited", http.StatusTooManyRequests, "Invalid message: Slow down, you're sending messages too quickly")
+	ErrChatMuted        = problem.New("chat_muted", http.StatusForbidden, "Invalid message: You have been muted")
+	ErrChatBanned       = problem.New("chat_banned", http.StatusForbidden, "Invalid message: You have been banned from chat")
+	ErrChatNameRequired = problem.New("chat_name_required", http.StatusForbidden, "Invalid message: Subscribe with the chat token you got when you joined to chat")
+	ErrChatTokenInvalid = problem.New("chat_token_invalid", http.StatusForbidden, "Invalid chat token: Join the room to get one")
+	ErrChatBlocked      = problem.New("chat_blocked", http.StatusUnprocessableEntity, "Invalid message: That message isn't allowed")
+)
+
+// ChatMessage - One message, as sent to the room and kept in its history
+type ChatMessage struct {
+	Room   string    `json:"room"`
+	Name   string    `json:"name"`
+	Text   string    `json:"text"`
+	SentAt time.Time `json:"sent_at"`
+}
+
+// Moderation - ChatModerated event data, Until is only set for mutes
+type Moderation struct {
+	Room   string     `json:"room"`
+	Name   string     `json:"name"`
+	Action string     `json:"action"`
+	Until  *time.Time `json:"until,omitempty"`
+}
+
+// WordFilter - Checks a message before it's sent, returning the text to send
+// or an error to reject it
+type WordFilter interface {
+	Filter(text string) (string, error)
+}
+
+// MaskFilter - Replaces blocked words with asterisks, ignoring case
+type MaskFilter struct {
+	pattern *regexp.Regexp
+}
+
+// NewMaskFilter - Masks whole words only, so "class" is safe from "ass"
+func NewMaskFilter(words ...string) *MaskFilter {
+	quoted := []string{}
+	for _, word := range words {
+		if word = strings.TrimSpace(word); word != "" {
+			quoted = append(quoted, regexp.QuoteMeta(word))
+		}
+	}
+	if len(quoted) == 0 {
+		return &MaskFilter{}
+	}
+
+	return &MaskFilter{regexp.MustCompile(`(?i)\b(` + strings.Join(quoted, "|") + `)\b`)}
+}
+
+func (f *MaskFilter) Filter(text string) (string, error) {
+	if f.pattern == nil {
+		return text, nil
+	}
+
+	return f.pattern.ReplaceAllStringFunc(text, func(word string) string {
+		return strings.Repeat("*", len(word))
+	}), nil
+}
+
+// BlockFilter - Rejects messages containing blocked words outright
+type BlockFilter struct {
+	mask *MaskFilter
+}
+
+func NewBlockFilter(words ...string) *BlockFilter {
+	return &BlockFilter{NewMaskFilter(words...)}
+}
+
+func (f *BlockFilter) Filter(text string) (string, error) {
+	if f.mask.pattern != nil && f.mask.pattern.MatchString(text) {
+		return "", ErrChatBlocked
+	}
+
+	return text, nil
+}
+
+// ChatSession - A player's right to chat, issued when they join and dropped when they leave.
+// Every connection with the same token shares it and its rate limit. Mutes and bans
+// are held against the wallet account it was issued to, so they follow the player
+// back in if they rejoin, or against the token when there's no account.
+type ChatSession struct {
+	Name    string
+	account string
+	token   string
+	sent    []time.Time
+}
+
+// moderated - What mutes and bans are held against
+func (s *ChatSession) moderated() string {
+	if s.account != "" {
+		return s.account
+	}
+
+	return s.token
+}
+
+// Chat - A room's chat, with its history and moderation
+type Chat struct {
+	Room       string
+	Filter     WordFilter
+	MaxLength  int
+	RateLimit  int
+	RatePeriod time.Duration
+	Now        func() time.Time
+	history    []ChatMessage
+	sessions   map[string]*ChatSession
+	muted      map[string]time.Time
+	banned     map[string]bool
+	mu         sync.Mutex
+}
+
+// NewChat - Unfiltered until given a Filter
+func NewChat(room string) *Chat {
+	return &Chat{
+		Room:       room,
+		MaxLength:  MaxChatLength,
+		RateLimit:  ChatRateLimit,
+		RatePeriod: ChatRatePeriod,
+		Now:        time.Now,
+		history:    make([]ChatMessage, 0, ChatHistorySize),
+		sessions:   make(map[string]*ChatSession),
+		muted:      make(map[string]time.Time),
+		banned:     make(map[string]bool),
+	}
+}
+
+// Register - Issue a token for name to chat with, call once they've joined the room
+// with the wallet account they're playing as, if any
+func (c *Chat) Register(name string, account string) (string, error) {
+	token, err := randomToken()
+	if err != nil {
+		return "", err
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.sessions[token] = &ChatSession{Name: name, account: account, token: token}
+
+	return token, nil
+}
+
+// Session - The session token was issued for
+func (c *Chat) Session(token string) (*ChatSession, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	session, exists := c.sessions[token]
+	if !exists {
+		return nil, ErrChatTokenInvalid
+	}
+
+	return session, nil
+}
+
+// holders - Every session issued to name, call with the lock held
+func (c *Chat) holders(name string) []*ChatSession {
+	holders := []*ChatSession{}
+	for _, session := range c.sessions {
+		if session.Name == name {
+			holders = append(holders, session)
+		}
+	}
+
+	return holders
+}
+
+// Release - Drop the sessions of players who've left the room, their tokens stop working.
+// Bans and mutes held against their accounts stay.
+func (c *Chat) Release(names ...string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	for _, name := range names {
+		for _, session := range c.holders(name) {
+			delete(c.sessions, session.token)
+			delete(c.muted, session.token)
+			delete(c.banned, session.token)
+		}
+	}
+}
+
+// GameEvent - Release everyone who leaves or is kicked from the room
+func (c *Chat) GameEvent(room string, event *Event) {
+	switch event.Type {
+	case PlayerLeft.String():
+		if left, ok := event.Data.([]GamePlayer); ok {
+			for _, player := range left {
+				c.Release(player.Name)
+			}
+		}
+	case PlayerKicked.String():
+		if name, ok := event.Data.(string); ok {
+			c.Release(name)
+		}
+	}
+}
+
+// Send - Check and record a message from session, returning it as it should be broadcast
+func (c *Chat) Send(session *ChatSession, text string) (ChatMessage, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	now := c.Now()
+	if session == nil || c.sessions[session.token] != session {
+		return ChatMessage{}, ErrChatNameRequired
+	}
+	if c.banned[session.moderated()] {
+		return ChatMessage{}, ErrChatBanned
+	}
+	if until, muted := c.muted[session.moderated()]; muted {
+		if now.Before(until) {
+			return ChatMessage{}, ErrChatMuted
+		}
+		delete(c.muted, session.moderated())
+	}
+
+	text = strings.TrimSpace(text)
+	if text == "" {
+		return ChatMessage{}, ErrChatEmpty
+	}
+	if len([]rune(text)) > c.MaxLength {
+		return ChatMessage{}, ErrChatTooLong
+	}
+
+	// Only count messages still inside the window
+	recent := session.sent[:0]
+	for _, sent := range session.sent {
+		if now.Sub(sent) < c.RatePeriod {
+			recent = append(recent, sent)
+		}
+	}
+	session.sent = recent
+	if len(session.sent) >= c.RateLimit {
+		return ChatMessage{}, ErrChatRateLimited
+	}
+
+	if c.Filter != nil {
+		filtered, err := c.Filter.Filter(text)
+		if err != nil {
+			return ChatMessage{}, err
+		}
+		text = filtered
+	}
+	session.sent = append(session.sent, now)
+
+	message := ChatMessage{Room: c.Room, Name: session.Name, Text: text, SentAt: now.UTC()}
+	if len(c.history) >= ChatHistorySize {
+		c.history = append(c.history[:0], c.history[1:]...)
+	}
+	c.history = append(c.history, message)
+
+	return message, nil
+}
+
+// Mute - Stop whoever's chatting as name for a while
+func (c *Chat) Mute(name string, duration time.Duration) Moderation {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	until := c.Now().Add(duration).UTC()
+	for _, session := range c.holders(name) {
+		c.muted[session.moderated()] = until
+	}
+
+	return Moderation{Room: c.Room, Name: name, Action: "mute", Until: &until}
+}
+
+// Unmute - Let whoever's chatting as name chat again, bans are for good
+func (c *Chat) Unmute(name string) Moderation {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	for _, session := range c.holders(name) {
+		delete(c.muted, session.moderated())
+	}
+
+	return Moderation{Room: c.Room, Name: name, Action: "unmute"}
+}
+
+// Ban - Stop whoever's chatting as name chatting in this room and take their messages
+// out of the history. They're still banned if they rejoin with the same account,
+// whoever joins with the name after them isn't.
+func (c *Chat) Ban(name string) Moderation {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	for _, session := range c.holders(name) {
+		c.banned[session.moderated()] = true
+	}
+	history := make([]ChatMessage, 0, ChatHistorySize)
+	for _, message := range c.history {
+		if message.Name != name {
+			history = append(history, message)
+		}
+	}
+	c.history = history
+
+	return Moderation{Room: c.Room, Name: name, Action: "ban"}
+}
+
+// History - The most recent messages, oldest first
+func (c *Chat) History() []ChatMessage {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return append([]ChatMessage{}, c.history...)
+}
+
+// Say - Send a message to everyone subscribed to the room
+func (r *Room) Say(session *ChatSession, text string) error {
+	message, err := r.Chat.Send(session, text)
+	if err != nil {
+		return err
+	}
+	r.Broadcaster.Chat(NewEvent(ChatSent, message))
+
+	return nil
+}
+
+// Moderate - Let the room know someone has been muted or banned
+func (r *Room) Moderate(moderation Moderation) {
+	r.Broadcaster.Chat(NewEvent(ChatModerated, moderation))
+}
@@ -0,0 +1,87 @@
+package game
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/go-chi/chi"
+)
+
+const (
+	DefaultMuteMinutes = 5
+)
+
+// ChatHandler - Chat moderation, for the room's host or an admin
+type ChatHandler struct {
+	lobby *Lobby
+	admin *AdminHandler
+}
+
+func NewChatHandler(lobby *Lobby, admin *AdminHandler) *ChatHandler {
+	return &ChatHandler{lobby, admin}
+}
+
+type ModerateRequest struct {
+	Name    string `json:"name"`
+	Minutes int    `json:"minutes,omitempty"`
+}
+
+// Mute - POST /chat/{room}/mute {name,minutes}
+func (h *ChatHandler) Mute(w http.ResponseWriter, r *http.Request) {
+	h.moderate(w, r, func(chat *Chat, request *ModerateRequest) Moderation {
+		if request.Minutes <= 0 {
+			request.Minutes = DefaultMuteMinutes
+		}
+		return chat.Mute(request.Name, time.Duration(request.Minutes)*time.Minute)
+	})
+}
+
+// Unmute - POST /chat/{room}/unmute {name}
+func (h *ChatHandler) Unmute(w http.ResponseWriter, r *http.Request) {
+	h.moderate(w, r, func(chat *Chat, request *ModerateRequest) Moderation {
+		return chat.Unmute(request.Name)
+	})
+}
+
+// Ban - POST /chat/{room}/ban {name}
+func (h *ChatHandler) Ban(w http.ResponseWriter, r *http.Request) {
+	h.moderate(w, r, func(chat *Chat, request *ModerateRequest) Moderation {
+		return chat.Ban(request.Name)
+	})
+}
+
+// moderate - Check who's asking, apply the action and tell the room
+func (h *ChatHandler) moderate(w http.ResponseWriter, r *http.Request, action func(chat *Chat, request *ModerateRequest) Moderation) {
+
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Server", "NG: Small Browser Based Game Server")
+
+	room, err := h.lobby.Room(chi.URLParam(r, "room"))
+	if err != nil {
+		writeRoomError(w, err)
+		return
+	}
+	if !h.admin.isAdmin(r) && !room.IsHost(bearer(r)) {
+		writeRoomError(w, ErrNotHost)
+		return
+	}
+
+	request := new(ModerateRequest)
+	if !decodeRoomRequest(w, r, request) {
+		return
+	}
+	if request.Name == "" {
+		writeRoomError(w, ErrPlayerNotFound)
+		return
+	}
+
+	moderation := action(room.Chat, request)
+	room.Moderate(moderation)
+
+	writeRoomResponse(w, http.StatusOK, RoomResponse{
+		Type:   "Success",
+		Title:  "Chat Moderated",
+		Detail: request.Name + ": " + moderation.Action,
+		Code:   room.ID,
+	})
+}
@@ -0,0 +1,212 @@
+package game
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// joined - A session for name, as though they'd just joined the room without an account
+func joined(t *testing.T, chat *Chat, name string) *ChatSession {
+	return joinedAs(t, chat, name, "")
+}
+
+// joinedAs - A session for name, as though they'd just joined the room as account
+func joinedAs(t *testing.T, chat *Chat, name string, account string) *ChatSession {
+	token, err := chat.Register(name, account)
+	assert.Nil(t, err)
+	session, err := chat.Session(token)
+	assert.Nil(t, err)
+
+	return session
+}
+
+func TestChatSession(t *testing.T) {
+	assert := assert.New(t)
+
+	chat := NewChat("main")
+	token, err := chat.Register("Steve", "")
+	assert.Nil(err)
+	session, err := chat.Session(token)
+	assert.Nil(err)
+	assert.Equal("Steve", session.Name)
+
+	// Every connection with the token shares the session, other tokens and rooms get nothing
+	again, err := chat.Session(token)
+	assert.Nil(err)
+	assert.Same(session, again)
+	_, err = chat.Session("Steve")
+	assert.Equal(ErrChatTokenInvalid, err)
+	_, err = NewChat("room-1").Session(token)
+	assert.Equal(ErrChatTokenInvalid, err)
+
+	// Sessions the chat didn't issue can't send
+	_, err = chat.Send(nil, "Hello")
+	assert.Equal(ErrChatNameRequired, err)
+	_, err = chat.Send(&ChatSession{Name: "Steve"}, "Hello")
+	assert.Equal(ErrChatNameRequired, err)
+
+	// Leaving the room drops the session
+	sarah := joined(t, chat, "Sarah")
+	chat.GameEvent("main", NewEvent(PlayerLeft, []GamePlayer{{Name: "Steve"}}))
+	_, err = chat.Session(token)
+	assert.Equal(ErrChatTokenInvalid, err)
+	_, err = chat.Send(session, "Hello")
+	assert.Equal(ErrChatNameRequired, err)
+	chat.GameEvent("main", NewEvent(PlayerKicked, "Sarah"))
+	_, err = chat.Send(sarah, "Hello")
+	assert.Equal(ErrChatNameRequired, err)
+}
+
+func TestChatSend(t *testing.T) {
+	assert := assert.New(t)
+
+	now := time.Now()
+	chat := NewChat("main")
+	chat.Now = func() time.Time { return now }
+	steve := joined(t, chat, "Steve")
+
+	_, err := chat.Send(nil, "Hello")
+	assert.Equal(ErrChatNameRequired, err)
+	_, err = chat.Send(steve, "   ")
+	assert.Equal(ErrChatEmpty, err)
+	_, err = chat.Send(steve, strings.Repeat("a", MaxChatLength+1))
+	assert.Equal(ErrChatTooLong, err)
+
+	message, err := chat.Send(steve, " Hello ")
+	assert.Nil(err)
+	assert.Equal(ChatMessage{Room: "main", Name: "Steve", Text: "Hello", SentAt: now.UTC()}, message)
+
+	// Rate limited per session
+	for i := 1; i < ChatRateLimit; i++ {
+		_, err = chat.Send(steve, "Again")
+		assert.Nil(err)
+	}
+	_, err = chat.Send(steve, "Again")
+	assert.Equal(ErrChatRateLimited, err)
+	_, err = chat.Send(joined(t, chat, "Sarah"), "Hi")
+	assert.Nil(err)
+	now = now.Add(ChatRatePeriod)
+	_, err = chat.Send(steve, "Again")
+	assert.Nil(err)
+
+	assert.Len(chat.History(), ChatRateLimit+2)
+}
+
+func TestChatHistoryLimit(t *testing.T) {
+	assert := assert.New(t)
+
+	chat := NewChat("main")
+	chat.RateLimit = ChatHistorySize + 10
+	steve := joined(t, chat, "Steve")
+	for i := 0; i < ChatHistorySize+10; i++ {
+		chat.Send(steve, fmt.Sprintf("Message %d", i))
+	}
+
+	history := chat.History()
+	assert.Len(history, ChatHistorySize)
+	assert.Equal("Message 10", history[0].Text)
+	assert.Equal(fmt.Sprintf("Message %d", ChatHistorySize+9), history[ChatHistorySize-1].Text)
+}
+
+func TestChatModeration(t *testing.T) {
+	assert := assert.New(t)
+
+	now := time.Now()
+	chat := NewChat("main")
+	chat.Now = func() time.Time { return now }
+	steve := joined(t, chat, "Steve")
+	chat.Send(steve, "Hello")
+
+	moderation := chat.Mute("Steve", time.Minute)
+	assert.Equal("mute", moderation.Action)
+	_, err := chat.Send(steve, "Hello")
+	assert.Equal(ErrChatMuted, err)
+	// Only Steve's token is muted
+	_, err = chat.Send(joined(t, chat, "Sarah"), "Hello")
+	assert.Nil(err)
+	now = now.Add(time.Minute)
+	_, err = chat.Send(steve, "Hello")
+	assert.Nil(err)
+
+	chat.Mute("Steve", time.Hour)
+	chat.Unmute("Steve")
+	_, err = chat.Send(steve, "Hello")
+	assert.Nil(err)
+
+	chat.Ban("Steve")
+	_, err = chat.Send(steve, "Hello")
+	assert.Equal(ErrChatBanned, err)
+	assert.Len(chat.History(), 1)
+
+	// Whoever joins with the name later isn't held to the ban
+	chat.Release("Steve")
+	_, err = chat.Send(joined(t, chat, "Steve"), "Hello")
+	assert.Nil(err)
+}
+
+func TestChatBanFollowsAccount(t *testing.T) {
+	assert := assert.New(t)
+
+	chat := NewChat("main")
+	steve := joinedAs(t, chat, "Steve", "account-1")
+	chat.Ban("Steve")
+	_, err := chat.Send(steve, "Hello")
+	assert.Equal(ErrChatBanned, err)
+
+	// Rejoining, even under another name, doesn't lift it
+	chat.Release("Steve")
+	_, err = chat.Send(joinedAs(t, chat, "Stephen", "account-1"), "Hello")
+	assert.Equal(ErrChatBanned, err)
+	_, err = chat.Send(joinedAs(t, chat, "Steve", "account-2"), "Hello")
+	assert.Nil(err)
+}
+
+func TestChatFilters(t *testing.T) {
+	assert := assert.New(t)
+
+	mask := NewMaskFilter("darn", " heck ", "")
+	text, err := mask.Filter("Darn it, what the heck. Darning socks")
+	assert.Nil(err)
+	assert.Equal("**** it, what the ****. Darning socks", text)
+
+	text, err = NewMaskFilter().Filter("Darn")
+	assert.Nil(err)
+	assert.Equal("Darn", text)
+
+	chat := NewChat("main")
+	chat.Filter = NewBlockFilter("darn")
+	_, err = chat.Send(joined(t, chat, "Steve"), "darn")
+	assert.Equal(ErrChatBlocked, err)
+	assert.Empty(chat.History())
+}
+
+func TestRoomSnapshot(t *testing.T) {
+	assert := assert.New(t)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	lobby := newTestLobby(ctx)
+	room, _ := lobby.Open(MainRoom)
+
+	assert.Nil(room.Join(ctx, &Player{Name: "Steve", First: 3, Second: 7}))
+	assert.Nil(room.Say(joined(t, room.Chat, "Steve"), "Anyone else here?"))
+	assert.Equal(ErrChatNameRequired, room.Say(nil, "Hello"))
+
+	snapshot, err := room.Observe(ctx)
+	assert.Nil(err)
+	assert.Equal(MainRoom, snapshot.Room)
+	assert.Equal(GameStateWaiting, snapshot.State)
+	assert.Len(snapshot.Chat, 1)
+	assert.Equal("Anyone else here?", snapshot.Chat[0].Text)
+
+	// Nothing to observe once the room's closed
+	lobby.Close(MainRoom)
+	_, err = room.Observe(ctx)
+	assert.Equal(ErrEngineStopped, err)
+	assert.Equal(ErrEngineStopped, room.Join(ctx, &Player{Name: "Sarah", First: 3, Second: 7}))
+}
@@ -0,0 +1,402 @@
+package game
+
+import (
+	"encoding/json"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// RestorePolicy - What to do with a game that was in play when the server went down
+type RestorePolicy int
+
+// RestorePolicies
+const (
+	// RestoreResume - Carry on from the last checkpoint, stakes still held
+	RestoreResume RestorePolicy = 0
+	// RestoreVoid - Call the game off, handing every stake back and releasing its players
+	RestoreVoid RestorePolicy = 1
+)
+
+// Checkpoint - An engine's game as it stood after its last transition.
+// Settled is set once the engine stopped and handed every stake back, so
+// there's nothing left to restore.
+type Checkpoint struct {
+	Room    string           `json:"room"`
+	SavedAt time.Time        `json:"saved_at"`
+	State   State            `json:"state"`
+	Game    *GameCheckpoint  `json:"game"`
+	Engine  EngineCheckpoint `json:"engine"`
+	Stakes  json.RawMessage  `json:"stakes,omitempty"`
+	Settled bool             `json:"settled"`
+}
+
+// GameCheckpoint - Everything a Game needs to carry on where it left off.
+// Scoring and Condition are the names the game was configured with, empty
+// when it's playing the rules it was built with.
+type GameCheckpoint struct {
+	Players        map[string]GamePlayer `json:"players"`
+	Accounts       map[string]string     `json:"accounts,omitempty"`
+	Registered     map[string]GamePlayer `json:"registered"`
+	WaitingRoom    []GamePlayer          `json:"waiting_room"`
+	Round          int                   `json:"round"`
+	Numbers        []int                 `json:"numbers"`
+	History        []int                 `json:"history"`
+	Scoring        string                `json:"scoring,omitempty"`
+	Condition      string                `json:"condition,omitempty"`
+	PicksPerPlayer int                   `json:"picks_per_player"`
+	Seats          int                   `json:"seats"`
+	WaitlistSize   int                   `json:"waitlist_size"`
+	AllowAdjust    bool                  `json:"allow_adjust"`
+	AdjustPenalty  int                   `json:"adjust_penalty"`
+	TopScore       int                   `json:"top_score"`
+	Winner         GamePlayer            `json:"winner"`
+	Rogue          bool                  `json:"rogue"`
+	StartedAt      time.Time             `json:"started_at"`
+	SuddenDeath    bool                  `json:"sudden_death"`
+	SuddenDeathAt  int                   `json:"sudden_death_at,omitempty"`
+	State          State                 `json:"state"`
+}
+
+// EngineCheckpoint - Where the engine's countdown and bot seating had got to
+type EngineCheckpoint struct {
+	Count        int  `json:"count"`
+	CountingDown bool `json:"counting_down"`
+	Waited       int  `json:"waited"`
+	Bots         int  `json:"bots"`
+}
+
+// StakesCheckpointer - Stakes that can be carried over a restart, what's
+// checkpointed is theirs to decide
+type StakesCheckpointer interface {
+	Checkpoint() (json.RawMessage, error)
+	Restore(data json.RawMessage) error
+}
+
+// CheckpointStore - Where engines keep checkpoints, the latest one per room
+type CheckpointStore interface {
+	Save(checkpoint *Checkpoint) error
+	// Load - The room's latest checkpoint, nil if it has none
+	Load(room string) (*Checkpoint, error)
+	// Delete - Forget the room's checkpoint, once the room has closed for good
+	Delete(room string) error
+	// List - Every room with a checkpoint, by ID
+	List() ([]string, error)
+}
+
+// FileCheckpoints - One JSON file per room in Dir, replaced on every save
+type FileCheckpoints struct {
+	Dir string
+}
+
+// NewFileCheckpoints - Dir is created on the first save
+func NewFileCheckpoints(dir string) *FileCheckpoints {
+	return &FileCheckpoints{Dir: dir}
+}
+
+// Save - See CheckpointStore. Written alongside, synced and renamed into place,
+// so a crash mid save never leaves half a checkpoint.
+func (fc *FileCheckpoints) Save(checkpoint *Checkpoint) error {
+	if err := os.MkdirAll(fc.Dir, 0755); err != nil {
+		return err
+	}
+	data, err := json.MarshalIndent(checkpoint, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	path := fc.path(checkpoint.Room)
+	file, err := os.Create(path + ".tmp")
+	if err != nil {
+		return err
+	}
+	if _, err := file.Write(data); err != nil {
+		file.Close()
+		return err
+	}
+	if err := file.Sync(); err != nil {
+		file.Close()
+		return err
+	}
+	if err := file.Close(); err != nil {
+		return err
+	}
+
+	return os.Rename(path+".tmp", path)
+}
+
+// Load - See CheckpointStore
+func (fc *FileCheckpoints) Load(room string) (*Checkpoint, error) {
+	data, err := os.ReadFile(fc.path(room))
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	checkpoint := &Checkpoint{}
+	if err := json.Unmarshal(data, checkpoint); err != nil {
+		return nil, err
+	}
+
+	return checkpoint, nil
+}
+
+// Delete - See CheckpointStore
+func (fc *FileCheckpoints) Delete(room string) error {
+	err := os.Remove(fc.path(room))
+	if os.IsNotExist(err) {
+		return nil
+	}
+
+	return err
+}
+
+// List - See CheckpointStore
+func (fc *FileCheckpoints) List() ([]string, error) {
+	entries, err := os.ReadDir(fc.Dir)
+	if os.IsNotExist(err) {
+		return []string{}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	rooms := []string{}
+	for _, entry := range entries {
+		// Entries are sorted by name, and a save cut short leaves only a .tmp
+		if name := entry.Name(); !entry.IsDir() && filepath.Ext(name) == ".json" {
+			rooms = append(rooms, strings.TrimSuffix(name, ".json"))
+		}
+	}
+
+	return rooms, nil
+}
+
+// path - Where room's checkpoint is kept
+func (fc *FileCheckpoints) path(room string) string {
+	return filepath.Join(fc.Dir, room+".json")
+}
+
+// Checkpoint - The game as it stands, copied so it can be saved while the game moves on
+func (g *Game) Checkpoint() *GameCheckpoint {
+	players := make(map[string]GamePlayer, len(g.Players))
+	for name, player := range g.Players {
+		players[name] = player
+	}
+	registered := make(map[string]GamePlayer, len(g.registered))
+	for skeleton, player := range g.registered {
+		registered[skeleton] = player
+	}
+	waitingRoom := make([]GamePlayer, 0, len(g.waitingRoom))
+	for _, player := range g.waitingRoom {
+		waitingRoom = append(waitingRoom, *player)
+	}
+	// Kept apart from the players, who are sent to everyone watching
+	accounts := make(map[string]string)
+	for _, player := range registered {
+		if player.Account != "" {
+			accounts[player.Name] = player.Account
+		}
+	}
+
+	return &GameCheckpoint{
+		Players:        players,
+		Accounts:       accounts,
+		Registered:     registered,
+		WaitingRoom:    waitingRoom,
+		Round:          g.Round,
+		Numbers:        append([]int{}, g.Numbers...),
+		History:        append([]int{}, g.history...),
+		Scoring:        g.scoring,
+		Condition:      g.condition,
+		PicksPerPlayer: g.PicksPerPlayer,
+		Seats:          g.Seats,
+		WaitlistSize:   g.WaitlistSize,
+		AllowAdjust:    g.AllowAdjust,
+		AdjustPenalty:  g.AdjustPenalty,
+		TopScore:       g.TopScore,
+		Winner:         g.Winner,
+		Rogue:          g.Rogue,
+		StartedAt:      g.StartedAt,
+		SuddenDeath:    g.SuddenDeath,
+		SuddenDeathAt:  g.suddenDeathAt,
+		State:          g.state,
+	}
+}
+
+// withoutAccounts - A copy for the log, which the host can read, without the
+// wallet accounts players are staking from
+func (c *GameCheckpoint) withoutAccounts() *GameCheckpoint {
+	logged := *c
+	logged.Accounts = nil
+
+	return &logged
+}
+
+// Restore - Put the game back as it was checkpointed
+func (g *Game) Restore(checkpoint *GameCheckpoint) error {
+	if err := (&RoomSettings{Scoring: checkpoint.Scoring, Condition: checkpoint.Condition}).validate(); err != nil {
+		return err
+	}
+	g.record(DomainEvent{Type: DomainGameRestored, Snapshot: checkpoint})
+
+	return nil
+}
+
+// restore - Apply a checkpoint already validated by Restore
+func (g *Game) restore(checkpoint *GameCheckpoint) {
+	if checkpoint.Scoring != "" {
+		g.Scoring, _ = NewScoringStrategy(checkpoint.Scoring)
+	}
+	if checkpoint.Condition != "" {
+		g.Condition, _ = NewWinCondition(checkpoint.Condition)
+	}
+	g.scoring = checkpoint.Scoring
+	g.condition = checkpoint.Condition
+
+	g.Players = make(map[string]GamePlayer, len(checkpoint.Players))
+	for name, player := range checkpoint.Players {
+		player.Account = checkpoint.Accounts[player.Name]
+		g.Players[name] = player
+	}
+	g.registered = make(map[string]GamePlayer, len(checkpoint.Registered))
+	for skeleton, player := range checkpoint.Registered {
+		player.Account = checkpoint.Accounts[player.Name]
+		g.registered[skeleton] = player
+	}
+	g.waitingRoom = make([]*GamePlayer, 0, len(checkpoint.WaitingRoom))
+	for i := range checkpoint.WaitingRoom {
+		player := checkpoint.WaitingRoom[i]
+		player.Account = checkpoint.Accounts[player.Name]
+		g.waitingRoom = append(g.waitingRoom, &player)
+	}
+	g.Round = checkpoint.Round
+	g.Numbers = append(make([]int, 0, MaxRounds), checkpoint.Numbers...)
+	g.history = append([]int{}, checkpoint.History...)
+	g.PicksPerPlayer = checkpoint.PicksPerPlayer
+	g.Seats = checkpoint.Seats
+	g.WaitlistSize = checkpoint.WaitlistSize
+	g.AllowAdjust = checkpoint.AllowAdjust
+	g.AdjustPenalty = checkpoint.AdjustPenalty
+	g.TopScore = checkpoint.TopScore
+	g.Winner = checkpoint.Winner
+	g.Rogue = checkpoint.Rogue
+	g.StartedAt = checkpoint.StartedAt
+	g.SuddenDeath = checkpoint.SuddenDeath
+	g.suddenDeathAt = checkpoint.SuddenDeathAt
+	g.state = checkpoint.State
+	g.events = nil
+}
+
+// checkpoint - The engine's game as it stands, call from the engine loop.
+// There's no checkpoint without its stakes, they couldn't be settled from it.
+func (eng *Engine) checkpoint() (*Checkpoint, error) {
+	checkpoint := &Checkpoint{
+		Room:    eng.Room,
+		SavedAt: eng.Clock.Now().UTC(),
+		State:   eng.Game.GetState(),
+		Game:    eng.Game.Checkpoint(),
+		Engine: EngineCheckpoint{
+			Count:        eng.count,
+			CountingDown: eng.countingDown,
+			Waited:       eng.waited,
+			Bots:         eng.bots,
+		},
+	}
+	if stakes, ok := eng.Stakes.(StakesCheckpointer); ok {
+		data, err := stakes.Checkpoint()
+		if err != nil {
+			return nil, err
+		}
+		checkpoint.Stakes = data
+	}
+
+	return checkpoint, nil
+}
+
+// save - Checkpoint the game after a transition, so a crash loses at most the one in hand.
+// Nothing is saved if the checkpoint couldn't be taken, err says why.
+func (eng *Engine) save(checkpoint *Checkpoint, err error) {
+	if eng.Checkpoints == nil {
+		return
+	}
+	if err == nil {
+		err = eng.Checkpoints.Save(checkpoint)
+	}
+	if err != nil {
+		log.Printf("Unable to checkpoint game: %s\n", err.Error())
+	}
+}
+
+// Restore - Pick up from the room's latest checkpoint before the engine starts.
+// A game that was in play is resumed or voided according to policy, anything
+// before a game starts is always kept. Does nothing without a checkpoint to restore.
+func (eng *Engine) Restore(policy RestorePolicy) error {
+	eng.mu.Lock()
+	defer eng.mu.Unlock()
+
+	if eng.running {
+		return ErrEngineRunning
+	}
+	if eng.Checkpoints == nil {
+		return nil
+	}
+	checkpoint, err := eng.Checkpoints.Load(eng.Room)
+	if err != nil || checkpoint == nil || checkpoint.Settled || checkpoint.Game == nil {
+		return err
+	}
+
+	if err := (&RoomSettings{Scoring: checkpoint.Game.Scoring, Condition: checkpoint.Game.Condition}).validate(); err != nil {
+		return err
+	}
+	// Stakes first, a game is never carried on without the stakes it's played for
+	if stakes, ok := eng.Stakes.(StakesCheckpointer); ok && len(checkpoint.Stakes) > 0 {
+		if err := stakes.Restore(checkpoint.Stakes); err != nil {
+			return err
+		}
+	}
+	if err := eng.Game.Restore(checkpoint.Game); err != nil {
+		return err
+	}
+	eng.count = checkpoint.Engine.Count
+	eng.countingDown = checkpoint.Engine.CountingDown
+	eng.waited = checkpoint.Engine.Waited
+	eng.bots = checkpoint.Engine.Bots
+
+	if policy == RestoreVoid && eng.inPlay() {
+		log.Printf("Room %s - Voiding the game interrupted in round %d\n", eng.Room, checkpoint.Game.Round)
+		eng.Game.Cancel()
+		if eng.Stakes != nil {
+			if err := eng.Stakes.Refund(); err != nil {
+				log.Printf("Unable to refund stakes: %s\n", err.Error())
+			}
+		}
+		eng.Game.Reset()
+		eng.Game.TakeEvents()
+		eng.resetCountdown()
+		eng.waited = 0
+	} else if eng.inPlay() {
+		log.Printf("Room %s - Resuming the game interrupted in round %d\n", eng.Room, checkpoint.Game.Round)
+	}
+	eng.save(eng.checkpoint())
+
+	return nil
+}
+
+// discard - Call off the game in the room's checkpoint, hand every stake back and
+// forget it. For a room that won't open again, the engine is never started.
+func (eng *Engine) discard() error {
+	if err := eng.Restore(RestoreVoid); err != nil {
+		return err
+	}
+	eng.Game.Cancel()
+	if eng.Stakes != nil {
+		if err := eng.Stakes.Refund(); err != nil {
+			return err
+		}
+	}
+
+	return eng.Checkpoints.Delete(eng.Room)
+}
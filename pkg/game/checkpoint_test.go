@@ -0,0 +1,260 @@
+package game
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// heldStakes - Stakes carried over a restart in a checkpoint
+type heldStakes struct {
+	held     []string
+	accounts []string
+	refunded bool
+	paid     []string
+}
+
+func (s *heldStakes) Stake(name string, account string) error {
+	s.held = append(s.held, name)
+	s.accounts = append(s.accounts, account)
+	return nil
+}
+
+func (s *heldStakes) Unstake(name string) error { return nil }
+
+func (s *heldStakes) Payout(winner string, tied []string) error {
+	s.paid = append([]string{winner}, tied...)
+	return nil
+}
+
+func (s *heldStakes) Refund() error {
+	s.held = nil
+	s.refunded = true
+	return nil
+}
+
+func (s *heldStakes) Checkpoint() (json.RawMessage, error) {
+	return json.Marshal(s.held)
+}
+
+func (s *heldStakes) Restore(data json.RawMessage) error {
+	return json.Unmarshal(data, &s.held)
+}
+
+// brokenStakes - Stakes that can't be checkpointed
+type brokenStakes struct {
+	heldStakes
+}
+
+func (s *brokenStakes) Checkpoint() (json.RawMessage, error) {
+	return nil, errors.New("broken")
+}
+
+func TestGameCheckpoint(t *testing.T) {
+	assert := assert.New(t)
+
+	game := NewGame(NewRNG(MaxNum))
+	game.Seats = 2
+	assert.Nil(game.Configure(&RoomSettings{Scoring: "distance"}))
+	for _, name := range []string{"Steve", "Sarah", "Simon"} {
+		assert.Nil(game.RegisterPlayer(&Player{Name: name, First: 3, Second: 7, Account: "account-" + name}))
+	}
+	game.AddWaitingPlayersToGame()
+
+	data, err := json.Marshal(game.Checkpoint())
+	assert.Nil(err)
+	checkpoint := &GameCheckpoint{}
+	assert.Nil(json.Unmarshal(data, checkpoint))
+
+	restored := NewGame(NewRNG(MaxNum))
+	assert.Nil(restored.Restore(checkpoint))
+	assert.Equal(GameStateWaiting, restored.GetState())
+	assert.Len(restored.Players, 2)
+	assert.Equal([]WaitlistEntry{{Name: "Simon", Position: 1}}, restored.Waitlist())
+	// Accounts are kept with the checkpoint, but never sent with the players
+	assert.Equal("account-Steve", restored.Players["Steve"].Account)
+	assert.Equal("account-Simon", restored.waitingRoom[0].Account)
+	assert.NotContains(string(data), `"account":`)
+	// nor in the log the host can read
+	assert.Empty(restored.Log()[0].Snapshot.Accounts)
+	assert.Equal(ErrInvalidPlayerName, restored.CheckPlayerExists("simon"))
+	assert.IsType(&DistanceScoring{}, restored.Scoring)
+
+	checkpoint.Condition = "nonsense"
+	assert.Equal(ErrUnknownWinCondition, NewGame(NewRNG(MaxNum)).Restore(checkpoint))
+}
+
+func TestGameCheckpointSuddenDeath(t *testing.T) {
+	assert := assert.New(t)
+
+	// Always tied, so it takes every extra round
+	game := NewGame(NewSSNG([]int{5, 5, 5}))
+	game.Condition = NewSuddenDeathCondition(NewMaxRoundsCondition(2), 3)
+	game.RegisterPlayer(&Player{Name: "Steve", First: 5, Second: 5})
+	game.RegisterPlayer(&Player{Name: "Sarah", First: 5, Second: 5})
+	game.AddWaitingPlayersToGame()
+	game.Start()
+	for i := 0; i < 3; i++ {
+		game.PlayRound()
+	}
+	assert.True(game.SuddenDeath)
+
+	data, err := json.Marshal(game.Checkpoint())
+	assert.Nil(err)
+	checkpoint := &GameCheckpoint{}
+	assert.Nil(json.Unmarshal(data, checkpoint))
+
+	// One extra round played before the checkpoint, two left after it
+	restored := NewGame(NewSSNG([]int{5, 5}))
+	restored.Condition = NewSuddenDeathCondition(NewMaxRoundsCondition(2), 3)
+	assert.Nil(restored.Restore(checkpoint))
+	restored.PlayRound()
+	assert.Equal(GameStateInProgress, restored.GetState())
+	restored.PlayRound()
+	assert.Equal(GameStateCompleted, restored.GetState())
+}
+
+func TestEngineCheckpointNeedsStakes(t *testing.T) {
+	assert := assert.New(t)
+
+	store := NewFileCheckpoints(t.TempDir())
+	engine := NewEngine(NewGame(NewRNG(MaxNum)), &EngineConfig{GameSpeed: 1 * time.Minute, WaitingCount: 10, ManualRun: true})
+	engine.Room = MainRoom
+	engine.Stakes = &brokenStakes{}
+	engine.Checkpoints = store
+	engine.Start(context.Background())
+	defer engine.Stop(context.Background())
+
+	ctx := context.Background()
+	_, err := engine.Do(ctx, &Action{Type: ActionTypeJoinGame, Player: &Player{Name: "Steve", First: 3, Second: 7}})
+	assert.Nil(err)
+	<-engine.Event
+	_, err = engine.Do(ctx, &Action{Type: ActionTypeObserveGame})
+	assert.Nil(err)
+
+	// Steve's stake couldn't be checkpointed, so neither is the game
+	checkpoint, err := store.Load(MainRoom)
+	assert.Nil(err)
+	assert.Nil(checkpoint)
+}
+
+// crashed - The checkpoint an engine left after playing a round, as if the server then died
+func crashed(t *testing.T) *FileCheckpoints {
+	engine, clock, events := startedEngine()
+	store := NewFileCheckpoints(t.TempDir())
+	engine.Checkpoints = store
+	clock.Advance(1 * time.Second)
+	next(events, PlayedRound)
+
+	checkpoint, err := store.Load(MainRoom)
+	assert.Nil(t, err)
+	engine.Stop(context.Background())
+	assert.Nil(t, store.Save(checkpoint))
+
+	return store
+}
+
+// restoredEngine - A fresh engine for the main room, restored from store
+func restoredEngine(store CheckpointStore, policy RestorePolicy) (*Engine, *heldStakes, error) {
+	stakes := &heldStakes{}
+	engine := NewEngine(NewGame(NewRNG(MaxNum)), &EngineConfig{GameSpeed: 1 * time.Second, WaitingCount: 1})
+	engine.Room = MainRoom
+	engine.Stakes = stakes
+	engine.Checkpoints = store
+
+	return engine, stakes, engine.Restore(policy)
+}
+
+func TestEngineRestoreResume(t *testing.T) {
+	assert := assert.New(t)
+	store := crashed(t)
+	checkpoint, _ := store.Load(MainRoom)
+	assert.False(checkpoint.Settled)
+	assert.Equal(GameStateInProgress, checkpoint.State)
+
+	engine, _, err := restoredEngine(store, RestoreResume)
+	assert.Nil(err)
+	game := engine.Game.(*Game)
+	assert.Equal(GameStateInProgress, game.GetState())
+	assert.Equal(checkpoint.Game.Round, game.Round)
+	assert.Equal(checkpoint.Game.Players, game.Players)
+	assert.Equal(ErrInvalidPlayerName, game.CheckPlayerExists("Steve"))
+
+	// Plays on from the round it got to
+	clock := NewFakeClock(time.Now())
+	game.Clock = clock
+	engine.Clock = clock
+	engine.Start(context.Background())
+	clock.Advance(1 * time.Second)
+	round := next(engine.Event, PlayedRound)
+	assert.Equal(checkpoint.Game.Round+1, round.Data.(RoundResult).Round)
+	engine.Stop(context.Background())
+}
+
+func TestEngineRestoreVoid(t *testing.T) {
+	assert := assert.New(t)
+	store := crashed(t)
+
+	engine, stakes, err := restoredEngine(store, RestoreVoid)
+	assert.Nil(err)
+	assert.True(stakes.refunded)
+	game := engine.Game.(*Game)
+	assert.Equal(GameStateWaiting, game.GetState())
+	assert.Empty(game.Players)
+	assert.Nil(game.CheckPlayerExists("Steve"))
+
+	// Stopping settles the room, there's nothing left to restore
+	engine.Start(context.Background())
+	engine.Stop(context.Background())
+	checkpoint, _ := store.Load(MainRoom)
+	assert.True(checkpoint.Settled)
+	engine, _, err = restoredEngine(store, RestoreResume)
+	assert.Nil(err)
+	assert.Equal(GameStateWaiting, engine.Game.GetState())
+}
+
+func TestLobbyRecover(t *testing.T) {
+	assert := assert.New(t)
+	store := crashed(t)
+	// The same game left behind by a matchmade room and a private room
+	for _, id := range []string{"room-3", "QWERTY"} {
+		checkpoint, _ := store.Load(MainRoom)
+		checkpoint.Room = id
+		checkpoint.Stakes = json.RawMessage(`["Steve","Sarah"]`)
+		assert.Nil(store.Save(checkpoint))
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	lobby := newTestLobby(ctx)
+	lobby.Checkpoints = store
+	stakes := map[string]*heldStakes{}
+	lobby.Setup = func(room *Room) {
+		stakes[room.ID] = &heldStakes{}
+		room.Engine.Stakes = stakes[room.ID]
+	}
+
+	// Only the main room is opened again, the others are called off and refunded
+	assert.Nil(lobby.Recover(MainRoom))
+	rooms, _ := store.List()
+	assert.Equal([]string{MainRoom}, rooms)
+	assert.True(stakes["room-3"].refunded)
+	assert.True(stakes["QWERTY"].refunded)
+
+	main, _ := lobby.Open(MainRoom)
+	assert.Equal(GameStateInProgress, main.Game.GetState())
+	// A new room never picks up an old room's game
+	room, _ := lobby.Open("")
+	assert.Equal("room-4", room.ID)
+	assert.Equal(GameStateWaiting, room.Game.GetState())
+	assert.Empty(room.Game.Players)
+
+	// Stopped before the store's directory is cleaned up, so nothing's saved into it after
+	for _, room := range lobby.Rooms() {
+		assert.Nil(lobby.Close(room.ID))
+	}
+}
@@ -0,0 +1,151 @@
+package game
+
+import (
+	"fmt"
+	"net/http"
+	"sort"
+
+	"networkgaming.co.uk/techtest/pkg/problem"
+)
+
+const (
+	DefaultPicksPerPlayer = 2
+	MinPicksPerPlayer     = 1
+	MaxPicksPerPlayer     = MaxNum
+	AdjustPenaltyScore    = 2
+)
+
+var (
+	ErrInvalidPicks      = problem.New("invalid_picks", http.StatusUnprocessableEntity, "Invalid choice: Wrong number of picks for this game")
+	ErrInvalidRange      = problem.New("invalid_range", http.StatusUnprocessableEntity, fmt.Sprintf("Invalid range: Choose a lower and upper bound between %d - %d", MinNum, MaxNum))
+	ErrPickOutOfRange    = problem.New("pick_out_of_range", http.StatusUnprocessableEntity, "Invalid choice: Picks must be inside your range")
+	ErrAdjustNotAllowed  = problem.New("adjust_not_allowed", http.StatusForbidden, "Invalid action: This game does not allow adjusting bounds")
+	ErrGameNotInProgress = problem.New("game_not_in_progress", http.StatusConflict, "Invalid action: Game is not in progress")
+	ErrPlayerNotFound    = problem.New("player_not_found", http.StatusNotFound, "Invalid action: There is no player in the game with that name")
+	ErrPlayerOut         = problem.New("player_out", http.StatusConflict, "Invalid action: Player is out of the game")
+)
+
+// Range - Explicit lower and upper bounds chosen by a player
+type Range struct {
+	Lower int `json:"lower"`
+	Upper int `json:"upper"`
+}
+
+// HasPick - Whether the player picked number exactly
+func (gp GamePlayer) HasPick(number int) bool {
+	for _, pick := range gp.Picks {
+		if pick == number {
+			return true
+		}
+	}
+
+	return false
+}
+
+// picks - The player's picks, falling back to First and Second
+func (p *Player) picks() []int {
+	if p.Picks != nil {
+		return p.Picks
+	}
+
+	return []int{p.First, p.Second}
+}
+
+// choose - Validate a player's picks and range against the game's rules.
+// Without an explicit range the bounds are the smallest and largest picks.
+func (g *Game) choose(player *Player) (GamePlayer, error) {
+	gp := GamePlayer{Name: player.Name}
+
+	picks := append([]int{}, player.picks()...)
+	if len(picks) != g.PicksPerPlayer {
+		return gp, ErrInvalidPicks
+	}
+	for _, pick := range picks {
+		if err := g.validateChoice(pick); err != nil {
+			return gp, err
+		}
+	}
+	sort.Ints(picks)
+	gp.Picks = picks
+	gp.Lower = picks[0]
+	gp.Upper = picks[len(picks)-1]
+
+	if player.Range != nil {
+		if err := g.validateRange(player.Range); err != nil {
+			return gp, err
+		}
+		if gp.Lower < player.Range.Lower || gp.Upper > player.Range.Upper {
+			return gp, ErrPickOutOfRange
+		}
+		gp.Lower = player.Range.Lower
+		gp.Upper = player.Range.Upper
+	}
+
+	return gp, nil
+}
+
+// ValidatePlayer - Check a player's name and picks would be accepted by RegisterPlayer,
+// player.Name is replaced with its normalised form
+func (g *Game) ValidatePlayer(player *Player) error {
+	if err := g.checkName(player); err != nil {
+		return err
+	}
+	if _, err := g.choose(player); err != nil {
+		return err
+	}
+
+	return g.checkSeats()
+}
+
+// AdjustPlayer - Change a seated player's picks and bounds between rounds,
+// at the cost of the game's AdjustPenalty. The name is normalised as it was on joining.
+func (g *Game) AdjustPlayer(player *Player) error {
+
+	if !g.AllowAdjust {
+		return ErrAdjustNotAllowed
+	}
+
+	if g.state != GameStateInProgress {
+		return ErrGameNotInProgress
+	}
+
+	if !player.Bot && g.Names != nil {
+		name, err := g.Names.Normalize(player.Name)
+		if err != nil {
+			return ErrPlayerNotFound
+		}
+		player.Name = name
+	}
+	current, exists := g.Players[player.Name]
+	if !exists {
+		return ErrPlayerNotFound
+	}
+	if current.IsOut() {
+		return ErrPlayerOut
+	}
+
+	gp, err := g.choose(player)
+	if err != nil {
+		return err
+	}
+
+	current.Picks = gp.Picks
+	current.Lower = gp.Lower
+	current.Upper = gp.Upper
+	current.Score -= g.AdjustPenalty
+	current.Streak = 0
+	g.update(current)
+
+	return nil
+}
+
+func (g *Game) validateRange(r *Range) error {
+	if r.Lower > r.Upper {
+		return ErrInvalidRange
+	}
+	if g.validateChoice(r.Lower) != nil || g.validateChoice(r.Upper) != nil {
+		return ErrInvalidRange
+	}
+
+	return nil
+}
@@ -0,0 +1,100 @@
+package game
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"networkgaming.co.uk/techtest/pkg/names"
+)
+
+func TestRegisteringMorePicks(t *testing.T) {
+	assert := assert.New(t)
+
+	rand := NewRNG(MaxNum)
+	game := NewGame(rand)
+	game.PicksPerPlayer = 3
+
+	// Two numbers is not enough now
+	err := game.RegisterPlayer(&Player{Name: "Steve", First: 5, Second: 8})
+	assert.Equal(ErrInvalidPicks, err)
+
+	// Bad pick
+	err = game.RegisterPlayer(&Player{Name: "Steve", Picks: []int{5, 8, 11}})
+	assert.Equal(ErrInvalidNumber, err)
+
+	err = game.RegisterPlayer(&Player{Name: "Steve", Picks: []int{8, 2, 5}})
+	assert.Nil(err)
+	game.AddWaitingPlayersToGame()
+
+	steve := game.Players["Steve"]
+	assert.Equal([]int{2, 5, 8}, steve.Picks)
+	assert.Equal(2, steve.Lower)
+	assert.Equal(8, steve.Upper)
+}
+
+func TestRegisteringExplicitRange(t *testing.T) {
+	assert := assert.New(t)
+
+	rand := NewRNG(MaxNum)
+	game := NewGame(rand)
+
+	// Upside down range
+	err := game.RegisterPlayer(&Player{Name: "Steve", First: 5, Second: 6, Range: &Range{Lower: 7, Upper: 3}})
+	assert.Equal(ErrInvalidRange, err)
+
+	// Picks outside the range
+	err = game.RegisterPlayer(&Player{Name: "Steve", First: 5, Second: 9, Range: &Range{Lower: 3, Upper: 7}})
+	assert.Equal(ErrPickOutOfRange, err)
+
+	err = game.RegisterPlayer(&Player{Name: "Steve", First: 5, Second: 6, Range: &Range{Lower: 3, Upper: 7}})
+	assert.Nil(err)
+	game.AddWaitingPlayersToGame()
+
+	steve := game.Players["Steve"]
+	assert.Equal(3, steve.Lower)
+	assert.Equal(7, steve.Upper)
+	// The bounds are not picks so only score as inside
+	assert.Equal(InsideBoundsScore-4, game.Scoring.Points(steve, 3, 0))
+	assert.Equal(ExactMatchScore, game.Scoring.Points(steve, 5, 0))
+}
+
+func TestAdjustingPlayer(t *testing.T) {
+	assert := assert.New(t)
+	seq := []int{9, 9, 9}
+	gen := NewSSNG(seq)
+
+	game := NewGame(gen)
+	game.RegisterPlayer(&Player{Name: "Steve", First: 1, Second: 2})
+	game.RegisterPlayer(&Player{Name: "Sarah", First: 1, Second: 2})
+	game.AddWaitingPlayersToGame()
+	game.Start()
+
+	// Off by default
+	err := game.AdjustPlayer(&Player{Name: "Steve", First: 9, Second: 9})
+	assert.Equal(ErrAdjustNotAllowed, err)
+
+	game.AllowAdjust = true
+	err = game.AdjustPlayer(&Player{Name: "Nobody", First: 9, Second: 9})
+	assert.Equal(ErrPlayerNotFound, err)
+
+	game.PlayRound()
+	err = game.AdjustPlayer(&Player{Name: "Steve", First: 9, Second: 9})
+	assert.Nil(err)
+	assert.Equal(-1-AdjustPenaltyScore, game.Players["Steve"].Score)
+
+	game.PlayRound()
+	assert.Equal(-1-AdjustPenaltyScore+ExactMatchScore, game.Players["Steve"].Score)
+	assert.Equal(-2, game.Players["Sarah"].Score)
+
+	// Found by the name they registered under
+	game.Names = names.DefaultPolicy()
+	err = game.AdjustPlayer(&Player{Name: "  Sarah ", First: 9, Second: 9})
+	assert.Nil(err)
+	assert.Equal(-2-AdjustPenaltyScore, game.Players["Sarah"].Score)
+
+	game.Cancel()
+	game.Reset()
+	err = game.AdjustPlayer(&Player{Name: "Steve", First: 1, Second: 1})
+	assert.Equal(ErrGameNotInProgress, err)
+}
@@ -0,0 +1,196 @@
+package game
+
+import (
+	"sort"
+	"sync"
+	"time"
+)
+
+// Clock - Where the engine and game get the time, their ticks and timeouts from
+type Clock interface {
+	Now() time.Time
+	NewTicker(period time.Duration) Ticker
+	After(d time.Duration) <-chan time.Time
+}
+
+// Ticker - Ticks every period, Reset it to tick every period from now on,
+// Stop it once it's no longer needed
+type Ticker interface {
+	C() <-chan time.Time
+	Reset(period time.Duration)
+	Stop()
+}
+
+// RealClock - The wall clock
+type RealClock struct{}
+
+// Now - See Clock
+func (RealClock) Now() time.Time {
+	return time.Now()
+}
+
+// NewTicker - See Clock
+func (RealClock) NewTicker(period time.Duration) Ticker {
+	return realTicker{time.NewTicker(period)}
+}
+
+// After - See Clock
+func (RealClock) After(d time.Duration) <-chan time.Time {
+	return time.After(d)
+}
+
+type realTicker struct {
+	ticker *time.Ticker
+}
+
+func (rt realTicker) C() <-chan time.Time {
+	return rt.ticker.C
+}
+
+func (rt realTicker) Reset(period time.Duration) {
+	rt.ticker.Reset(period)
+}
+
+func (rt realTicker) Stop() {
+	rt.ticker.Stop()
+}
+
+// FakeClock - For testing the engine.
+// Time only moves when it's Advanced, so whole games can be played out
+// deterministically without waiting on the wall clock.
+type FakeClock struct {
+	now     time.Time
+	tickers []*fakeTicker
+	timers  []fakeTimer
+	mu      sync.Mutex
+}
+
+// fakeTimer - Fires once at, never waits to be received
+type fakeTimer struct {
+	at time.Time
+	c  chan time.Time
+}
+
+// NewFakeClock - Stopped at now
+func NewFakeClock(now time.Time) *FakeClock {
+	return &FakeClock{now: now}
+}
+
+// Now - See Clock
+func (fc *FakeClock) Now() time.Time {
+	fc.mu.Lock()
+	defer fc.mu.Unlock()
+
+	return fc.now
+}
+
+// NewTicker - See Clock, the first tick is one period from now
+func (fc *FakeClock) NewTicker(period time.Duration) Ticker {
+	fc.mu.Lock()
+	defer fc.mu.Unlock()
+
+	ticker := &fakeTicker{
+		clock:  fc,
+		c:      make(chan time.Time),
+		period: period,
+		next:   fc.now.Add(period),
+	}
+	fc.tickers = append(fc.tickers, ticker)
+
+	return ticker
+}
+
+// After - See Clock, fires once the clock has been Advanced by d
+func (fc *FakeClock) After(d time.Duration) <-chan time.Time {
+	fc.mu.Lock()
+	defer fc.mu.Unlock()
+
+	timer := fakeTimer{at: fc.now.Add(d), c: make(chan time.Time, 1)}
+	fc.timers = append(fc.timers, timer)
+
+	return timer.c
+}
+
+// Advance - Move the time forward by d, firing every tick and timer that falls
+// due on the way in order. Each tick waits until it's received, so the engine
+// has taken its turn for the tick before the clock moves on.
+func (fc *FakeClock) Advance(d time.Duration) {
+	fc.mu.Lock()
+	until := fc.now.Add(d)
+	fc.mu.Unlock()
+
+	for {
+		fc.mu.Lock()
+		ticker := fc.due(until)
+		if ticker == nil {
+			fc.now = until
+			fc.fire()
+			fc.mu.Unlock()
+			return
+		}
+		fc.now = ticker.next
+		ticker.next = ticker.next.Add(ticker.period)
+		now := fc.now
+		fc.fire()
+		fc.mu.Unlock()
+
+		// Not holding the lock, the engine may want the time while it turns
+		ticker.c <- now
+	}
+}
+
+// fire - Fire every timer that's due, call with the lock held
+func (fc *FakeClock) fire() {
+	waiting := fc.timers[:0]
+	for _, timer := range fc.timers {
+		if timer.at.After(fc.now) {
+			waiting = append(waiting, timer)
+			continue
+		}
+		timer.c <- fc.now
+	}
+	fc.timers = waiting
+}
+
+// due - The ticker that fires next, if any fire before until
+func (fc *FakeClock) due(until time.Time) *fakeTicker {
+	sort.SliceStable(fc.tickers, func(i, j int) bool {
+		return fc.tickers[i].next.Before(fc.tickers[j].next)
+	})
+	if len(fc.tickers) == 0 || fc.tickers[0].next.After(until) {
+		return nil
+	}
+
+	return fc.tickers[0]
+}
+
+type fakeTicker struct {
+	clock  *FakeClock
+	c      chan time.Time
+	period time.Duration
+	next   time.Time
+}
+
+func (ft *fakeTicker) C() <-chan time.Time {
+	return ft.c
+}
+
+func (ft *fakeTicker) Reset(period time.Duration) {
+	ft.clock.mu.Lock()
+	defer ft.clock.mu.Unlock()
+
+	ft.period = period
+	ft.next = ft.clock.now.Add(period)
+}
+
+func (ft *fakeTicker) Stop() {
+	ft.clock.mu.Lock()
+	defer ft.clock.mu.Unlock()
+
+	for i, ticker := range ft.clock.tickers {
+		if ticker == ft {
+			ft.clock.tickers = append(ft.clock.tickers[:i], ft.clock.tickers[i+1:]...)
+			return
+		}
+	}
+}
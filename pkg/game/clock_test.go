@@ -0,0 +1,122 @@
+package game
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFakeClock(t *testing.T) {
+	assert := assert.New(t)
+
+	start := time.Now()
+	clock := NewFakeClock(start)
+	fast := clock.NewTicker(1 * time.Second)
+	slow := clock.NewTicker(3 * time.Second)
+
+	ticks := make(chan string, 10)
+	done := make(chan bool)
+	go func() {
+		for i := 0; i < 4; i++ {
+			select {
+			case <-fast.C():
+				ticks <- "fast"
+			case <-slow.C():
+				ticks <- "slow"
+			}
+		}
+		done <- true
+	}()
+	clock.Advance(3 * time.Second)
+	<-done
+	close(ticks)
+
+	order := []string{}
+	for tick := range ticks {
+		order = append(order, tick)
+	}
+	assert.Equal([]string{"fast", "fast", "fast", "slow"}, order)
+	assert.Equal(start.Add(3*time.Second), clock.Now())
+
+	// Stopped tickers don't hold the clock up
+	fast.Stop()
+	slow.Stop()
+	clock.Advance(time.Hour)
+	assert.Equal(start.Add(time.Hour+3*time.Second), clock.Now())
+
+	// Timers fire once they're due, without waiting to be received
+	after := clock.After(time.Minute)
+	clock.Advance(59 * time.Second)
+	assert.Len(after, 0)
+	clock.Advance(1 * time.Second)
+	assert.Equal(start.Add(time.Hour+time.Minute+3*time.Second), <-after)
+}
+
+type resultRecorder struct {
+	results []GameResult
+}
+
+func (rr *resultRecorder) GameCompleted(result GameResult) {
+	rr.results = append(rr.results, result)
+}
+
+type eventRecorder struct {
+	rooms []string
+	types []string
+}
+
+func (er *eventRecorder) GameEvent(room string, event *Event) {
+	er.rooms = append(er.rooms, room)
+	er.types = append(er.types, event.Type)
+}
+
+func TestEngineFastForward(t *testing.T) {
+	assert := assert.New(t)
+
+	start := time.Now()
+	clock := NewFakeClock(start)
+	game := NewGame(NewRNG(MaxNum))
+	game.Clock = clock
+	engine := NewEngine(game, &EngineConfig{
+		GameSpeed:    1 * time.Second,
+		WaitingCount: 3,
+	})
+	engine.Clock = clock
+	recorder := &resultRecorder{}
+	engine.Listeners = append(engine.Listeners, recorder)
+	watcher := &eventRecorder{}
+	engine.Room = MainRoom
+	engine.Watchers = append(engine.Watchers, watcher)
+	engine.Start(context.Background())
+	broadcast := make(chan []string, 1)
+	go func() {
+		types := []string{}
+		for event := range engine.Event {
+			types = append(types, event.Type)
+			if event.Type == GameCompleted.String() {
+				broadcast <- types
+			}
+		}
+	}()
+
+	ctx := context.Background()
+	_, err := engine.Do(ctx, &Action{Type: ActionTypeJoinGame, Player: &Player{Name: "Steve", First: 3, Second: 7}})
+	assert.Nil(err)
+	_, err = engine.Do(ctx, &Action{Type: ActionTypeJoinGame, Player: &Player{Name: "Sarah", First: 4, Second: 8}})
+	assert.Nil(err)
+
+	// A whole game in an instant
+	clock.Advance(10 * time.Minute)
+	engine.Stop(context.Background())
+
+	assert.Len(recorder.results, 1)
+	assert.True(recorder.results[0].CompletedAt.After(start))
+	assert.True(recorder.results[0].CompletedAt.Before(start.Add(10 * time.Minute)))
+
+	// Watchers hear everything that's broadcast, in order
+	types := <-broadcast
+	assert.Equal(types, watcher.types[:len(types)])
+	assert.Equal(MainRoom, watcher.rooms[0])
+}
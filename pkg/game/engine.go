@@ -1,190 +1,698 @@
 package game
 
 import (
+	"context"
+	"errors"
 	"log"
+	"net/http"
+	"sync"
 	"time"
+
+	"networkgaming.co.uk/techtest/pkg/problem"
+)
+
+const (
+	// DefaultQueueSize - Actions waiting for the engine before more are turned away
+	DefaultQueueSize = 64
+	// DefaultActionTimeout - Longest an action waits for the engine to reply
+	DefaultActionTimeout = 5 * time.Second
+)
+
+var (
+	ErrEngineStopped = problem.New("engine_stopped", http.StatusServiceUnavailable, "Unavailable: The game has stopped")
+	ErrEngineBusy    = problem.New("engine_busy", http.StatusServiceUnavailable, "Unavailable: The server is busy, try again shortly")
+	ErrEngineTimeout = problem.New("engine_timeout", http.StatusServiceUnavailable, "Unavailable: The game didn't answer in time, try again shortly")
+	ErrEngineRunning = problem.New("engine_running", http.StatusConflict, "Invalid action: The game is already running")
 )
 
 // ActionType - wrapper around int
 type ActionType int
 
 // Player - Not in the game
+// Picks overrides First and Second when set, Range sets explicit bounds.
+// StaySeated carries the player over to the next game when this one ends.
+// Account is the wallet account their stake comes from, see Accounts.
 type Player struct {
-	Name   string
-	First  int
-	Second int
+	Name       string
+	First      int
+	Second     int
+	Picks      []int
+	Range      *Range
+	Bot        bool
+	StaySeated bool
+	Account    string
 }
 
 // Action - external actions that may affect the state of the game
+// Strategy names the bot strategy for ActionTypeAddBot.
+// Token must match the engine's HostToken for host actions.
+// Notice is what subscribers are told by ActionTypeDrain.
+// Context is the caller's, once it's done the engine skips the action or drops its reply.
 type Action struct {
-	Type   ActionType
-	Player *Player
-	Reply  chan *ActionResponse
+	Type     ActionType
+	Player   *Player
+	Strategy string
+	Token    string
+	Settings *RoomSettings
+	Notice   *ShutdownNotice
+	Context  context.Context
+	Reply    chan *ActionResponse
+}
+
+// abandoned - The caller has given up waiting
+func (a *Action) abandoned() bool {
+	return a.Context != nil && a.Context.Err() != nil
+}
+
+// reply - Answer the caller, unless they've gone away
+func (a *Action) reply(ar *ActionResponse) {
+	if a.Context == nil {
+		a.Reply <- ar
+		return
+	}
+
+	select {
+	case a.Reply <- ar:
+	case <-a.Context.Done():
+	}
 }
 
 // ActionTypes
 const (
 	ActionTypeJoinGame    ActionType = 0
 	ActionTypeObserveGame ActionType = 1
+	ActionTypeAdjustGame  ActionType = 2
+	ActionTypeAddBot      ActionType = 3
+	// Host actions
+	ActionTypeStartGame      ActionType = 4
+	ActionTypeKickPlayer     ActionType = 5
+	ActionTypeChangeSettings ActionType = 6
+	// Server actions
+	ActionTypeDrain ActionType = 7
+	// Audit actions
+	ActionTypeReadLog ActionType = 8
+	// Lobby actions
+	ActionTypeCheckIdle ActionType = 9
 )
 
 // ActionResponse - Result of action returned to original caller
+// Err is the error behind Message for callers that need to tell errors apart.
+// Snapshot is set in reply to ActionTypeObserveGame, Position to ActionTypeJoinGame,
+// Log to ActionTypeReadLog and Idle to ActionTypeCheckIdle.
 type ActionResponse struct {
-	Success bool
-	Message string
+	Success  bool
+	Message  string
+	Err      error
+	Snapshot *Snapshot
+	Position int
+	Log      []DomainEvent
+	Idle     bool
 }
 
-// Engine - Runs the game and mutates game state
+// Engine - Runs the game and mutates game state.
+// Listeners are told about every completed game, Watchers every event broadcast.
 type Engine struct {
 	Event        chan *Event
 	Action       chan *Action
-	Cancel       chan chan bool
 	Game         GameI
 	Config       *EngineConfig
-	running      bool
+	Stakes       Stakes
+	Listeners    []ResultListener
+	Watchers     []EventListener
+	Room         string
+	HostToken    string
+	Chat         *Chat
 	count        int
 	countingDown bool
-	Ticker       <-chan time.Time
+	waited       int
+	bots         int
+	botRand      NumberGenerator
+	Clock        Clock
+	ticker       Ticker
+	Checkpoints  CheckpointStore
+	ctx          context.Context
+	running      bool
+	started      bool
+	draining     bool
+	finish       bool
+	drained      chan struct{}
+	stop         chan struct{}
+	done         chan struct{}
+	mu           sync.Mutex
 }
 
 // EngineConfig - Parameters that alter the behavior of the game
+// ManualRun never ticks, the engine only answers actions.
+// WaitingInterval, CountdownStep, RoundInterval and Intermission time each phase,
+// GameSpeed when not set. RoundAcceleration takes that fraction off each round's
+// interval, never going quicker than MinRoundInterval.
+// BotAfterTicks fills the table with BotStrategy bots when players have been
+// waiting that many ticks for an opponent, zero never adds bots.
+// SeatTimeout releases players left seated that long without a game starting,
+// zero keeps them for good.
+// QueueSize and ActionTimeout bound actions sent with Do, zero uses the defaults.
 type EngineConfig struct {
-	WaitingCount int
-	GameSpeed    time.Duration
-	ManualRun    bool
+	WaitingCount      int
+	GameSpeed         time.Duration
+	WaitingInterval   time.Duration
+	CountdownStep     time.Duration
+	RoundInterval     time.Duration
+	RoundAcceleration float64
+	Intermission      time.Duration
+	ManualRun         bool
+	BotAfterTicks     int
+	BotStrategy       string
+	SeatTimeout       time.Duration
+	QueueSize         int
+	ActionTimeout     time.Duration
 }
 
 // NewEngine - Initiates a new Engine with given Game and Config
 func NewEngine(game GameI, config *EngineConfig) *Engine {
 
+	queueSize := config.QueueSize
+	if queueSize <= 0 {
+		queueSize = DefaultQueueSize
+	}
+
 	event := make(chan *Event)
-	action := make(chan *Action)
-	cancel := make(chan chan bool)
+	action := make(chan *Action, queueSize)
 
 	return &Engine{
-		Event:  event,
-		Cancel: cancel,
-		Action: action,
-		Game:   game,
-		Config: config,
+		Event:   event,
+		Action:  action,
+		Game:    game,
+		Config:  config,
+		botRand: NewRNG(MaxNum),
+		Clock:   RealClock{},
+		done:    make(chan struct{}),
 	}
 }
 
 // Start - The engine, enters the game loop
-// Handles time ticks, external actions, game mutations, and broadcasting events
-func (eng *Engine) Start() {
+// Handles time ticks, external actions, game mutations, and broadcasting events.
+// Ticks come from the engine's Clock, timed for the phase the game is in.
+// The engine runs until it's stopped or ctx is done, either way the game is
+// cancelled and every stake refunded, unless it was left in play by Drain for
+// Restore to pick up. A stopped engine is started again with Restart.
+func (eng *Engine) Start(ctx context.Context) error {
+	eng.mu.Lock()
+	defer eng.mu.Unlock()
 
-	// log.Println("GameEngine - Starting...")
-	eng.running = true
-	go func() {
-
-		// If we are not manual set a timed ticker
-		if !eng.Config.ManualRun {
-			// TODO: Swap this for NewTicker as this is a memory leak if not cancelled
-			eng.Ticker = time.Tick(eng.Config.GameSpeed)
-		}
-
-		// log.Println("GameEngine - Entering game loop...")
-		for {
-			// log.Println("GameEngine - In")
-			select {
-			case <-eng.Ticker:
-				gameState := eng.Game.GetState()
-				switch gameState {
-
-				case GameStateWaiting:
-					// log.Println("GameEngine - Waiting")
-					// Add new players to the game
-					joined, _ := eng.Game.AddWaitingPlayersToGame()
-					if len(joined) > 0 {
-						eng.Event <- NewEvent(PlayerJoined, joined)
-						// log.Printf("Waiting - Added players to the game [+%v]\n", joined)
-					}
-					eng.Game.GetReady()
-					eng.Event <- NewEvent(GameWaiting, nil)
-				case GameStateReady:
-					// log.Println("GameEngine - Ready Countdown")
-					// Add new players on countdown:
-					joined, _ := eng.Game.AddWaitingPlayersToGame()
-					if len(joined) > 0 {
-						// log.Printf("Ready - Added players to the game [+%v]\n", joined)
-						eng.Event <- NewEvent(PlayerJoined, joined)
-					}
-					if !eng.countingDown {
-						// Start counting down if we haven't already
-						eng.startCountdown()
-						eng.Event <- NewEvent(CountdownStarted, eng.count)
-					} else if eng.isCountdownComplete() {
-						// Check if countdown is complete
-						eng.Game.Start()
-						eng.Event <- NewEvent(GameStarted, eng.count)
-					} else {
-						// Otherwise, keep counting down
-						eng.countdown()
-						eng.Event <- NewEvent(CountingDown, eng.count)
-					}
-
-				case GameStateInProgress:
-					// log.Println("GameEngine - Playing Round")
-					err := eng.Game.PlayRound()
-					if err != nil {
-						// If something bad's happened here, we want to know about it!
-						log.Fatal(err.Error())
-						return
-					}
-					eng.Event <- NewEvent(PlayedRound, eng.Game.GetRoundResult())
-					// log.Printf("Played Round: %+v\n", eng.Game)
-
-				case GameStateCompleted:
-					// log.Println("GameEngine - Completed")
-					winner, err := eng.Game.NominateWinner()
-					if err != nil {
-						// If something bad's happened here, we want to know about it!
-						log.Fatal(err.Error())
-						return
-					}
-					eng.Event <- NewEvent(GameCompleted, winner)
-					eng.Game.Reset()
-					eng.resetCountdown()
-					eng.Event <- NewEvent(GameReset, eng.Game)
-
-				case GameStateCancelled:
-					// log.Println("GameEngine - Cancelled")
-					// TODO: Implement
-					return
-				}
-
-			case action := <-eng.Action:
-
-				switch action.Type {
-
-				case ActionTypeJoinGame:
-					// log.Println("Received Join Game Action")
-					err := eng.Game.RegisterPlayer(action.Player)
-					if err != nil {
-						action.Reply <- &ActionResponse{false, err.Error()}
-						log.Printf("Unable to add player: %s\n", err.Error())
-					} else {
-						action.Reply <- &ActionResponse{true, ""}
-						eng.Event <- NewEvent(PlayerRegistered, eng.Game)
-					}
-				}
-
-			case wait := <-eng.Cancel:
-				eng.Game.Cancel()
-				eng.running = false
-				wait <- true
-				return
+	if eng.running {
+		return ErrEngineRunning
+	}
+	if eng.started {
+		return ErrEngineStopped
+	}
+	eng.start(ctx)
+
+	return nil
+}
+
+// Restart - Start a stopped engine again with the context it was first started with.
+// Everyone had their stake back when it stopped, so it starts on a cleared table.
+func (eng *Engine) Restart() error {
+	eng.mu.Lock()
+	defer eng.mu.Unlock()
+
+	if eng.running {
+		return ErrEngineRunning
+	}
+	if !eng.started {
+		return ErrEngineStopped
+	}
+	if err := eng.ctx.Err(); err != nil {
+		return err
+	}
+
+	// A game drained mid play was left for a restore, and can't be cleared until it's called off
+	if eng.inPlay() {
+		eng.Game.Cancel()
+		if eng.Stakes != nil {
+			if err := eng.Stakes.Refund(); err != nil {
+				log.Printf("Unable to refund stakes: %s\n", err.Error())
 			}
-			// log.Println("GameEngine - Out")
 		}
-	}()
+	}
+	if err := eng.Game.Reset(); err != nil {
+		return err
+	}
+	eng.Game.TakeEvents()
+	eng.resetCountdown()
+	eng.waited = 0
+	eng.draining = false
+	eng.finish = false
+	eng.done = make(chan struct{})
+	eng.start(eng.ctx)
+
+	return nil
+}
+
+// Stop - Cancel the game, refund every stake and wait for the engine to stop,
+// or for ctx to be done. Stopping a stopped engine does nothing.
+func (eng *Engine) Stop(ctx context.Context) error {
+	eng.mu.Lock()
+	if !eng.running {
+		eng.mu.Unlock()
+		return nil
+	}
+	select {
+	case <-eng.stop:
+	default:
+		close(eng.stop)
+	}
+	done := eng.done
+	eng.mu.Unlock()
+
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
 }
 
+// Wait - Block until the engine stops, returns straight away if it isn't running
+func (eng *Engine) Wait() {
+	eng.mu.Lock()
+	running, done := eng.running, eng.done
+	eng.mu.Unlock()
+
+	if running {
+		<-done
+	}
+}
+
+// IsRunning - Between Start or Restart and the engine stopping
 func (eng *Engine) IsRunning() bool {
+	eng.mu.Lock()
+	defer eng.mu.Unlock()
+
 	return eng.running
 }
 
+// start - Spin up the game loop, call holding mu
+func (eng *Engine) start(ctx context.Context) {
+	eng.ctx = ctx
+	eng.running = true
+	eng.started = true
+	eng.stop = make(chan struct{})
+	// Before returning, so a FakeClock advanced straight after Start ticks the engine
+	eng.ticker = nil
+	if !eng.Config.ManualRun {
+		eng.ticker = eng.Clock.NewTicker(eng.interval())
+	}
+
+	go eng.run(ctx, eng.stop, eng.done)
+}
+
+// run - The game loop
+func (eng *Engine) run(ctx context.Context, stop chan struct{}, done chan struct{}) {
+	defer func() {
+		if eng.ticker != nil {
+			eng.ticker.Stop()
+		}
+		eng.mu.Lock()
+		eng.running = false
+		eng.mu.Unlock()
+		close(done)
+	}()
+
+	var ticks <-chan time.Time
+	if eng.ticker != nil {
+		ticks = eng.ticker.C()
+	}
+
+	// log.Println("GameEngine - Entering game loop...")
+	for {
+		// log.Println("GameEngine - In")
+		select {
+		case <-ticks:
+			if eng.Game.GetState() == GameStateCancelled {
+				log.Println("Game cancelled, stopping the engine")
+				eng.shutdown()
+				return
+			}
+			// Next tick is set before anyone hears about this one
+			events := eng.tick()
+			eng.schedule()
+			eng.settle()
+			eng.save(eng.checkpoint())
+			eng.emit(events...)
+
+		case action := <-eng.Action:
+			if action.abandoned() {
+				continue
+			}
+			eng.act(action)
+			if action.Type != ActionTypeObserveGame && action.Type != ActionTypeReadLog {
+				eng.save(eng.checkpoint())
+			}
+			eng.emit(eng.Game.TakeEvents()...)
+
+		case <-stop:
+			eng.shutdown()
+			return
+
+		case <-ctx.Done():
+			eng.shutdown()
+			return
+		}
+		// log.Println("GameEngine - Out")
+	}
+}
+
+// act - Carry out an action, replying before broadcasting what changed
+func (eng *Engine) act(action *Action) {
+	if eng.refuse(action) {
+		action.reply(&ActionResponse{Success: false, Message: ErrShuttingDown.Error(), Err: ErrShuttingDown})
+		return
+	}
+
+	switch action.Type {
+
+	case ActionTypeJoinGame:
+		// log.Println("Received Join Game Action")
+		err := eng.stake(action.Player)
+		if err != nil {
+			action.reply(&ActionResponse{Success: false, Message: err.Error(), Err: err})
+			log.Printf("Unable to add player: %s\n", err.Error())
+		} else {
+			action.reply(&ActionResponse{Success: true, Position: eng.position(action.Player.Name)})
+			eng.emit(NewEvent(PlayerRegistered, eng.Game))
+		}
+
+	case ActionTypeAddBot:
+		info, err := eng.addBot(action.Strategy)
+		if err != nil {
+			action.reply(&ActionResponse{Success: false, Message: err.Error(), Err: err})
+			log.Printf("Unable to add bot: %s\n", err.Error())
+		} else {
+			action.reply(&ActionResponse{Success: true, Message: info.Name})
+			eng.emit(NewEvent(BotAdded, info))
+		}
+
+	case ActionTypeStartGame, ActionTypeKickPlayer, ActionTypeChangeSettings:
+		events, err := eng.host(action)
+		if err != nil {
+			action.reply(&ActionResponse{Success: false, Message: err.Error(), Err: err})
+			log.Printf("Unable to carry out host action: %s\n", err.Error())
+		} else {
+			action.reply(&ActionResponse{Success: true})
+		}
+		eng.schedule()
+		eng.emit(events...)
+
+	case ActionTypeObserveGame:
+		action.reply(&ActionResponse{Success: true, Snapshot: eng.snapshot()})
+
+	case ActionTypeReadLog:
+		action.reply(&ActionResponse{Success: true, Log: eng.Game.Log()})
+
+	case ActionTypeCheckIdle:
+		action.reply(&ActionResponse{Success: true, Idle: !eng.inPlay() && eng.Game.Vacant()})
+
+	case ActionTypeDrain:
+		event := eng.drain(action.Notice)
+		action.reply(&ActionResponse{Success: true})
+		eng.emit(event)
+
+	case ActionTypeAdjustGame:
+		err := eng.Game.AdjustPlayer(action.Player)
+		if err != nil {
+			action.reply(&ActionResponse{Success: false, Message: err.Error(), Err: err})
+			log.Printf("Unable to adjust player: %s\n", err.Error())
+		} else {
+			action.reply(&ActionResponse{Success: true})
+			eng.emit(NewEvent(PlayerAdjusted, eng.Game.GetRoundResult()))
+		}
+	}
+}
+
+// emit - Broadcast events in order, dropping them once the engine's stopping
+// so a broadcaster that's gone first can't hold it up
+func (eng *Engine) emit(events ...*Event) {
+	for _, event := range events {
+		eng.notify(event)
+		select {
+		case eng.Event <- event:
+		case <-eng.stop:
+			return
+		case <-eng.ctx.Done():
+			return
+		}
+	}
+}
+
+// shutdown - Cancel the game and hand back every stake. A game still in play when
+// draining is left as it was checkpointed, stakes held, for the next start to restore.
+func (eng *Engine) shutdown() {
+	if eng.Checkpoints != nil && eng.draining && eng.inPlay() {
+		return
+	}
+	eng.Game.Cancel()
+	if eng.Stakes != nil {
+		if err := eng.Stakes.Refund(); err != nil {
+			log.Printf("Unable to refund stakes: %s\n", err.Error())
+			return
+		}
+	}
+	checkpoint, err := eng.checkpoint()
+	if err == nil {
+		checkpoint.Settled = true
+	}
+	eng.save(checkpoint, err)
+}
+
+// Do - Queue an action and wait for the engine's reply. Fails fast with ErrEngineBusy
+// when the queue is full and ErrEngineStopped once the engine has stopped, and gives
+// up with ErrEngineTimeout after the ActionTimeout on the engine's Clock or ctx's error once ctx is done.
+func (eng *Engine) Do(ctx context.Context, action *Action) (*ActionResponse, error) {
+	// Timed by the engine's Clock, cancelled so the engine skips it once it's timed out
+	timeout := eng.Clock.After(eng.actionTimeout())
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	// Room for the reply so the engine never waits on a caller who's gone
+	reply := make(chan *ActionResponse, 1)
+	action.Context = ctx
+	action.Reply = reply
+	if ctx.Err() != nil {
+		return nil, ctx.Err()
+	}
+	eng.mu.Lock()
+	done := eng.done
+	eng.mu.Unlock()
+	select {
+	case <-done:
+		return nil, ErrEngineStopped
+	default:
+	}
+	select {
+	case eng.Action <- action:
+	default:
+		return nil, ErrEngineBusy
+	}
+
+	var ar *ActionResponse
+	select {
+	case ar = <-reply:
+	case <-done:
+		return nil, ErrEngineStopped
+	case <-timeout:
+		return nil, ErrEngineTimeout
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+	if !ar.Success {
+		if ar.Err != nil {
+			return ar, ar.Err
+		}
+		return ar, errors.New(ar.Message)
+	}
+
+	return ar, nil
+}
+
+// actionTimeout - How long Do waits for a reply
+func (eng *Engine) actionTimeout() time.Duration {
+	if eng.Config.ActionTimeout > 0 {
+		return eng.Config.ActionTimeout
+	}
+
+	return DefaultActionTimeout
+}
+
+// tick - Move the game on, returning the events to broadcast.
+// While draining only a game in play is moved on, no new one is started.
+func (eng *Engine) tick() []*Event {
+	events := eng.expire()
+	if eng.draining && !eng.inPlay() {
+		return events
+	}
+	switch eng.Game.GetState() {
+
+	case GameStateWaiting:
+		// log.Println("GameEngine - Waiting")
+		// Add new players to the game
+		events = append(events, eng.seat()...)
+		if eng.Game.GetReady() == nil && eng.Game.GetState() == GameStateReady {
+			events = append(events, NewEvent(GameReady, eng.Game.GetRoundResult()))
+		} else {
+			events = append(events, NewEvent(GameWaiting, nil))
+		}
+		events = append(events, eng.fillWithBots()...)
+
+	case GameStateReady:
+		// log.Println("GameEngine - Ready Countdown")
+		// Add new players on countdown:
+		events = append(events, eng.seat()...)
+		if !eng.countingDown {
+			// Start counting down if we haven't already
+			eng.startCountdown()
+			events = append(events, NewEvent(CountdownStarted, eng.count).Until(eng.countdownDeadline()))
+		} else if eng.isCountdownComplete() {
+			// Check if countdown is complete, if the game can't start the countdown begins again
+			if err := eng.Game.Start(); err != nil {
+				log.Printf("Unable to start game: %s\n", err.Error())
+			} else {
+				events = append(events, NewEvent(GameStarted, eng.count).Until(eng.deadline()))
+			}
+		} else {
+			// Otherwise, keep counting down
+			eng.countdown()
+			events = append(events, NewEvent(CountingDown, eng.count).Until(eng.countdownDeadline()))
+		}
+
+	case GameStateInProgress:
+		// log.Println("GameEngine - Playing Round")
+		err := eng.Game.PlayRound()
+		if err != nil {
+			// If something bad's happened here, we want to know about it!
+			log.Fatal(err.Error())
+		}
+		events = append(events, NewEvent(PlayedRound, eng.Game.GetRoundResult()).Until(eng.deadline()))
+		events = append(events, eng.Game.TakeEvents()...)
+		// log.Printf("Played Round: %+v\n", eng.Game)
+
+	case GameStateCompleted:
+		// log.Println("GameEngine - Completed")
+		winner, err := eng.Game.NominateWinner()
+		if err != nil {
+			// If something bad's happened here, we want to know about it!
+			log.Fatal(err.Error())
+		}
+		events = append(events, NewEvent(GameCompleted, winner))
+		eng.publishResult(winner)
+		if eng.Stakes != nil {
+			eng.payout(winner)
+		}
+		// Give everyone time to see who won before clearing the table
+		eng.Game.Intermission()
+		events = append(events, NewEvent(IntermissionStarted, nil).Until(eng.deadline()))
+
+	case GameStateIntermission:
+		// log.Println("GameEngine - Intermission over")
+		eng.Game.Reset()
+		events = append(events, eng.Game.TakeEvents()...)
+		events = append(events, eng.restake()...)
+		eng.resetCountdown()
+		events = append(events, NewEvent(GameReset, eng.Game))
+	}
+
+	// Whatever else the game raised, state changes included
+	return append(events, eng.Game.TakeEvents()...)
+}
+
+// payout - Pay the pot to the winner and anyone tied with them. Bots never stake so never
+// take a share, a game a bot won is handed back to everyone as if it were called off.
+func (eng *Engine) payout(winner GamePlayer) {
+	var err error
+	if winner.Bot {
+		err = eng.Stakes.Refund()
+	} else {
+		err = eng.Stakes.Payout(winner.Name, tiedNames(eng.Game.GetRoundResult(), winner))
+	}
+	if err != nil {
+		log.Printf("Unable to pay out pot: %s\n", err.Error())
+	}
+}
+
+// stake - Register the player, taking their stake first if the game is played for stakes
+func (eng *Engine) stake(player *Player) error {
+	if eng.Stakes == nil {
+		return eng.Game.RegisterPlayer(player)
+	}
+
+	// Normalises the name, so the stake is held under the name that's played
+	if err := eng.Game.ValidatePlayer(player); err != nil {
+		return err
+	}
+	if err := eng.Stakes.Stake(player.Name, player.Account); err != nil {
+		return err
+	}
+	if err := eng.Game.RegisterPlayer(player); err != nil {
+		eng.Stakes.Unstake(player.Name)
+		return err
+	}
+
+	return nil
+}
+
+// fillWithBots - Seat bots for anyone left waiting too long without an opponent
+func (eng *Engine) fillWithBots() []*Event {
+	if eng.Config.BotAfterTicks <= 0 || eng.Game.GetState() != GameStateWaiting {
+		eng.waited = 0
+		return nil
+	}
+
+	seated := len(eng.Game.GetRoundResult().LeaderBoard)
+	if seated == 0 || seated >= MinPlayersRequired {
+		eng.waited = 0
+		return nil
+	}
+
+	eng.waited++
+	if eng.waited < eng.Config.BotAfterTicks {
+		return nil
+	}
+	eng.waited = 0
+
+	events := []*Event{}
+	for i := seated; i < MinPlayersRequired; i++ {
+		info, err := eng.addBot(eng.Config.BotStrategy)
+		if err != nil {
+			log.Printf("Unable to add bot: %s\n", err.Error())
+			break
+		}
+		events = append(events, NewEvent(BotAdded, info))
+	}
+
+	return events
+}
+
+// addBot - Register a bot with a name that isn't taken yet
+func (eng *Engine) addBot(strategyName string) (BotInfo, error) {
+	strategy, err := NewBotStrategy(strategyName, eng.botRand)
+	if err != nil {
+		return BotInfo{}, err
+	}
+
+	name := botName(strategyName, eng.bots+1)
+	for eng.Game.CheckPlayerExists(name) != nil {
+		eng.bots++
+		name = botName(strategyName, eng.bots+1)
+	}
+	eng.bots++
+
+	if err := eng.Game.RegisterBot(name, strategy); err != nil {
+		return BotInfo{}, err
+	}
+
+	return BotInfo{Name: name, Strategy: strategyName, Bot: true}, nil
+}
+
 func (eng *Engine) startCountdown() {
 	eng.resetCountdown()
 	eng.countingDown = true
@@ -1,6 +1,7 @@
 package game
 
 import (
+	"context"
 	"testing"
 	"time"
 
@@ -23,17 +24,61 @@ func TestEngineStartStop(t *testing.T) {
 		ManualRun:    true,
 	}
 	engine := NewEngine(mockGame, engineConfig)
-	engine.Start()
+	engine.Start(context.Background())
 
 	// Tests
 	assert.Equal(engine.IsRunning(), true)
-	wait := make(chan bool)
-	engine.Cancel <- wait
-	<-wait
+	engine.Stop(context.Background())
 	assert.Equal(engine.IsRunning(), false)
 
 }
 
+func TestEngineRestart(t *testing.T) {
+	assert := assert.New(t)
+	game := NewGame(NewRNG(MaxNum))
+	engine := NewEngine(game, &EngineConfig{
+		GameSpeed:    1 * time.Minute,
+		WaitingCount: 10,
+		ManualRun:    true,
+	})
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	assert.Nil(engine.Start(ctx))
+	assert.Equal(ErrEngineRunning, engine.Start(ctx))
+	assert.Equal(ErrEngineRunning, engine.Restart())
+	go func() {
+		for range engine.Event {
+		}
+	}()
+	_, err := engine.Do(ctx, &Action{Type: ActionTypeJoinGame, Player: &Player{Name: "Steve", First: 3, Second: 7, StaySeated: true}})
+	assert.Nil(err)
+
+	// Stopping cancels the game, it's only started again with Restart
+	assert.Nil(engine.Stop(ctx))
+	assert.Nil(engine.Stop(ctx))
+	engine.Wait()
+	assert.False(engine.IsRunning())
+	assert.Equal(GameStateCancelled, game.GetState())
+	assert.Equal(ErrEngineStopped, engine.Start(ctx))
+	_, err = engine.Do(ctx, &Action{Type: ActionTypeObserveGame})
+	assert.Equal(ErrEngineStopped, err)
+
+	// On a cleared table
+	assert.Nil(engine.Restart())
+	assert.True(engine.IsRunning())
+	assert.Equal(GameStateWaiting, game.GetState())
+	assert.Nil(game.CheckPlayerExists("Steve"))
+	_, err = engine.Do(ctx, &Action{Type: ActionTypeJoinGame, Player: &Player{Name: "Steve", First: 3, Second: 7}})
+	assert.Nil(err)
+
+	// The context it was started with stops it too
+	cancel()
+	engine.Wait()
+	assert.False(engine.IsRunning())
+	assert.Equal(context.Canceled, engine.Restart())
+}
+
 func TestEngineStartGamePlayRoundsThenComplete(t *testing.T) {
 	assert := assert.New(t)
 	// TODO: Move to setup test func
@@ -42,20 +87,19 @@ func TestEngineStartGamePlayRoundsThenComplete(t *testing.T) {
 	engineConfig := &EngineConfig{
 		GameSpeed:    10 * time.Nanosecond,
 		WaitingCount: 10,
-		ManualRun:    true,
+		ManualRun:    false,
 	}
 	engine := NewEngine(mockGame, engineConfig)
-	// Set the manual ticker
-	manualTicker := NewManualTicker()
-	engine.Ticker = manualTicker.GetTicker()
-	engine.Start()
+	clock := NewFakeClock(time.Now())
+	engine.Clock = clock
+	engine.Start(context.Background())
 
 	// Tests
 	assert.Equal(engine.IsRunning(), true)
 	// Put the game into ready mode, engine should start playing rounds
 	mockGame.State = GameStateInProgress
 	for i := 0; i < mockGameMaxRounds; i++ {
-		manualTicker.Tick()
+		clock.Advance(engineConfig.GameSpeed)
 		<-engine.Event
 	}
 	assert.Equal(mockGameMaxRounds, mockGame.PlayRoundCalled)
@@ -74,17 +118,38 @@ func TestEngineAddingPlayerToGame(t *testing.T) {
 		ManualRun:    true,
 	}
 	engine := NewEngine(mockGame, engineConfig)
-	engine.Start()
+	engine.Start(context.Background())
 
 	// Tests
-	player := &Player{"Steve", 5, 3}
+	player := &Player{Name: "Steve", First: 5, Second: 3}
 	rc := make(chan *ActionResponse)
-	join := &Action{ActionTypeJoinGame, player, rc}
+	join := &Action{Type: ActionTypeJoinGame, Player: player, Reply: rc}
 	engine.Action <- join
 	resp := <-rc
 	assert.True(resp.Success)
 }
 
+func TestEngineCountdownWithoutEnoughPlayers(t *testing.T) {
+	assert := assert.New(t)
+	game := NewGame(NewRNG(MaxNum))
+	engine := NewEngine(game, &EngineConfig{GameSpeed: 10 * time.Nanosecond, WaitingCount: 2, ManualRun: true})
+	game.RegisterPlayer(&Player{Name: "Steve", First: 3, Second: 9})
+	game.RegisterPlayer(&Player{Name: "Sarah", First: 4, Second: 2})
+	game.AddWaitingPlayersToGame()
+	assert.Nil(game.GetReady())
+	engine.startCountdown()
+	engine.countdown()
+	// Gone before the countdown finishes
+	delete(game.Players, "Sarah")
+
+	for _, event := range engine.tick() {
+		assert.NotEqual(GameStarted.String(), event.Type)
+	}
+	assert.Equal(GameStateReady, game.GetState())
+	events := engine.tick()
+	assert.Equal(CountdownStarted.String(), events[0].Type)
+}
+
 func TestEngineCountdown(t *testing.T) {
 	assert := assert.New(t)
 	// TODO: Move to setup test func
@@ -93,51 +158,114 @@ func TestEngineCountdown(t *testing.T) {
 	engineConfig := &EngineConfig{
 		GameSpeed:    10 * time.Minute,
 		WaitingCount: 10,
-		ManualRun:    true,
+		ManualRun:    false,
 	}
 	engine := NewEngine(game, engineConfig)
-	// Set the manual ticker
-	manualTicker := NewManualTicker()
-	engine.Ticker = manualTicker.GetTicker()
-	engine.Start()
+	clock := NewFakeClock(time.Now())
+	engine.Clock = clock
+	engine.Start(context.Background())
 
 	// Game should be waiting for minimum players to join
 	assert.Equal(GameStateWaiting, game.GetState())
 	// Tests
-	playerOne := &Player{"Steve", 5, 3}
-	playerTwo := &Player{"Sarah", 4, 1}
+	playerOne := &Player{Name: "Steve", First: 5, Second: 3}
+	playerTwo := &Player{Name: "Sarah", First: 4, Second: 1}
 	rc := make(chan *ActionResponse)
-	join := &Action{ActionTypeJoinGame, playerOne, rc}
+	join := &Action{Type: ActionTypeJoinGame, Player: playerOne, Reply: rc}
 	engine.Action <- join
 	<-rc
 	<-engine.Event
 	// Engine should be waiting for one more player
 	assert.Equal(GameStateWaiting, game.GetState())
-	join = &Action{ActionTypeJoinGame, playerTwo, rc}
+	join = &Action{Type: ActionTypeJoinGame, Player: playerTwo, Reply: rc}
 	engine.Action <- join
 	<-rc
 	<-engine.Event
 	// Turn the engine manually once
-	manualTicker.Tick()
-	// Add waiting players to game
-	<-engine.Event
+	clock.Advance(engineConfig.GameSpeed)
+	// Add waiting players to game, and it's ready as it has enough of them
 	<-engine.Event
+	assert.Equal(GameReady.String(), (<-engine.Event).Type)
+	event := <-engine.Event
+	assert.Equal(StateChanged.String(), event.Type)
+	assert.Equal(Transition{From: GameStateWaiting, To: GameStateReady}, event.Data)
 
 	// Game should be in ready state as it has enough players
 	assert.Equal(GameStateReady, game.GetState())
 	// Start countdown
-	manualTicker.Tick()
-	event := <-engine.Event
+	clock.Advance(engineConfig.GameSpeed)
+	event = <-engine.Event
 	assert.Equal(CountdownStarted.String(), event.Type)
 	// continue the countdown 10, 9, 8,...,1
 	for i := 1; i < engine.Config.WaitingCount; i++ {
-		manualTicker.Tick()
+		clock.Advance(engineConfig.GameSpeed)
 		event = <-engine.Event
 		assert.Equal(CountingDown.String(), event.Type)
 	}
 	// Next turn should start the game
-	manualTicker.Tick()
+	clock.Advance(engineConfig.GameSpeed)
 	event = <-engine.Event
 	assert.Equal(GameStarted.String(), event.Type)
 	assert.Equal(GameStateInProgress, game.GetState())
 }
+
+func TestEngineDo(t *testing.T) {
+	assert := assert.New(t)
+
+	clock := NewFakeClock(time.Date(2026, 10, 19, 12, 0, 0, 0, time.UTC))
+	game := NewGame(NewRNG(MaxNum))
+	engineConfig := &EngineConfig{
+		GameSpeed:     1 * time.Minute,
+		WaitingCount:  10,
+		ManualRun:     true,
+		QueueSize:     2,
+		ActionTimeout: 5 * time.Second,
+	}
+	engine := NewEngine(game, engineConfig)
+	engine.Clock = clock
+	ctx := context.Background()
+
+	// Callers who've already gone aren't queued at all
+	cancelled, cancel := context.WithCancel(ctx)
+	cancel()
+	_, err := engine.Do(cancelled, &Action{Type: ActionTypeJoinGame, Player: &Player{Name: "Stan", First: 4, Second: 8}})
+	assert.Equal(context.Canceled, err)
+
+	// Nothing's taking actions yet, so Steve's waits in the queue until it times out
+	timedOut := make(chan error)
+	go func() {
+		_, err := engine.Do(ctx, &Action{Type: ActionTypeJoinGame, Player: &Player{Name: "Steve", First: 3, Second: 7}})
+		timedOut <- err
+	}()
+	steve := <-engine.Action
+	clock.Advance(4 * time.Second)
+	select {
+	case err = <-timedOut:
+		assert.Fail("Gave up early", err)
+	default:
+	}
+	clock.Advance(1 * time.Second)
+	assert.Equal(ErrEngineTimeout, <-timedOut)
+
+	// Once the queue is full
+	engine.Action <- &Action{Type: ActionTypeJoinGame, Player: &Player{Name: "Stan", First: 4, Second: 8}, Context: cancelled}
+	engine.Action <- steve
+	_, err = engine.Do(ctx, &Action{Type: ActionTypeJoinGame, Player: &Player{Name: "Sarah", First: 4, Second: 8}})
+	assert.Equal(ErrEngineBusy, err)
+
+	// Leaving Steve's queued for the engine
+	<-engine.Action
+	engine.Start(context.Background())
+	ar, err := engine.Do(ctx, &Action{Type: ActionTypeJoinGame, Player: &Player{Name: "Simon", First: 2, Second: 9}})
+	assert.Nil(err)
+	assert.True(ar.Success)
+	<-engine.Event
+
+	// Actions whose callers gave up are skipped
+	assert.Nil(game.CheckPlayerExists("Steve"))
+	assert.Equal(ErrInvalidPlayerName, game.CheckPlayerExists("Simon"))
+
+	engine.Stop(context.Background())
+	_, err = engine.Do(ctx, &Action{Type: ActionTypeObserveGame})
+	assert.Equal(ErrEngineStopped, err)
+}
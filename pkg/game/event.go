@@ -1,45 +1,106 @@
 package game
 
+import "time"
+
 const (
 	// Events
-	PlayerJoined     EventType = 0
-	PlayerLeft       EventType = 1
-	PlayedRound      EventType = 2
-	GameCreated      EventType = 3
-	GameStarted      EventType = 4
-	GameCompleted    EventType = 5
-	GameReady        EventType = 6
-	GameWaiting      EventType = 7
-	CountdownStarted EventType = 8
-	CountingDown     EventType = 9
-	GameReset        EventType = 10
-	PlayerRegistered EventType = 11
+	PlayerJoined            EventType = 0
+	PlayerLeft              EventType = 1
+	PlayedRound             EventType = 2
+	GameCreated             EventType = 3
+	GameStarted             EventType = 4
+	GameCompleted           EventType = 5
+	GameReady               EventType = 6
+	GameWaiting             EventType = 7
+	CountdownStarted        EventType = 8
+	CountingDown            EventType = 9
+	GameReset               EventType = 10
+	PlayerRegistered        EventType = 11
+	PlayerEliminated        EventType = 12
+	SuddenDeath             EventType = 13
+	PlayerAdjusted          EventType = 14
+	BotAdded                EventType = 15
+	MatchFound              EventType = 16
+	QueuePosition           EventType = 17
+	TournamentStarted       EventType = 18
+	TournamentRoundStarted  EventType = 19
+	TournamentGameCompleted EventType = 20
+	TournamentCompleted     EventType = 21
+	PlayerKicked            EventType = 22
+	SettingsChanged         EventType = 23
+	ChatSent                EventType = 24
+	ChatRejected            EventType = 25
+	ChatModerated           EventType = 26
+	GameSnapshot            EventType = 27
+	WaitlistUpdated         EventType = 28
+	RateLimited             EventType = 29
+	IntermissionStarted     EventType = 30
+	ServerShuttingDown      EventType = 31
+	StateChanged            EventType = 32
 )
 
+// eventTypeNames - What each event type is called on the wire
+var eventTypeNames = [...]string{
+	"Player Joined",
+	"Player Left",
+	"Played Round",
+	"Game Created",
+	"Game Started",
+	"Game Completed",
+	"Game Ready",
+	"Game Waiting",
+	"Countdown Started",
+	"Counting Down",
+	"Game Reset",
+	"Player Registered",
+	"Player Eliminated",
+	"Sudden Death",
+	"Player Adjusted",
+	"Bot Added",
+	"Match Found",
+	"Queue Position",
+	"Tournament Started",
+	"Tournament Round Started",
+	"Tournament Game Completed",
+	"Tournament Completed",
+	"Player Kicked",
+	"Settings Changed",
+	"Chat Message",
+	"Chat Rejected",
+	"Chat Moderated",
+	"Snapshot",
+	"Waitlist Updated",
+	"Rate Limited",
+	"Intermission Started",
+	"Server Shutting Down",
+	"State Changed",
+}
+
 func (et EventType) String() string {
-	names := [...]string{
-		"Player Joined",
-		"Player Left",
-		"Played Round",
-		"Game Created",
-		"Game Started",
-		"Game Completed",
-		"Game Ready",
-		"Game Waiting",
-		"Countdown Started",
-		"Counting Down",
-		"Game Reset",
-		"Player Registered",
-	}
-
-	return names[et]
+	return eventTypeNames[et]
+}
+
+// EventTypeNames - Every event type's name, as sent in an event's type
+func EventTypeNames() []string {
+	return append([]string{}, eventTypeNames[:]...)
 }
 
+// Event - Deadline is when whatever the event is counting down to is due,
+// so clients can show a timer without keeping time themselves
 type Event struct {
-	Type string      `json:"type"`
-	Data interface{} `json:"data"`
+	Type     string      `json:"type"`
+	Data     interface{} `json:"data"`
+	Deadline *time.Time  `json:"deadline,omitempty"`
 }
 
 func NewEvent(eventType EventType, data interface{}) *Event {
-	return &Event{eventType.String(), data}
+	return &Event{Type: eventType.String(), Data: data}
+}
+
+// Until - Set the event's deadline
+func (e *Event) Until(deadline time.Time) *Event {
+	deadline = deadline.UTC()
+	e.Deadline = &deadline
+
+	return e
 }
@@ -0,0 +1,271 @@
+package game
+
+import (
+	"net/http"
+	"time"
+
+	"networkgaming.co.uk/techtest/pkg/names"
+	"networkgaming.co.uk/techtest/pkg/problem"
+)
+
+const (
+	// MaxLogSize - Past this many domain events, games before the current one are
+	// folded into a snapshot at the head of the log
+	MaxLogSize = 10000
+)
+
+var (
+	ErrRoundNotPlayed = problem.New("round_not_played", http.StatusNotFound, "Invalid round: That round hasn't been played in the latest game")
+)
+
+// DomainEventType - What changed in a game
+type DomainEventType string
+
+// DomainEventTypes, each carries the values the game changed to rather than how
+// to work them out, so folding the log never needs the game's dice or clock
+const (
+	DomainPlayerRegistered DomainEventType = "PlayerRegistered"
+	DomainPlayerSeated     DomainEventType = "PlayerSeated"
+	DomainPlayerLeft       DomainEventType = "PlayerLeft"
+	DomainGameStarted      DomainEventType = "GameStarted"
+	DomainNumberDrawn      DomainEventType = "NumberDrawn"
+	DomainScoreChanged     DomainEventType = "ScoreChanged"
+	DomainSuddenDeath      DomainEventType = "SuddenDeath"
+	DomainWinnerNominated  DomainEventType = "WinnerNominated"
+	DomainStateChanged     DomainEventType = "StateChanged"
+	DomainSettingsChanged  DomainEventType = "SettingsChanged"
+	DomainGameReset        DomainEventType = "GameReset"
+	DomainGameRestored     DomainEventType = "GameRestored"
+)
+
+// DomainEvent - One entry in a game's append only log.
+// Seq counts up from one for the life of the game, Round is the round the game
+// was on once the event was applied. A GameRestored snapshot that compacted the
+// log carries the Stats of the games folded into it.
+type DomainEvent struct {
+	Seq      int             `json:"seq"`
+	Type     DomainEventType `json:"type"`
+	At       time.Time       `json:"at"`
+	Round    int             `json:"round"`
+	Player   *GamePlayer     `json:"player,omitempty"`
+	Number   int             `json:"number,omitempty"`
+	Rogue    bool            `json:"rogue,omitempty"`
+	State    State           `json:"state,omitempty"`
+	Settings *RoomSettings   `json:"settings,omitempty"`
+	Snapshot *GameCheckpoint `json:"snapshot,omitempty"`
+	Stats    *Stats          `json:"stats,omitempty"`
+}
+
+// Log - Every domain event the game has applied, oldest first
+func (g *Game) Log() []DomainEvent {
+	return append([]DomainEvent{}, g.log...)
+}
+
+// record - Apply a change to the game and append it to the log
+func (g *Game) record(event DomainEvent) {
+	g.seq++
+	event.Seq = g.seq
+	if event.Player != nil {
+		player := *event.Player
+		event.Player = &player
+	}
+	if event.At.IsZero() {
+		event.At = g.Clock.Now()
+	}
+	from := g.state
+	g.apply(event)
+	if g.state != from {
+		g.events = append(g.events, NewEvent(StateChanged, Transition{From: from, To: g.state}))
+	}
+	event.Round = g.Round
+	if event.Snapshot != nil {
+		event.Snapshot = event.Snapshot.withoutAccounts()
+	}
+	g.log = append(g.log, event)
+	if len(g.log) > MaxLogSize {
+		g.compact()
+	}
+}
+
+// update - Record a seated player's new score, standing or picks
+func (g *Game) update(player GamePlayer) {
+	g.record(DomainEvent{Type: DomainScoreChanged, Player: &player})
+}
+
+// transition - Record the game moving to state, if it isn't there already
+// and the transition table allows it
+func (g *Game) transition(state State) error {
+	if err := g.check(state); err != nil {
+		return err
+	}
+	if g.state != state {
+		g.record(DomainEvent{Type: DomainStateChanged, State: state})
+	}
+
+	return nil
+}
+
+// apply - Make the change event describes, the only place a game's state is written
+func (g *Game) apply(event DomainEvent) {
+	switch event.Type {
+
+	case DomainPlayerRegistered:
+		player := *event.Player
+		g.registered[names.Skeleton(player.Name)] = player
+		g.waitingRoom = append(g.waitingRoom, &player)
+
+	case DomainPlayerSeated:
+		g.unwait(event.Player.Name)
+		g.Players[event.Player.Name] = *event.Player
+
+	case DomainPlayerLeft:
+		delete(g.Players, event.Player.Name)
+		delete(g.registered, names.Skeleton(event.Player.Name))
+		g.unwait(event.Player.Name)
+		g.updateTopScore()
+
+	case DomainGameStarted:
+		g.state = GameStateInProgress
+		g.Round = 0
+		g.StartedAt = event.At
+
+	case DomainNumberDrawn:
+		g.Numbers = append(g.Numbers, event.Number)
+		g.remember(event.Number)
+		g.Round++
+
+	case DomainScoreChanged:
+		g.Players[event.Player.Name] = *event.Player
+		g.updateTopScore()
+
+	case DomainSuddenDeath:
+		g.SuddenDeath = true
+		g.suddenDeathAt = g.Round
+
+	case DomainWinnerNominated:
+		g.Players[event.Player.Name] = *event.Player
+		g.Winner = *event.Player
+		g.Rogue = event.Rogue
+
+	case DomainStateChanged:
+		g.state = event.State
+
+	case DomainSettingsChanged:
+		g.configure(event.Settings)
+
+	case DomainGameReset:
+		g.Round = 0
+		g.state = GameStateWaiting
+		g.Winner = GamePlayer{}
+		g.Rogue = false
+		g.Numbers = make([]int, 0, MaxRounds)
+		g.StartedAt = time.Time{}
+		g.SuddenDeath = false
+		g.suddenDeathAt = 0
+		for k, player := range g.Players {
+			player.Score = 0
+			player.Streak = 0
+			player.Bust = false
+			player.Eliminated = false
+			player.Winner = false
+			player.JoinedAt = event.At
+			g.Players[k] = player
+		}
+		g.updateTopScore()
+
+	case DomainGameRestored:
+		g.restore(event.Snapshot)
+	}
+}
+
+// unwait - Take name out of the waiting room
+func (g *Game) unwait(name string) {
+	waiting := make([]*GamePlayer, 0, len(g.waitingRoom))
+	for _, player := range g.waitingRoom {
+		if player.Name != name {
+			waiting = append(waiting, player)
+		}
+	}
+	g.waitingRoom = waiting
+}
+
+// compact - Fold every game before the current one into a snapshot, keeping the
+// current game's events so it can still be audited round by round
+func (g *Game) compact() {
+	cut := lastIndex(g.log, DomainGameStarted)
+	if cut < 1 {
+		return
+	}
+	folded := Fold(g.log[:cut])
+	stats := NewStats()
+	Project(g.log[:cut], stats)
+	snapshot := DomainEvent{
+		Seq:      g.log[cut-1].Seq,
+		Type:     DomainGameRestored,
+		At:       g.log[cut-1].At,
+		Round:    g.log[cut-1].Round,
+		Snapshot: folded.Checkpoint().withoutAccounts(),
+		Stats:    stats,
+	}
+	g.log = append([]DomainEvent{snapshot}, g.log[cut:]...)
+}
+
+// Fold - The game log describes, rebuilt from nothing
+func Fold(log []DomainEvent) *Game {
+	g := NewGame(nil)
+	for _, event := range log {
+		g.apply(event)
+		g.seq = event.Seq
+	}
+	g.log = append([]DomainEvent{}, log...)
+
+	return g
+}
+
+// AtRound - The latest game as it stood once round had been played and scored,
+// round zero being just before the first number was drawn
+func AtRound(log []DomainEvent, round int) (*Game, error) {
+	start := gameStart(log)
+	if start < 0 || round < 0 {
+		return nil, ErrRoundNotPlayed
+	}
+
+	end := start + 1
+	for ; end < len(log); end++ {
+		event := log[end]
+		if event.Type == DomainNumberDrawn && event.Round > round {
+			break
+		}
+		if event.Type == DomainGameReset || event.Type == DomainGameStarted || event.Type == DomainGameRestored {
+			break
+		}
+	}
+	game := Fold(log[:end])
+	if game.Round != round {
+		return nil, ErrRoundNotPlayed
+	}
+
+	return game, nil
+}
+
+// gameStart - Index of the event the latest game starts from, -1 if none has.
+// A game restored mid play starts from the snapshot it was restored with.
+func gameStart(log []DomainEvent) int {
+	start := lastIndex(log, DomainGameStarted)
+	if restored := lastIndex(log, DomainGameRestored); restored > start {
+		start = restored
+	}
+
+	return start
+}
+
+// lastIndex - Index of the last event of eventType, -1 if there's none
+func lastIndex(log []DomainEvent, eventType DomainEventType) int {
+	for i := len(log) - 1; i >= 0; i-- {
+		if log[i].Type == eventType {
+			return i
+		}
+	}
+
+	return -1
+}
@@ -0,0 +1,136 @@
+package game
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// playedGame - A game played to the end and cleared, with Steve staying seated for the next.
+// Returns the leader board after each round.
+func playedGame(game *Game) []RoundResult {
+	game.RegisterPlayer(&Player{Name: "Steve", First: 3, Second: 7, StaySeated: true})
+	game.RegisterPlayer(&Player{Name: "Sarah", First: 4, Second: 8})
+	game.RegisterPlayer(&Player{Name: "Simon", First: 2, Second: 9})
+	game.AddWaitingPlayersToGame()
+	game.GetReady()
+	game.Start()
+
+	rounds := []RoundResult{game.GetRoundResult()}
+	for game.GetState() != GameStateCompleted {
+		game.PlayRound()
+		rounds = append(rounds, game.GetRoundResult())
+	}
+	game.NominateWinner()
+	game.Intermission()
+	game.Reset()
+
+	return rounds
+}
+
+func TestFold(t *testing.T) {
+	assert := assert.New(t)
+
+	game := NewGame(NewSeededRNG(MaxNum, 42))
+	game.Clock = NewFakeClock(time.Now())
+	assert.Nil(game.Configure(&RoomSettings{Condition: "last-standing"}))
+	playedGame(game)
+	game.RegisterPlayer(&Player{Name: "Sue", First: 1, Second: 5})
+
+	log := game.Log()
+	assert.Equal(DomainSettingsChanged, log[0].Type)
+	for i, event := range log {
+		assert.Equal(i+1, event.Seq)
+	}
+
+	folded := Fold(log)
+	assert.Equal(game.Checkpoint(), folded.Checkpoint())
+	assert.Equal(ErrInvalidPlayerName, folded.CheckPlayerExists("Sue"))
+}
+
+func TestAtRound(t *testing.T) {
+	assert := assert.New(t)
+
+	game := NewGame(NewSeededRNG(MaxNum, 12))
+	game.Clock = NewFakeClock(time.Now())
+	rounds := playedGame(game)
+	log := game.Log()
+
+	for round, result := range rounds {
+		past, err := AtRound(log, round)
+		assert.Nil(err)
+		assert.Equal(round, past.Round)
+		assert.Len(past.Players, len(result.LeaderBoard))
+		for _, player := range result.LeaderBoard {
+			assert.Equal(player.Score, past.Players[player.Name].Score)
+		}
+	}
+	_, err := AtRound(log, len(rounds))
+	assert.Equal(ErrRoundNotPlayed, err)
+	_, err = AtRound(nil, 0)
+	assert.Equal(ErrRoundNotPlayed, err)
+}
+
+func TestProjections(t *testing.T) {
+	assert := assert.New(t)
+
+	game := NewGame(NewSeededRNG(MaxNum, 7))
+	game.Clock = NewFakeClock(time.Now())
+	played := len(playedGame(game)) - 1
+	played += len(playedGame(game)) - 1
+
+	leaderboard := NewLeaderboard()
+	stats := NewStats()
+	Project(game.Log(), leaderboard, stats)
+
+	// Only Steve stayed seated once the last game was cleared
+	assert.Len(leaderboard.Players, 1)
+	assert.Equal("Steve", leaderboard.Players[0].Name)
+	assert.Equal(0, leaderboard.Players[0].Score)
+
+	assert.Equal(2, stats.Games)
+	assert.Equal(played, stats.Rounds)
+	draws := 0
+	for _, count := range stats.Draws {
+		draws += count
+	}
+	assert.Equal(stats.Rounds, draws)
+	assert.Equal(2, stats.Players["Steve"].Games)
+	wins := 0
+	for _, player := range stats.Players {
+		wins += player.Wins
+	}
+	assert.Equal(2, wins)
+}
+
+func TestLogCompaction(t *testing.T) {
+	assert := assert.New(t)
+
+	game := NewGame(NewSeededRNG(MaxNum, 1))
+	game.Clock = NewFakeClock(time.Now())
+	games, rounds := 0, 0
+	for len(game.log) < MaxLogSize {
+		rounds += len(playedGame(game)) - 1
+		games++
+	}
+	rounds += len(playedGame(game)) - 1
+	games++
+
+	log := game.Log()
+	assert.Equal(DomainGameRestored, log[0].Type)
+	assert.LessOrEqual(len(log), MaxLogSize)
+	assert.Equal(game.seq, log[len(log)-1].Seq)
+	assert.Equal(game.Checkpoint(), Fold(log).Checkpoint())
+
+	// Stats still count the games compacted away
+	stats := NewStats()
+	Project(log, stats)
+	assert.Equal(games, stats.Games)
+	assert.Equal(rounds, stats.Rounds)
+	assert.Equal(games, stats.Players["Steve"].Games)
+	// and projecting again counts the same
+	again := NewStats()
+	Project(log, again)
+	assert.Equal(stats, again)
+}
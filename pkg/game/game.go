@@ -1,10 +1,14 @@
 package game
 
 import (
-	"errors"
 	"fmt"
 	"math"
+	"net/http"
 	"sort"
+	"time"
+
+	"networkgaming.co.uk/techtest/pkg/names"
+	"networkgaming.co.uk/techtest/pkg/problem"
 )
 
 type State int
@@ -26,20 +30,22 @@ const (
 	OutOfBoundsScore  = -1
 
 	// Game States
-	GameStateReady      State = 1
-	GameStateInProgress State = 2
-	GameStateCompleted  State = 3
-	GameStateWaiting    State = 4
-	GameStateCancelled  State = 5
+	GameStateReady        State = 1
+	GameStateInProgress   State = 2
+	GameStateCompleted    State = 3
+	GameStateWaiting      State = 4
+	GameStateCancelled    State = 5
+	GameStateIntermission State = 6
 )
 
 var (
-	ErrInvalidNumber     = fmt.Errorf("Invalid number: Choose a number between %d - %d", MinNum, MaxNum)
-	ErrInvalidPlayerName = fmt.Errorf("Invalid name: There is already a player here with that name")
-	ErrNotEnoughPlayers  = errors.New("Invalid action: Not enough players in the game")
-	ErrGameInProgress    = errors.New("Invalid action: Game is in progress")
-	ErrGameComplete      = errors.New("Invalid action: Game in complete")
-	ErrNoSingleWinner    = errors.New("Invalid state: Not single winner nominated")
+	ErrInvalidNumber     = problem.New("invalid_number", http.StatusUnprocessableEntity, fmt.Sprintf("Invalid number: Choose a number between %d - %d", MinNum, MaxNum))
+	ErrInvalidPlayerName = problem.New("name_taken", http.StatusConflict, "Invalid name: There is already a player here with that name")
+	ErrNotEnoughPlayers  = problem.New("not_enough_players", http.StatusConflict, "Invalid action: Not enough players in the game")
+	ErrGameInProgress    = problem.New("game_in_progress", http.StatusConflict, "Invalid action: Game is in progress")
+	ErrGameComplete      = problem.New("game_complete", http.StatusConflict, "Invalid action: Game in complete")
+	ErrGameNotComplete   = problem.New("game_not_complete", http.StatusConflict, "Invalid action: Game isn't complete yet")
+	ErrNoSingleWinner    = problem.New("no_single_winner", http.StatusInternalServerError, "Invalid state: Not single winner nominated")
 )
 
 type GameI interface {
@@ -48,33 +54,79 @@ type GameI interface {
 	PlayRound() error
 	UpdatePlayerScores(number int)
 	NominateWinner() (GamePlayer, error)
+	Intermission() error
 	Reset() error
+	ExpirePlayers(before time.Time) []GamePlayer
 	RegisterPlayer(player *Player) error
+	ValidatePlayer(player *Player) error
+	AdjustPlayer(player *Player) error
+	Configure(settings *RoomSettings) error
+	RemovePlayer(name string) error
+	RegisterBot(name string, strategy BotStrategy) error
+	GetHistory() []int
 	CheckPlayerExists(name string) error
 	GetState() State
 	Cancel() error
 	AddWaitingPlayersToGame() ([]*GamePlayer, error)
 	GetRoundResult() RoundResult
+	Waitlist() []WaitlistEntry
+	Vacant() bool
+	TakeEvents() []*Event
+	Log() []DomainEvent
+	Checkpoint() *GameCheckpoint
+	Restore(checkpoint *GameCheckpoint) error
 }
 
 type GamePlayer struct {
-	Name   string `json:"name"`
-	Upper  int    `json:"upper"`
-	Lower  int    `json:"lower"`
-	Score  int    `json:"score"`
-	Winner bool   `json:"winner"`
+	Name       string    `json:"name"`
+	Upper      int       `json:"upper"`
+	Lower      int       `json:"lower"`
+	Picks      []int     `json:"picks"`
+	Score      int       `json:"score"`
+	Streak     int       `json:"streak"`
+	Bust       bool      `json:"bust"`
+	Eliminated bool      `json:"eliminated"`
+	Winner     bool      `json:"winner"`
+	Bot        bool      `json:"bot"`
+	StaySeated bool      `json:"stay_seated"`
+	JoinedAt   time.Time `json:"joined_at"`
+	Account    string    `json:"-"`
+}
+
+// IsOut - Bust or eliminated players take no further part in the game
+func (gp GamePlayer) IsOut() bool {
+	return gp.Bust || gp.Eliminated
 }
 
 type Game struct {
-	Players     map[string]GamePlayer `json:"players"`
-	Round       int                   `json:"round"`
-	Numbers     [MaxRounds]int        `json:"numbers"`
-	Rand        NumberGenerator       `json:"-"`
-	TopScore    int                   `json:"top_score"`
-	Winner      GamePlayer            `json:"winner"`
-	state       State
-	registered  map[string]GamePlayer
-	waitingRoom []*GamePlayer
+	Players        map[string]GamePlayer `json:"players"`
+	Round          int                   `json:"round"`
+	Numbers        []int                 `json:"numbers"`
+	Rand           NumberGenerator       `json:"-"`
+	Scoring        ScoringStrategy       `json:"-"`
+	Condition      WinCondition          `json:"-"`
+	Names          *names.Policy         `json:"-"`
+	Clock          Clock                 `json:"-"`
+	PicksPerPlayer int                   `json:"picks_per_player"`
+	Seats          int                   `json:"seats"`
+	WaitlistSize   int                   `json:"waitlist_size"`
+	AllowAdjust    bool                  `json:"allow_adjust"`
+	AdjustPenalty  int                   `json:"adjust_penalty"`
+	TopScore       int                   `json:"top_score"`
+	Winner         GamePlayer            `json:"winner"`
+	Rogue          bool                  `json:"rogue"` // Winner hit BlackJack outright, rather than being nominated
+	StartedAt      time.Time             `json:"started_at"`
+	SuddenDeath    bool                  `json:"sudden_death"`
+	suddenDeathAt  int                   // the round sudden death began after
+	scoring        string                // configured by name, for checkpoints
+	condition      string
+	state          State
+	registered     map[string]GamePlayer // by names.Skeleton
+	waitingRoom    []*GamePlayer
+	events         []*Event
+	history        []int
+	log            []DomainEvent
+	seq            int
 }
 
 // RoundResult - Sorted leader board for API
@@ -89,15 +141,24 @@ func NewGame(rand NumberGenerator) *Game {
 	waitingRoom := make([]*GamePlayer, 0)
 
 	return &Game{
-		Players:     players,
-		Round:       0,
-		Numbers:     [MaxRounds]int{},
-		Rand:        rand,
-		TopScore:    math.MinInt8,
-		Winner:      GamePlayer{},
-		state:       GameStateWaiting,
-		registered:  registered,
-		waitingRoom: waitingRoom,
+		Players:        players,
+		Round:          0,
+		Numbers:        make([]int, 0, MaxRounds),
+		Rand:           rand,
+		Scoring:        NewClassicScoring(),
+		Condition:      NewMaxRoundsCondition(MaxRounds),
+		Names:          names.DefaultPolicy(),
+		PicksPerPlayer: DefaultPicksPerPlayer,
+		Seats:          DefaultSeats,
+		WaitlistSize:   DefaultWaitlistSize,
+		AllowAdjust:    false,
+		AdjustPenalty:  AdjustPenaltyScore,
+		TopScore:       math.MinInt8,
+		Clock:          RealClock{},
+		Winner:         GamePlayer{},
+		state:          GameStateWaiting,
+		registered:     registered,
+		waitingRoom:    waitingRoom,
 	}
 }
 
@@ -108,7 +169,7 @@ func (g *Game) GetReady() error {
 	}
 
 	if len(g.Players) >= MinPlayersRequired {
-		g.state = GameStateReady
+		return g.transition(GameStateReady)
 	}
 
 	return nil
@@ -123,65 +184,90 @@ func (g *Game) Start() error {
 	if len(g.Players) < MinPlayersRequired {
 		return ErrNotEnoughPlayers
 	}
+	if err := g.check(GameStateInProgress); err != nil {
+		return err
+	}
 
-	g.state = GameStateInProgress
-	g.Round = 0
+	g.record(DomainEvent{Type: DomainGameStarted})
 
 	return nil
 }
 
 func (g *Game) PlayRound() error {
 
-	if g.Round >= MaxRounds {
+	if g.state == GameStateCompleted {
 		return ErrGameComplete
 	}
+	if g.state != GameStateInProgress {
+		return ErrGameNotInProgress
+	}
 
+	round := g.Round
 	roundNumber := g.Rand.GetInt()
-	// Update Scores and Leader Board
-	g.UpdatePlayerScores(roundNumber)
-
-	g.Numbers[g.Round] = roundNumber
-	g.Round++
-
-	if g.Round >= MaxRounds {
-		g.state = GameStateCompleted
+	g.record(DomainEvent{Type: DomainNumberDrawn, Number: roundNumber})
+	// Update Scores and Leader Board, scored as the round it was drawn for
+	g.score(roundNumber, round)
+
+	// A rogue win may already have ended it
+	if g.state != GameStateCompleted {
+		over, events := g.Condition.AfterRound(g)
+		g.events = append(g.events, events...)
+		if over {
+			return g.transition(GameStateCompleted)
+		}
 	}
 
 	return nil
 }
 
 func (g *Game) UpdatePlayerScores(number int) {
-	// set top to a low value
-	topScore := math.MinInt8
-	// Loop through players in game
-	for name, player := range g.Players {
-		if player.Upper == number || player.Lower == number {
-			player.Score += ExactMatchScore
-			// fmt.Printf("Player %s ExactMatch. New Score[%d]\n", name, player.Score)
-		} else if number <= player.Upper && number >= player.Lower {
-			player.Score += InsideBoundsScore - (player.Upper - player.Lower)
-			// fmt.Printf("Player %s InsideBoundsScore. New Score[%d]\n", name, player.Score)
+	g.score(number, g.Round)
+}
+
+// score - Score number for every player still in the running, as drawn in round
+func (g *Game) score(number int, round int) {
+	over := false
+	// Loop through players in game, in name order so a shared rogue win
+	// goes to the first alphabetically as NominateWinner would have it
+	names := make([]string, 0, len(g.Players))
+	for name := range g.Players {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		player := g.Players[name]
+		// Bust and eliminated players are out of the running
+		if player.IsOut() {
+			continue
+		}
+		points := g.Scoring.Points(player, number, round)
+		player.Score += points
+		if points > 0 {
+			player.Streak++
 		} else {
-			player.Score += OutOfBoundsScore
-			// fmt.Printf("Player %s OutOfBoundsScore. New Score[%d]\n", name, player.Score)
+			player.Streak = 0
 		}
-		// Update topScore
-		if player.Score > topScore {
-			topScore = player.Score
+		rogue := false
+		switch g.Scoring.Check(player) {
+		case OutcomeWin:
+			// Rogue win case
+			rogue = g.Winner.Name == ""
+			player.Winner = rogue
+			over = true
+		case OutcomeBust:
+			player.Bust = true
 		}
-		// Check for rogue win case
-		if player.Score == BlackJack {
-			player.Winner = true
-			g.Winner = player
-			g.state = GameStateCompleted
+		g.update(player)
+		if rogue {
+			g.record(DomainEvent{Type: DomainWinnerNominated, Player: &player, Rogue: true})
 		}
-		// Write updates back to the map!
-		g.Players[name] = player
-		g.TopScore = topScore
+	}
+	// Everyone's out, the least bad player can still take it
+	if over || (len(g.Players) > 0 && len(g.standing()) == 0) {
+		g.transition(GameStateCompleted)
 	}
 }
 
-// TODO: This fails if players draw and their scores are negative! Fix it!
 func (g *Game) NominateWinner() (GamePlayer, error) {
 	// Check for BlackJack winner
 	if g.Winner.Name != "" {
@@ -192,36 +278,35 @@ func (g *Game) NominateWinner() (GamePlayer, error) {
 	winners := []GamePlayer{}
 	bigUp := 0
 	bigLow := 0
-	// Find players with top score and capture highest upper/lower bounds
+	// Players who are out can only win if nobody is left standing
+	allOut := len(g.standing()) == 0
+	// Find players with top score and capture highest upper bound
 	for _, player := range g.Players {
-		if player.Score == g.TopScore {
+		if player.Score == g.TopScore && (allOut || !player.IsOut()) {
 			winners = append(winners, player)
 			if player.Upper > bigUp {
 				bigUp = player.Upper
 			}
-			if player.Lower > bigLow {
-				bigLow = player.Lower
-			}
 		}
 	}
 	// Check for one winner
 	if len(winners) == 1 {
-		winners[0].Winner = true
-		g.Players[winners[0].Name] = winners[0]
-		return winners[0], nil
+		return g.nominate(winners[0]), nil
 	}
 	// Keep winners with highest upper bound
 	bigUpWinners := []GamePlayer{}
 	for _, player := range winners {
 		if player.Upper == bigUp {
 			bigUpWinners = append(bigUpWinners, player)
+			// Capture the highest lower bound of those left
+			if player.Lower > bigLow {
+				bigLow = player.Lower
+			}
 		}
 	}
 	// Check is there one winner
 	if len(bigUpWinners) == 1 {
-		bigUpWinners[0].Winner = true
-		g.Players[bigUpWinners[0].Name] = bigUpWinners[0]
-		return bigUpWinners[0], nil
+		return g.nominate(bigUpWinners[0]), nil
 	}
 
 	// Keep winners with highest lower bound
@@ -236,60 +321,75 @@ func (g *Game) NominateWinner() (GamePlayer, error) {
 	}
 	// Check is there one winner
 	if len(bigLowWinners) == 1 {
-		bigLowWinners[0].Winner = true
-		g.Players[bigLowWinners[0].Name] = bigLowWinners[0]
-		return bigLowWinners[0], nil
+		return g.nominate(bigLowWinners[0]), nil
 	}
 
 	// Return the player who's first in alphabetical order
 	sort.Strings(names)
 	for _, player := range bigLowWinners {
 		if player.Name == names[0] {
-			player.Winner = true
-			g.Players[names[0]] = player
-			return player, nil
+			return g.nominate(player), nil
 		}
 	}
 
 	return winner, ErrNoSingleWinner
 }
 
+// Reset - Clear the table for the next game. Players who asked to stay seated
+// carry over, everyone else is released so their name can be used again.
+// After a cancelled game everyone has had their stake back, so nobody carries over.
 func (g *Game) Reset() error {
-	g.Round = 0
-	g.state = GameStateWaiting
-	g.Winner = GamePlayer{}
-	for k, player := range g.Players {
-		player.Score = 0
-		g.Players[k] = player
+	if err := g.check(GameStateWaiting); err != nil {
+		return err
+	}
+	cancelled := g.state == GameStateCancelled
+	seated := make([]GamePlayer, 0, len(g.Players))
+	for _, player := range g.Players {
+		seated = append(seated, player)
+	}
+	left := []GamePlayer{}
+	for _, player := range byName(seated) {
+		if cancelled || !player.StaySeated {
+			left = append(left, player)
+		}
+	}
+	if cancelled {
+		for _, player := range g.waitingRoom {
+			left = append(left, *player)
+		}
+	}
+	// Reset before anyone leaves, so the log has the game as it ended up to the reset
+	g.record(DomainEvent{Type: DomainGameReset})
+	for _, player := range left {
+		g.release(player.Name)
+	}
+	if len(left) > 0 {
+		g.events = append(g.events, NewEvent(PlayerLeft, byName(left)))
 	}
 	// Get players from the waiting room
 	return nil
 }
 
+// RegisterPlayer - Add a player to the waiting room, player.Name is replaced with its normalised form
 func (g *Game) RegisterPlayer(player *Player) error {
 
-	// Check name not in use
-	if err := g.CheckPlayerExists(player.Name); err != nil {
+	// Check the name is allowed and not in use
+	if err := g.checkName(player); err != nil {
 		return err
 	}
 	// Check choices
-	if err := g.validateChoice(player.First); err != nil {
+	gp, err := g.choose(player)
+	if err != nil {
 		return err
 	}
-	if err := g.validateChoice(player.Second); err != nil {
+	if err := g.checkSeats(); err != nil {
 		return err
 	}
+	gp.Bot = player.Bot
+	gp.StaySeated = player.StaySeated
+	gp.Account = player.Account
 
-	gp := GamePlayer{Name: player.Name}
-	if player.First >= player.Second {
-		gp.Upper = player.First
-		gp.Lower = player.Second
-	} else {
-		gp.Upper = player.Second
-		gp.Lower = player.First
-	}
-	g.registered[player.Name] = gp
-	g.waitingRoom = append(g.waitingRoom, &gp)
+	g.record(DomainEvent{Type: DomainPlayerRegistered, Player: &gp})
 
 	return nil
 }
@@ -302,12 +402,13 @@ func (g *Game) AddWaitingPlayersToGame() ([]*GamePlayer, error) {
 		return emptyWaitingRoom, ErrGameInProgress
 	}
 
-	waiting := g.waitingRoom
-	g.waitingRoom = emptyWaitingRoom
+	// Anyone who doesn't fit stays on the waitlist
+	free := g.free(len(g.waitingRoom))
+	waiting := append(emptyWaitingRoom, g.waitingRoom[:free]...)
 
-	// TODO: Check if the name already exists here and return an error
 	for _, waitingPlayer := range waiting {
-		g.Players[waitingPlayer.Name] = *waitingPlayer
+		waitingPlayer.JoinedAt = g.Clock.Now()
+		g.record(DomainEvent{Type: DomainPlayerSeated, Player: waitingPlayer})
 	}
 
 	return waiting, nil
@@ -330,12 +431,21 @@ func (g *Game) GetRoundResult() RoundResult {
 	}
 }
 
+// TakeEvents - Hands over events raised while playing rounds, for the engine to broadcast
+func (g *Game) TakeEvents() []*Event {
+	events := g.events
+	g.events = nil
+
+	return events
+}
+
 func (g *Game) GetState() State {
 	return g.state
 }
 
+// CheckPlayerExists - Names too alike to tell apart count as the same
 func (g *Game) CheckPlayerExists(name string) error {
-	_, exists := g.registered[name]
+	_, exists := g.registered[names.Skeleton(name)]
 	if exists {
 		return ErrInvalidPlayerName
 	}
@@ -343,10 +453,22 @@ func (g *Game) CheckPlayerExists(name string) error {
 	return nil
 }
 
-func (g *Game) Cancel() error {
-	g.state = GameStateCancelled
+// checkName - Normalise a player's name under the game's policy and check it's free.
+// Bots are named by the engine so only need to be free.
+func (g *Game) checkName(player *Player) error {
+	if !player.Bot && g.Names != nil {
+		name, err := g.Names.Normalize(player.Name)
+		if err != nil {
+			return err
+		}
+		player.Name = name
+	}
 
-	return nil
+	return g.CheckPlayerExists(player.Name)
+}
+
+func (g *Game) Cancel() error {
+	return g.transition(GameStateCancelled)
 }
 
 func (g *Game) validateChoice(i int) error {
@@ -356,3 +478,54 @@ func (g *Game) validateChoice(i int) error {
 
 	return nil
 }
+
+// standing - Players still in the running
+func (g *Game) standing() []GamePlayer {
+	standing := []GamePlayer{}
+	for _, player := range g.Players {
+		if !player.IsOut() {
+			standing = append(standing, player)
+		}
+	}
+
+	return standing
+}
+
+// leaders - Players still in the running on the top score
+func (g *Game) leaders() []GamePlayer {
+	leaders := []GamePlayer{}
+	for _, player := range g.standing() {
+		if player.Score == g.TopScore {
+			leaders = append(leaders, player)
+		}
+	}
+
+	return leaders
+}
+
+// updateTopScore - Top score of the players still in the running,
+// or of everyone if nobody is left standing
+func (g *Game) updateTopScore() {
+	// set top to a low value
+	topScore := math.MinInt8
+	players := g.standing()
+	if len(players) == 0 {
+		for _, player := range g.Players {
+			players = append(players, player)
+		}
+	}
+	for _, player := range players {
+		if player.Score > topScore {
+			topScore = player.Score
+		}
+	}
+	g.TopScore = topScore
+}
+
+// nominate - Settle the game on player outright
+func (g *Game) nominate(player GamePlayer) GamePlayer {
+	player.Winner = true
+	g.record(DomainEvent{Type: DomainWinnerNominated, Player: &player})
+
+	return player
+}
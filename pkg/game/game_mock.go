@@ -1,9 +1,11 @@
 package game
 
+import "time"
+
 type MockGame struct {
 	Players                  map[string]GamePlayer `json:"players"`
 	Round                    int                   `json:"round"`
-	Numbers                  [MaxRounds]int        `json:"numbers"`
+	Numbers                  []int                 `json:"numbers"`
 	Rand                     NumberGenerator       `json:"-"`
 	TopScore                 int                   `json:"top_score"`
 	State                    State                 `json:"state"`
@@ -47,14 +49,55 @@ func (gm *MockGame) NominateWinner() (GamePlayer, error) {
 	return GamePlayer{}, nil
 }
 
+func (gm *MockGame) Intermission() error {
+	gm.State = GameStateIntermission
+	return nil
+}
+
 func (gm *MockGame) Reset() error {
 	return nil
 }
 
+func (gm *MockGame) Waitlist() []WaitlistEntry {
+	return nil
+}
+
+func (gm *MockGame) Vacant() bool {
+	return false
+}
+
+func (gm *MockGame) ExpirePlayers(before time.Time) []GamePlayer {
+	return nil
+}
+
 func (gm *MockGame) RegisterPlayer(player *Player) error {
 	return nil
 }
 
+func (gm *MockGame) ValidatePlayer(player *Player) error {
+	return nil
+}
+
+func (gm *MockGame) AdjustPlayer(player *Player) error {
+	return nil
+}
+
+func (gm *MockGame) Configure(settings *RoomSettings) error {
+	return nil
+}
+
+func (gm *MockGame) RemovePlayer(name string) error {
+	return nil
+}
+
+func (gm *MockGame) RegisterBot(name string, strategy BotStrategy) error {
+	return nil
+}
+
+func (gm *MockGame) GetHistory() []int {
+	return nil
+}
+
 func (gm *MockGame) CheckPlayerExists(name string) error {
 	return nil
 }
@@ -71,6 +114,24 @@ func (gm *MockGame) AddWaitingPlayersToGame() ([]*GamePlayer, error) {
 	return make([]*GamePlayer, 0), nil
 }
 
+func (gm *MockGame) TakeEvents() []*Event {
+	return nil
+}
+
+func (gm *MockGame) Log() []DomainEvent {
+	return nil
+}
+
+func (gm *MockGame) Checkpoint() *GameCheckpoint {
+	return &GameCheckpoint{Round: gm.Round, State: gm.State}
+}
+
+func (gm *MockGame) Restore(checkpoint *GameCheckpoint) error {
+	gm.Round = checkpoint.Round
+	gm.State = checkpoint.State
+	return nil
+}
+
 func (gm MockGame) GetRoundResult() RoundResult {
-	return RoundResult{}
+	return RoundResult{Round: gm.Round}
 }
@@ -4,6 +4,7 @@ import (
 	"testing"
 
 	"github.com/stretchr/testify/assert"
+	"networkgaming.co.uk/techtest/pkg/names"
 )
 
 func TestGame(t *testing.T) {
@@ -43,19 +44,33 @@ func TestAddingPlayer(t *testing.T) {
 	game := NewGame(rand)
 
 	// New player
-	err := game.RegisterPlayer(&Player{"Steve", 5, 8})
+	err := game.RegisterPlayer(&Player{Name: "Steve", First: 5, Second: 8})
 	assert.Nil(err)
 
 	// Same player
-	err = game.RegisterPlayer(&Player{"Steve", 5, 8})
+	err = game.RegisterPlayer(&Player{Name: "Steve", First: 5, Second: 8})
 	assert.Equal(ErrInvalidPlayerName, err)
 
+	// Looks the same, Cyrillic e
+	err = game.RegisterPlayer(&Player{Name: "Stеve", First: 5, Second: 8})
+	assert.Equal(ErrInvalidPlayerName, err)
+
+	// Not a name at all
+	err = game.RegisterPlayer(&Player{Name: "<b>Steve</b>", First: 5, Second: 8})
+	assert.Equal(names.ErrNameCharacters, err)
+
+	// Tidied up before it's used
+	player := &Player{Name: "  Sam   Smith ", First: 5, Second: 8}
+	err = game.RegisterPlayer(player)
+	assert.Nil(err)
+	assert.Equal("Sam Smith", player.Name)
+
 	// New player but bad first number
-	err = game.RegisterPlayer(&Player{"Sarah", 15, 8})
+	err = game.RegisterPlayer(&Player{Name: "Sarah", First: 15, Second: 8})
 	assert.Equal(err, ErrInvalidNumber)
 
 	// New player but bad second number
-	err = game.RegisterPlayer(&Player{"Sarah", 5, 84})
+	err = game.RegisterPlayer(&Player{Name: "Sarah", First: 5, Second: 84})
 	assert.Equal(err, ErrInvalidNumber)
 
 }
@@ -63,10 +78,14 @@ func TestAddingPlayer(t *testing.T) {
 func TestRounds(t *testing.T) {
 	assert := assert.New(t)
 
-	rand := NewRNG(MaxNum)
+	// Seeded so nobody hits BlackJack for a rogue win before MaxRounds
+	rand := NewSeededRNG(MaxNum, 4)
 	game := NewGame(rand)
-	game.RegisterPlayer(&Player{"Steve", 3, 9})
-	game.RegisterPlayer(&Player{"Sarah", 4, 2})
+	game.RegisterPlayer(&Player{Name: "Steve", First: 3, Second: 9})
+	game.RegisterPlayer(&Player{Name: "Sarah", First: 4, Second: 2})
+	game.AddWaitingPlayersToGame()
+	assert.Equal(ErrGameNotInProgress, game.PlayRound())
+	game.Start()
 
 	for i := 0; i < MaxRounds; i++ {
 		err := game.PlayRound()
@@ -75,7 +94,9 @@ func TestRounds(t *testing.T) {
 		}
 		assert.Equal(i+1, game.Round)
 	}
+	assert.False(game.Rogue)
 	assert.Equal(game.GetState(), GameStateCompleted)
+	assert.Equal(ErrGameComplete, game.PlayRound())
 }
 
 func TestVectorExampleGame(t *testing.T) {
@@ -85,10 +106,11 @@ func TestVectorExampleGame(t *testing.T) {
 
 	game := NewGame(gen)
 	// Example with 3 players
-	game.RegisterPlayer(&Player{"PlayerA", 3, 8})
-	game.RegisterPlayer(&Player{"PlayerB", 5, 7})
-	game.RegisterPlayer(&Player{"PlayerC", 3, 7})
+	game.RegisterPlayer(&Player{Name: "PlayerA", First: 3, Second: 8})
+	game.RegisterPlayer(&Player{Name: "PlayerB", First: 5, Second: 7})
+	game.RegisterPlayer(&Player{Name: "PlayerC", First: 3, Second: 7})
 	game.AddWaitingPlayersToGame()
+	game.Start()
 
 	// Round 1: -1 -1 -1
 	// Round 2: -2 -2 -2
@@ -139,10 +161,11 @@ func TestNominatingAlphabeticalWinner(t *testing.T) {
 
 	game := NewGame(gen)
 	// Example with 3 players
-	game.RegisterPlayer(&Player{"ZZZ", 3, 8})
-	game.RegisterPlayer(&Player{"BBB", 3, 8})
-	game.RegisterPlayer(&Player{"NNN", 3, 8})
+	game.RegisterPlayer(&Player{Name: "ZZZ", First: 3, Second: 8})
+	game.RegisterPlayer(&Player{Name: "BBB", First: 3, Second: 8})
+	game.RegisterPlayer(&Player{Name: "NNN", First: 3, Second: 8})
 	game.AddWaitingPlayersToGame()
+	game.Start()
 
 	game.PlayRound()
 	// Should have the same scores and bounds
@@ -158,10 +181,11 @@ func TestNominatingUpperBoundWinner(t *testing.T) {
 
 	game := NewGame(gen)
 	// Example with 3 players, highest upper bound playerA should win here with 8
-	game.RegisterPlayer(&Player{"PlayerA", 3, 8})
-	game.RegisterPlayer(&Player{"PlayerB", 5, 7})
-	game.RegisterPlayer(&Player{"PlayerC", 3, 7})
+	game.RegisterPlayer(&Player{Name: "PlayerA", First: 3, Second: 8})
+	game.RegisterPlayer(&Player{Name: "PlayerB", First: 5, Second: 7})
+	game.RegisterPlayer(&Player{Name: "PlayerC", First: 3, Second: 7})
 	game.AddWaitingPlayersToGame()
+	game.Start()
 
 	game.PlayRound()
 	// PlayerB should win on highest upper bound
@@ -176,13 +200,60 @@ func TestNominatingLowerBoundWinner(t *testing.T) {
 
 	game := NewGame(gen)
 	// Example with 3 players, highest lower bound playerB should win here with 5
-	game.RegisterPlayer(&Player{"PlayerA", 3, 7})
-	game.RegisterPlayer(&Player{"PlayerB", 5, 7})
-	game.RegisterPlayer(&Player{"PlayerC", 3, 7})
+	game.RegisterPlayer(&Player{Name: "PlayerA", First: 3, Second: 7})
+	game.RegisterPlayer(&Player{Name: "PlayerB", First: 5, Second: 7})
+	game.RegisterPlayer(&Player{Name: "PlayerC", First: 3, Second: 7})
 	game.AddWaitingPlayersToGame()
+	game.Start()
 
 	game.PlayRound()
 	// PlayerB should win on highest upper bound
 	winner, _ := game.NominateWinner()
 	assert.Equal(winner.Name, "PlayerB")
 }
+
+func TestNominatingLowerBoundWinnerAfterUpperBoundTie(t *testing.T) {
+	assert := assert.New(t)
+	seq := []int{1}
+	gen := NewSSNG(seq)
+
+	game := NewGame(gen)
+	// All on the same score, PlayerC has the highest lower bound but not the highest upper
+	game.RegisterPlayer(&Player{Name: "PlayerA", First: 3, Second: 9})
+	game.RegisterPlayer(&Player{Name: "PlayerB", First: 4, Second: 9})
+	game.RegisterPlayer(&Player{Name: "PlayerC", First: 6, Second: 8})
+	game.AddWaitingPlayersToGame()
+	game.Start()
+
+	game.PlayRound()
+	winner, err := game.NominateWinner()
+	assert.Nil(err)
+	assert.Equal("PlayerB", winner.Name)
+}
+
+func TestRogueWinner(t *testing.T) {
+	assert := assert.New(t)
+	seq := []int{3, 3, 3, 3, 5}
+	gen := NewSSNG(seq)
+
+	game := NewGame(gen)
+	game.RegisterPlayer(&Player{Name: "Steve", First: 3, Second: 7})
+	game.RegisterPlayer(&Player{Name: "Sarah", First: 1, Second: 2})
+	game.AddWaitingPlayersToGame()
+	game.Start()
+
+	// 5 + 5 + 5 + 5 + 1 is exactly BlackJack
+	for i := 0; i < 5; i++ {
+		assert.Nil(game.PlayRound())
+	}
+	assert.Equal(GameStateCompleted, game.GetState())
+	assert.Equal("Steve", game.Winner.Name)
+	assert.True(game.Rogue)
+
+	// Carried over a checkpoint, and cleared for the next game
+	restored := NewGame(gen)
+	assert.Nil(restored.Restore(game.Checkpoint()))
+	assert.True(restored.Rogue)
+	assert.Nil(game.Reset())
+	assert.False(game.Rogue)
+}
@@ -1,30 +1,55 @@
 package game
 
 import (
+	"context"
 	"encoding/json"
+	"fmt"
 	"log"
 	"net/http"
+
+	"networkgaming.co.uk/techtest/pkg/problem"
+)
+
+const (
+	// MaxJoinBodySize - Far more than any join needs
+	MaxJoinBodySize = 4 << 10
 )
 
+// JoinGameHandler - Joins the main room through Lobby when it's set, spilling over
+// into another room when it's full, otherwise straight through the engine
+// Accounts is who players are staking as, they're sent their wallet token as the bearer token.
 type JoinGameHandler struct {
-	actionChannel chan *Action
+	Lobby    *Lobby
+	Accounts Accounts
+	engine   *Engine
 }
 
-func NewJoinGameHandler(actionChannel chan *Action) *JoinGameHandler {
-	return &JoinGameHandler{actionChannel}
+func NewJoinGameHandler(engine *Engine) *JoinGameHandler {
+	return &JoinGameHandler{engine: engine}
 }
 
+// JoinGameRequest - StaySeated keeps the player at the table for the next game
 type JoinGameRequest struct {
-	Name   string `json:"name"`
-	First  int    `json:"first"`
-	Second int    `json:"second"`
+	Name       string `json:"name"`
+	First      int    `json:"first"`
+	Second     int    `json:"second"`
+	Picks      []int  `json:"picks,omitempty"`
+	Range      *Range `json:"range,omitempty"`
+	StaySeated bool   `json:"stay_seated,omitempty"`
 }
 
+// JoinGameResponse - Room and Position are only set when joining,
+// Position is the place on the waitlist if every seat is taken and ChatToken
+// is the player's token to subscribe with to chat in Room.
+// Failures are problem details instead, see the problem package.
 type JoinGameResponse struct {
-	Status int    `json:"status"`
-	Type   string `json:"type"`
-	Title  string `json:"title"`
-	Detail string `json:"detail"`
+	Status    int    `json:"status"`
+	Type      string `json:"type"`
+	Title     string `json:"title"`
+	Detail    string `json:"detail"`
+	Room      string `json:"room,omitempty"`
+	Position  int    `json:"position,omitempty"`
+	ChatToken string `json:"chat_token,omitempty"`
 }
 
 func (h *JoinGameHandler) JoinGame(w http.ResponseWriter, r *http.Request) {
@@ -33,47 +58,135 @@ func (h *JoinGameHandler) JoinGame(w http.ResponseWriter, r *http.Request) {
 	w.Header().Set("Server", "NG: Small Browser Based Game Server")
 
 	request := new(JoinGameRequest)
+	r.Body = http.MaxBytesReader(w, r.Body, MaxJoinBodySize)
 	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
 		log.Printf("Join Game - Error decoding json %s", err.Error())
-		w.WriteHeader(http.StatusBadRequest)
-		errorResponse := JoinGameResponse{
-			Status: http.StatusBadRequest,
-			Type:   "Error",
-			Title:  "Invalid JSON",
-			Detail: err.Error(),
-		}
-		json.NewEncoder(w).Encode(errorResponse)
+		problem.Write(w, problem.InvalidJSON(err))
 		return
 	}
 
-	arc := make(chan *ActionResponse)
+	player := request.player()
+	account, err := Account(h.Accounts, r)
+	if err != nil {
+		problem.Write(w, err)
+		return
+	}
+	player.Account = account
 
 	log.Println("Join Game Request sending to Engine")
-	h.actionChannel <- &Action{
-		Type:   ActionTypeJoinGame,
-		Player: &Player{request.Name, request.First, request.Second},
-		Reply:  arc,
+	room, position, token, err := h.join(r.Context(), player)
+	if err != nil {
+		log.Printf("Join Game Error: %s", err.Error())
+		problem.Write(w, err)
+		return
+	}
+
+	response := JoinGameResponse{
+		Status:    http.StatusOK,
+		Type:      "Success",
+		Title:     "Joined Game",
+		Detail:    "Welcome to the game, player ;)",
+		Room:      room,
+		Position:  position,
+		ChatToken: token,
 	}
+	if position > 0 {
+		response.Title = "Joined Waitlist"
+		response.Detail = fmt.Sprintf("Every seat is taken, you're number %d in line for the next one", position)
+	}
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(response)
+	return
+}
 
-	ar := <-arc
-	if !ar.Success {
-		log.Printf("Join Game Error: %s", ar.Message)
-		w.WriteHeader(http.StatusBadRequest)
-		errorResponse := JoinGameResponse{
-			Status: http.StatusBadRequest,
-			Type:   "Error",
-			Title:  "Invalid Request",
-			Detail: ar.Message,
+// join - The room the player joined, their place on its waitlist and their chat token,
+// there's no chat without a lobby
+func (h *JoinGameHandler) join(ctx context.Context, player *Player) (string, int, string, error) {
+	if h.Lobby != nil {
+		room, position, err := h.Lobby.Place(ctx, MainRoom, player)
+		if err != nil {
+			return "", 0, "", err
+		}
+		token, err := room.Chat.Register(player.Name, player.Account)
+		if err != nil {
+			return "", 0, "", err
 		}
-		json.NewEncoder(w).Encode(errorResponse)
+		return room.ID, position, token, nil
+	}
+
+	ar, err := h.engine.Do(ctx, &Action{
+		Type:   ActionTypeJoinGame,
+		Player: player,
+	})
+	if err != nil {
+		return "", 0, "", err
+	}
+
+	return h.engine.Room, ar.Position, "", nil
+}
+
+func (r *JoinGameRequest) player() *Player {
+	return &Player{
+		Name:       r.Name,
+		First:      r.First,
+		Second:     r.Second,
+		Picks:      r.Picks,
+		Range:      r.Range,
+		StaySeated: r.StaySeated,
+	}
+}
+
+// AdjustGameHandler - Lets seated players change their picks mid game,
+// they're sent the chat token they got on joining as the bearer token
+type AdjustGameHandler struct {
+	lobby *Lobby
+}
+
+func NewAdjustGameHandler(lobby *Lobby) *AdjustGameHandler {
+	return &AdjustGameHandler{lobby}
+}
+
+// AdjustGameRequest - The same body as JoinGame plus the room joined, the main room if not set.
+// The name is taken from the token.
+type AdjustGameRequest struct {
+	JoinGameRequest
+	Room string `json:"room,omitempty"`
+}
+
+// AdjustGame - Applied between rounds for a score penalty
+func (h *AdjustGameHandler) AdjustGame(w http.ResponseWriter, r *http.Request) {
+
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Server", "NG: Small Browser Based Game Server")
+
+	request := new(AdjustGameRequest)
+	r.Body = http.MaxBytesReader(w, r.Body, MaxJoinBodySize)
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		log.Printf("Adjust Game - Error decoding json %s", err.Error())
+		problem.Write(w, problem.InvalidJSON(err))
+		return
+	}
+	if request.Room == "" {
+		request.Room = MainRoom
+	}
+
+	log.Println("Adjust Game Request sending to Engine")
+	room, err := h.lobby.Room(request.Room)
+	if err == nil {
+		err = room.Adjust(r.Context(), bearer(r), request.player())
+	}
+	if err != nil {
+		log.Printf("Adjust Game Error: %s", err.Error())
+		problem.Write(w, err)
 		return
 	}
 
 	response := JoinGameResponse{
 		Status: http.StatusOK,
 		Type:   "Success",
-		Title:  "Joined Game",
-		Detail: "Welcome to the game, player ;)",
+		Title:  "Adjusted Picks",
+		Detail: "Bounds updated, that'll cost you",
+		Room:   room.ID,
 	}
 	w.WriteHeader(http.StatusOK)
 	json.NewEncoder(w).Encode(response)
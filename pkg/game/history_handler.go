@@ -0,0 +1,91 @@
+package game
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+
+	"github.com/go-chi/chi"
+)
+
+// HistoryHandler - Reads of a room's domain event log, folded and projected off the engine.
+// The raw log is for the room's host or an admin.
+type HistoryHandler struct {
+	lobby *Lobby
+	admin *AdminHandler
+}
+
+func NewHistoryHandler(lobby *Lobby, admin *AdminHandler) *HistoryHandler {
+	return &HistoryHandler{lobby, admin}
+}
+
+// Log - GET /history/{room}/log
+func (h *HistoryHandler) Log(w http.ResponseWriter, r *http.Request) {
+	h.read(w, r, true, func(log []DomainEvent) (interface{}, error) {
+		return log, nil
+	})
+}
+
+// Round - GET /history/{room}/rounds/{round}, the latest game's leader board as it stood after round
+func (h *HistoryHandler) Round(w http.ResponseWriter, r *http.Request) {
+	h.read(w, r, false, func(log []DomainEvent) (interface{}, error) {
+		round, err := strconv.Atoi(chi.URLParam(r, "round"))
+		if err != nil {
+			return nil, ErrRoundNotPlayed
+		}
+		game, err := AtRound(log, round)
+		if err != nil {
+			return nil, err
+		}
+		return game.GetRoundResult(), nil
+	})
+}
+
+// Leaderboard - GET /history/{room}/leaderboard
+func (h *HistoryHandler) Leaderboard(w http.ResponseWriter, r *http.Request) {
+	h.read(w, r, false, func(log []DomainEvent) (interface{}, error) {
+		leaderboard := NewLeaderboard()
+		Project(log, leaderboard)
+		return leaderboard, nil
+	})
+}
+
+// Stats - GET /history/{room}/stats
+func (h *HistoryHandler) Stats(w http.ResponseWriter, r *http.Request) {
+	h.read(w, r, false, func(log []DomainEvent) (interface{}, error) {
+		stats := NewStats()
+		Project(log, stats)
+		return stats, nil
+	})
+}
+
+// read - Fetch the room's log and respond with what view makes of it
+func (h *HistoryHandler) read(w http.ResponseWriter, r *http.Request, restricted bool, view func(log []DomainEvent) (interface{}, error)) {
+
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Server", "NG: Small Browser Based Game Server")
+
+	room, err := h.lobby.Room(chi.URLParam(r, "room"))
+	if err != nil {
+		writeRoomError(w, err)
+		return
+	}
+	if restricted && !h.admin.isAdmin(r) && !room.IsHost(bearer(r)) {
+		writeRoomError(w, ErrNotHost)
+		return
+	}
+
+	log, err := room.Log(r.Context())
+	if err != nil {
+		writeRoomError(w, err)
+		return
+	}
+	response, err := view(log)
+	if err != nil {
+		writeRoomError(w, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(response)
+}
@@ -0,0 +1,113 @@
+package game
+
+import (
+	"log"
+	"sort"
+	"time"
+
+	"networkgaming.co.uk/techtest/pkg/names"
+)
+
+// ExpirePlayers - Release seated players who joined before the cutoff and are still
+// waiting for a game, so their names are free again. Nobody is released mid game.
+func (g *Game) ExpirePlayers(before time.Time) []GamePlayer {
+	left := []GamePlayer{}
+	if g.state != GameStateWaiting && g.state != GameStateReady {
+		return left
+	}
+
+	seated := make([]GamePlayer, 0, len(g.Players))
+	for _, player := range g.Players {
+		seated = append(seated, player)
+	}
+	for _, player := range byName(seated) {
+		if player.JoinedAt.Before(before) {
+			left = append(left, g.release(player.Name))
+		}
+	}
+	if len(g.Players) < MinPlayersRequired {
+		g.transition(GameStateWaiting)
+	}
+
+	return byName(left)
+}
+
+// release - Take a player off the table or out of the waiting room and free their name
+func (g *Game) release(name string) GamePlayer {
+	player, seated := g.Players[name]
+	if !seated {
+		player = g.registered[names.Skeleton(name)]
+	}
+	g.record(DomainEvent{Type: DomainPlayerLeft, Player: &player})
+
+	return player
+}
+
+// byName - Players in name order, so events come out the same every time
+func byName(players []GamePlayer) []GamePlayer {
+	sort.Slice(players, func(i, j int) bool {
+		return players[i].Name < players[j].Name
+	})
+
+	return players
+}
+
+// Intermission - Hold the completed game on the table so everyone can see who won
+func (g *Game) Intermission() error {
+	if g.state != GameStateCompleted {
+		return ErrGameNotComplete
+	}
+
+	return g.transition(GameStateIntermission)
+}
+
+// expire - Release anyone seated for longer than the SeatTimeout without a game,
+// handing back their stake
+func (eng *Engine) expire() []*Event {
+	if eng.Config.SeatTimeout <= 0 {
+		return nil
+	}
+
+	left := eng.Game.ExpirePlayers(eng.Clock.Now().Add(-eng.Config.SeatTimeout))
+	if len(left) == 0 {
+		return nil
+	}
+	if eng.Stakes != nil {
+		for _, player := range left {
+			if err := eng.Stakes.Unstake(player.Name); err != nil {
+				log.Printf("Unable to return stake: %s\n", err.Error())
+			}
+		}
+	}
+	if eng.Game.GetState() == GameStateWaiting {
+		eng.resetCountdown()
+	}
+
+	return []*Event{NewEvent(PlayerLeft, left)}
+}
+
+// restake - Take a new stake from everyone carried over to the next game,
+// releasing anyone who can't cover it
+func (eng *Engine) restake() []*Event {
+	if eng.Stakes == nil {
+		return nil
+	}
+
+	left := []GamePlayer{}
+	for _, player := range eng.Game.GetRoundResult().LeaderBoard {
+		// Bots play without a stake
+		if player.Bot {
+			continue
+		}
+		if err := eng.Stakes.Stake(player.Name, player.Account); err != nil {
+			log.Printf("Unable to keep %s seated: %s\n", player.Name, err.Error())
+			eng.Game.RemovePlayer(player.Name)
+			left = append(left, player)
+		}
+	}
+	if len(left) > 0 {
+		return []*Event{NewEvent(PlayerLeft, byName(left))}
+	}
+
+	return nil
+}
@@ -0,0 +1,95 @@
+package game
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestResetReleasesPlayers(t *testing.T) {
+	assert := assert.New(t)
+
+	game := NewGame(NewSSNG([]int{5}))
+	game.Condition = NewMaxRoundsCondition(1)
+	game.RegisterPlayer(&Player{Name: "Steve", First: 3, Second: 7, StaySeated: true})
+	game.RegisterPlayer(&Player{Name: "Sarah", First: 4, Second: 8})
+	game.RegisterPlayer(&Player{Name: "Simon", First: 2, Second: 9})
+	game.AddWaitingPlayersToGame()
+	game.Start()
+	game.PlayRound()
+	assert.Equal(GameStateCompleted, game.GetState())
+	game.NominateWinner()
+	game.TakeEvents()
+
+	game.Reset()
+	assert.Len(game.Players, 1)
+	steve := game.Players["Steve"]
+	assert.Equal(0, steve.Score)
+	assert.False(steve.Winner)
+	assert.True(steve.StaySeated)
+
+	events := game.TakeEvents()
+	assert.Len(events, 2)
+	assert.Equal(StateChanged.String(), events[0].Type)
+	assert.Equal(Transition{From: GameStateCompleted, To: GameStateWaiting}, events[0].Data)
+	assert.Equal(PlayerLeft.String(), events[1].Type)
+	left := events[1].Data.([]GamePlayer)
+	assert.Equal("Sarah", left[0].Name)
+	assert.Equal("Simon", left[1].Name)
+
+	// Free to join again, the seated player isn't
+	assert.Nil(game.RegisterPlayer(&Player{Name: "sarah", First: 4, Second: 8}))
+	assert.Equal(ErrInvalidPlayerName, game.RegisterPlayer(&Player{Name: "Steve", First: 3, Second: 7}))
+	joined, _ := game.AddWaitingPlayersToGame()
+	assert.Len(joined, 1)
+	game.GetReady()
+	assert.Equal(GameStateReady, game.GetState())
+}
+
+func TestExpirePlayers(t *testing.T) {
+	assert := assert.New(t)
+
+	game := NewGame(NewRNG(MaxNum))
+	game.RegisterPlayer(&Player{Name: "Steve", First: 3, Second: 7})
+	game.RegisterPlayer(&Player{Name: "Sarah", First: 4, Second: 8})
+	game.AddWaitingPlayersToGame()
+	game.GetReady()
+	cutoff := time.Now().Add(-time.Minute)
+	steve := game.Players["Steve"]
+	steve.JoinedAt = cutoff.Add(-time.Second)
+	game.Players["Steve"] = steve
+	assert.Equal(GameStateReady, game.GetState())
+
+	left := game.ExpirePlayers(cutoff)
+	assert.Len(left, 1)
+	assert.Equal("Steve", left[0].Name)
+	assert.Len(game.Players, 1)
+	assert.Nil(game.CheckPlayerExists("Steve"))
+	assert.Equal(GameStateWaiting, game.GetState())
+
+	// Nobody is released mid game
+	game.RegisterPlayer(&Player{Name: "Steve", First: 3, Second: 7})
+	game.AddWaitingPlayersToGame()
+	game.Start()
+	assert.Empty(game.ExpirePlayers(time.Now().Add(time.Minute)))
+	assert.Len(game.Players, 2)
+}
+
+func TestEngineRestake(t *testing.T) {
+	assert := assert.New(t)
+
+	game := NewGame(NewRNG(MaxNum))
+	engine := NewEngine(game, &EngineConfig{GameSpeed: 10 * time.Nanosecond, WaitingCount: 10, ManualRun: true})
+	stakes := &heldStakes{}
+	engine.Stakes = stakes
+	assert.Nil(engine.stake(&Player{Name: "Steve", First: 3, Second: 7, StaySeated: true, Account: "account-1"}))
+	_, err := engine.addBot("wide")
+	assert.Nil(err)
+	game.AddWaitingPlayersToGame()
+
+	// Staked again from the same account, bots never stake
+	assert.Empty(engine.restake())
+	assert.Equal([]string{"Steve", "Steve"}, stakes.held)
+	assert.Equal([]string{"account-1", "account-1"}, stakes.accounts)
+}
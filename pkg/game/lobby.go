@@ -0,0 +1,460 @@
+package game
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"net/http"
+	"sort"
+	"sync"
+	"time"
+
+	"networkgaming.co.uk/techtest/pkg/names"
+	"networkgaming.co.uk/techtest/pkg/problem"
+)
+
+const (
+	// MainRoom - The public table everyone joins by default
+	MainRoom = "main"
+)
+
+var (
+	ErrRoomExists   = problem.New("room_exists", http.StatusConflict, "Invalid room: A room with that ID already exists")
+	ErrRoomNotFound = problem.New("room_not_found", http.StatusNotFound, "Invalid room: No room with that ID")
+)
+
+// Room - A game with its own engine and broadcaster.
+// Private rooms are only joined with their invite code, which is also their ID.
+// Public rooms spill over into the overflow room once they're full.
+// Rooms opened without a name are closed once they've been idle for the lobby's IdleTimeout.
+type Room struct {
+	ID          string
+	Game        *Game
+	Engine      *Engine
+	Broadcaster *Broadcaster
+	Chat        *Chat
+	Private     bool
+	password    string
+	overflow    string
+	named       bool
+	idleSince   time.Time
+	cancel      context.CancelFunc
+}
+
+// Join - Register a player through the room's engine
+func (r *Room) Join(ctx context.Context, player *Player) error {
+	_, err := r.Enter(ctx, player)
+
+	return err
+}
+
+// Enter - Join, returning the player's place on the waitlist, zero if they'll have a seat
+func (r *Room) Enter(ctx context.Context, player *Player) (int, error) {
+	ar, err := r.Engine.Do(ctx, &Action{
+		Type:   ActionTypeJoinGame,
+		Player: player,
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	return ar.Position, nil
+}
+
+// Adjust - Change the picks of the player token was issued to on joining
+func (r *Room) Adjust(ctx context.Context, token string, player *Player) error {
+	session, err := r.Chat.Session(token)
+	if err != nil {
+		return err
+	}
+	player.Name = session.Name
+
+	return r.act(ctx, &Action{
+		Type:   ActionTypeAdjustGame,
+		Player: player,
+	})
+}
+
+// Observe - Snapshot of the room's game and chat, taken by its engine
+func (r *Room) Observe(ctx context.Context) (*Snapshot, error) {
+	ar, err := r.Engine.Do(ctx, &Action{
+		Type: ActionTypeObserveGame,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return ar.Snapshot, nil
+}
+
+// Idle - No game in play and no one but bots seated or waiting, checked by its engine
+func (r *Room) Idle(ctx context.Context) (bool, error) {
+	ar, err := r.Engine.Do(ctx, &Action{
+		Type: ActionTypeCheckIdle,
+	})
+	if err != nil {
+		return false, err
+	}
+
+	return ar.Idle, nil
+}
+
+// Log - Copy of the room's domain event log, taken by its engine
+func (r *Room) Log(ctx context.Context) ([]DomainEvent, error) {
+	ar, err := r.Engine.Do(ctx, &Action{
+		Type: ActionTypeReadLog,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return ar.Log, nil
+}
+
+// act - Send an action to the room's engine and wait for the reply
+func (r *Room) act(ctx context.Context, action *Action) error {
+	_, err := r.Engine.Do(ctx, action)
+
+	return err
+}
+
+// Lobby - Every open room.
+// Names is the name policy for every room, the default policy if not set.
+// Setup is called for each new room before it starts, to set rules, stakes and listeners.
+// Clock runs every room's game, engine and chat, the wall clock if not set.
+// Checkpoints is where every room's engine keeps its checkpoint, none are kept if not set.
+// Restore is what's done with a game a room's checkpoint finds in play when it opens,
+// only rooms opened by name are restored, see Recover for the rest.
+// IdleTimeout is how long a matchmade, spill-over or private room is kept open while idle,
+// they're kept until closed if not set.
+type Lobby struct {
+	Config      *EngineConfig
+	Names       *names.Policy
+	Clock       Clock
+	Setup       func(room *Room)
+	Checkpoints CheckpointStore
+	Restore     RestorePolicy
+	IdleTimeout time.Duration
+	ctx         context.Context
+	rooms       map[string]*Room
+	next        int
+	draining    bool
+	mu          sync.Mutex
+}
+
+// NewLobby - Rooms run until ctx is done or they are closed, each with a copy of config
+func NewLobby(ctx context.Context, config *EngineConfig) *Lobby {
+	return &Lobby{
+		Config: config,
+		ctx:    ctx,
+		rooms:  make(map[string]*Room),
+	}
+}
+
+// Open - Create and start a room, an empty id picks the next free one.
+// A room opened by name carries on from its checkpoint, if it has one.
+func (l *Lobby) Open(id string) (*Room, error) {
+	return l.open(id, nil, id != "")
+}
+
+// open - Create and start a room, prepare is called before anything can reach its engine.
+// A named room is restored from its checkpoint and kept open while idle.
+func (l *Lobby) open(id string, prepare func(room *Room), named bool) (*Room, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if l.draining {
+		return nil, ErrShuttingDown
+	}
+	if id == "" {
+		for id == "" || l.rooms[id] != nil {
+			l.next++
+			id = fmt.Sprintf("room-%d", l.next)
+		}
+	}
+	if _, exists := l.rooms[id]; exists {
+		return nil, ErrRoomExists
+	}
+
+	room := l.room(id, prepare)
+	ctx, cancel := context.WithCancel(l.ctx)
+	room.cancel = cancel
+	room.named = named
+	// A room that fails to restore starts afresh rather than not at all
+	if named {
+		if err := room.Engine.Restore(l.Restore); err != nil {
+			log.Printf("Room %s - Unable to restore checkpoint: %s\n", id, err.Error())
+		}
+	}
+
+	room.Broadcaster.Start(ctx)
+	room.Engine.Start(ctx)
+	l.rooms[id] = room
+
+	return room, nil
+}
+
+// room - A new room, set up but not started
+func (l *Lobby) room(id string, prepare func(room *Room)) *Room {
+	config := *l.Config
+	game := NewGame(NewRNG(MaxNum))
+	if l.Names != nil {
+		game.Names = l.Names
+	}
+	engine := NewEngine(game, &config)
+	engine.Room = id
+	engine.Chat = NewChat(id)
+	engine.Watchers = append(engine.Watchers, engine.Chat)
+	engine.Checkpoints = l.Checkpoints
+	if l.Clock != nil {
+		game.Clock = l.Clock
+		engine.Clock = l.Clock
+		engine.Chat.Now = l.Clock.Now
+	}
+	room := &Room{
+		ID:          id,
+		Game:        game,
+		Engine:      engine,
+		Broadcaster: NewBroadcaster(engine.Event),
+		Chat:        engine.Chat,
+	}
+	if prepare != nil {
+		prepare(room)
+	}
+	if l.Setup != nil {
+		l.Setup(room)
+	}
+
+	return room
+}
+
+// Recover - Settle the checkpoint of every room but those in keep, before any room opens.
+// Generated and private rooms aren't opened again once the server restarts, so their
+// games are called off and every stake they held handed back. Generated IDs carry on
+// past theirs, so one that can't be settled is never taken over by a new room.
+func (l *Lobby) Recover(keep ...string) error {
+	if l.Checkpoints == nil {
+		return nil
+	}
+	ids, err := l.Checkpoints.List()
+	if err != nil {
+		return err
+	}
+	kept := make(map[string]bool, len(keep))
+	for _, id := range keep {
+		kept[id] = true
+	}
+
+	for _, id := range ids {
+		var n int
+		if _, err := fmt.Sscanf(id, "room-%d", &n); err == nil {
+			l.mu.Lock()
+			if n > l.next {
+				l.next = n
+			}
+			l.mu.Unlock()
+		}
+		if kept[id] {
+			continue
+		}
+		if err := l.room(id, nil).Engine.discard(); err != nil {
+			log.Printf("Room %s - Unable to settle checkpoint: %s\n", id, err.Error())
+			continue
+		}
+		log.Printf("Room %s - Settled the checkpoint of a room that won't reopen\n", id)
+	}
+
+	return nil
+}
+
+// Room - Look up an open room
+func (l *Lobby) Room(id string) (*Room, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	room, exists := l.rooms[id]
+	if !exists {
+		return nil, ErrRoomNotFound
+	}
+
+	return room, nil
+}
+
+// Rooms - Every open room, by ID
+func (l *Lobby) Rooms() []*Room {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	rooms := make([]*Room, 0, len(l.rooms))
+	for _, room := range l.rooms {
+		rooms = append(rooms, room)
+	}
+	sort.Slice(rooms, func(i, j int) bool {
+		return rooms[i].ID < rooms[j].ID
+	})
+
+	return rooms
+}
+
+// Close - Cancel the room's game and stop its engine and broadcaster
+func (l *Lobby) Close(id string) error {
+	l.mu.Lock()
+	room, exists := l.rooms[id]
+	delete(l.rooms, id)
+	l.mu.Unlock()
+
+	if !exists {
+		return ErrRoomNotFound
+	}
+
+	// Engine first, so nothing it broadcasts on the way out is lost
+	room.Engine.Stop(context.Background())
+	room.cancel()
+	if room.Engine.Checkpoints != nil {
+		if err := room.Engine.Checkpoints.Delete(id); err != nil {
+			log.Printf("Room %s - Unable to delete checkpoint: %s\n", id, err.Error())
+		}
+	}
+
+	return nil
+}
+
+// Place - Join the room, or the room it spills over into once it's full.
+// Private rooms never spill over, their players get ErrRoomFull.
+func (l *Lobby) Place(ctx context.Context, id string, player *Player) (*Room, int, error) {
+	for {
+		room, err := l.Room(id)
+		if err != nil {
+			return nil, 0, err
+		}
+		position, err := room.Enter(ctx, player)
+		if err != ErrRoomFull || room.Private {
+			return room, position, err
+		}
+		if id, err = l.overflow(room); err != nil {
+			return nil, 0, err
+		}
+	}
+}
+
+// overflow - The room taking room's extra players, opened the first time it's needed
+func (l *Lobby) overflow(room *Room) (string, error) {
+	l.mu.Lock()
+	id := room.overflow
+	_, open := l.rooms[id]
+	l.mu.Unlock()
+	if open {
+		return id, nil
+	}
+
+	spill, err := l.Open("")
+	if err != nil {
+		return "", err
+	}
+	l.mu.Lock()
+	room.overflow = spill.ID
+	l.mu.Unlock()
+
+	return spill.ID, nil
+}
+
+// Validate - Check a player could join a new room, used by matchmaking
+func (l *Lobby) Validate(player *Player) error {
+	l.mu.Lock()
+	draining := l.draining
+	l.mu.Unlock()
+	if draining {
+		return ErrShuttingDown
+	}
+
+	game := NewGame(nil)
+	if l.Names != nil {
+		game.Names = l.Names
+	}
+
+	return game.ValidatePlayer(player)
+}
+
+// Seat - Open a new room for players, used by matchmaking
+func (l *Lobby) Seat(players []*Player) (string, error) {
+	room, err := l.Open("")
+	if err != nil {
+		return "", err
+	}
+
+	for _, player := range players {
+		if err := room.Join(l.ctx, player); err != nil {
+			// Closing hands back the stakes of everyone already seated
+			if err := l.Close(room.ID); err != nil {
+				log.Printf("Room %s - Unable to close: %s\n", room.ID, err.Error())
+			}
+			return "", err
+		}
+	}
+
+	return room.ID, nil
+}
+
+// ChatToken - Issue a chat token to a player seated in room id, used by matchmaking
+func (l *Lobby) ChatToken(id string, player *Player) (string, error) {
+	room, err := l.Room(id)
+	if err != nil {
+		return "", err
+	}
+
+	return room.Chat.Register(player.Name, player.Account)
+}
+
+// Start - Close idle rooms every interval until ctx is done
+func (l *Lobby) Start(ctx context.Context, interval time.Duration) {
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ticker.C:
+				l.Reap()
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+}
+
+// Reap - Close every room opened without a name that's been idle for IdleTimeout,
+// so a game that's ended or a room no one came to doesn't keep its engine running
+func (l *Lobby) Reap() {
+	if l.IdleTimeout <= 0 {
+		return
+	}
+	now := time.Now()
+	if l.Clock != nil {
+		now = l.Clock.Now()
+	}
+
+	for _, room := range l.Rooms() {
+		if room.named {
+			continue
+		}
+		idle, err := room.Idle(l.ctx)
+		if err != nil {
+			continue
+		}
+
+		l.mu.Lock()
+		if !idle {
+			room.idleSince = time.Time{}
+		} else if room.idleSince.IsZero() {
+			room.idleSince = now
+		}
+		expired := idle && now.Sub(room.idleSince) >= l.IdleTimeout
+		l.mu.Unlock()
+
+		if expired {
+			if err := l.Close(room.ID); err != nil {
+				continue
+			}
+			log.Printf("Room %s - Closed after being idle for %s\n", room.ID, l.IdleTimeout)
+		}
+	}
+}
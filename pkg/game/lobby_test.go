@@ -0,0 +1,123 @@
+package game
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLobbySeat(t *testing.T) {
+	assert := assert.New(t)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	lobby := NewLobby(ctx, &EngineConfig{
+		GameSpeed:    1 * time.Minute,
+		WaitingCount: 10,
+		ManualRun:    true,
+	})
+
+	main, err := lobby.Open(MainRoom)
+	assert.Nil(err)
+	_, err = lobby.Open(MainRoom)
+	assert.Equal(ErrRoomExists, err)
+
+	id, err := lobby.Seat([]*Player{
+		{Name: "Steve", First: 3, Second: 7},
+		{Name: "Sarah", First: 4, Second: 8},
+	})
+	assert.Nil(err)
+	assert.NotEqual(main.ID, id)
+
+	room, err := lobby.Room(id)
+	assert.Nil(err)
+	assert.Equal(ErrInvalidPlayerName, room.Game.CheckPlayerExists("Sarah"))
+	assert.Nil(main.Game.CheckPlayerExists("Sarah"))
+	assert.Len(lobby.Rooms(), 2)
+
+	assert.Equal(ErrInvalidNumber, lobby.Validate(&Player{Name: "Simon", First: 3, Second: 70}))
+
+	assert.Nil(lobby.Close(id))
+	assert.Equal(ErrRoomNotFound, lobby.Close(id))
+	assert.Len(lobby.Rooms(), 1)
+}
+
+func TestLobbySeatFailure(t *testing.T) {
+	assert := assert.New(t)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	lobby := NewLobby(ctx, &EngineConfig{
+		GameSpeed:    1 * time.Minute,
+		WaitingCount: 10,
+		ManualRun:    true,
+	})
+	stakes := &heldStakes{}
+	lobby.Setup = func(room *Room) {
+		room.Engine.Stakes = stakes
+	}
+	_, err := lobby.Open(MainRoom)
+	assert.Nil(err)
+
+	// Sarah is seated and staked before Steve's second pick is refused
+	id, err := lobby.Seat([]*Player{
+		{Name: "Sarah", First: 4, Second: 8},
+		{Name: "Steve", First: 3, Second: 70},
+	})
+	assert.Equal(ErrInvalidNumber, err)
+	assert.Equal("", id)
+	assert.True(stakes.refunded)
+	assert.Len(lobby.Rooms(), 1)
+}
+
+func TestLobbyReap(t *testing.T) {
+	assert := assert.New(t)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	clock := NewFakeClock(time.Date(2026, 10, 19, 12, 0, 0, 0, time.UTC))
+	lobby := NewLobby(ctx, &EngineConfig{
+		GameSpeed:    1 * time.Minute,
+		WaitingCount: 10,
+		ManualRun:    true,
+	})
+	lobby.Clock = clock
+	lobby.IdleTimeout = 5 * time.Minute
+
+	_, err := lobby.Open(MainRoom)
+	assert.Nil(err)
+	seated, err := lobby.Seat([]*Player{
+		{Name: "Steve", First: 3, Second: 7},
+		{Name: "Sarah", First: 4, Second: 8},
+	})
+	assert.Nil(err)
+	empty, err := lobby.Open("")
+	assert.Nil(err)
+	private, _, err := lobby.OpenPrivate("")
+	assert.Nil(err)
+	assert.Len(lobby.Rooms(), 4)
+
+	// Idle rooms are given IdleTimeout before they're closed
+	lobby.Reap()
+	clock.Advance(4 * time.Minute)
+	lobby.Reap()
+	assert.Len(lobby.Rooms(), 4)
+
+	clock.Advance(1 * time.Minute)
+	lobby.Reap()
+	_, err = lobby.Room(empty.ID)
+	assert.Equal(ErrRoomNotFound, err)
+	_, err = lobby.Room(private.ID)
+	assert.Equal(ErrRoomNotFound, err)
+
+	// Rooms with players in and rooms opened by name stay open
+	_, err = lobby.Room(seated)
+	assert.Nil(err)
+	_, err = lobby.Room(MainRoom)
+	assert.Nil(err)
+}
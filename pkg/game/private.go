@@ -0,0 +1,223 @@
+package game
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/hex"
+	"net/http"
+	"strings"
+
+	"networkgaming.co.uk/techtest/pkg/problem"
+)
+
+const (
+	// InviteCodeLength - Short enough to read out to a friend
+	InviteCodeLength = 6
+	// inviteAlphabet - No 0/O or 1/I to mix up
+	inviteAlphabet = "ABCDEFGHJKLMNPQRSTUVWXYZ23456789"
+)
+
+var (
+	ErrInvalidInviteCode = problem.New("invalid_invite_code", http.StatusNotFound, "Invalid invite: No private room with that code")
+	ErrWrongPassword     = problem.New("wrong_password", http.StatusForbidden, "Invalid invite: Wrong password for this room")
+	ErrNotHost           = problem.New("not_host", http.StatusForbidden, "Invalid action: Only the host can do that")
+	ErrNoSettings        = problem.New("no_settings", http.StatusBadRequest, "Invalid settings: No settings given")
+)
+
+// OpenPrivate - Create a room only joinable with its invite code, and password if one is given.
+// The token returned identifies the host for Start, Kick and Configure.
+func (l *Lobby) OpenPrivate(password string) (*Room, string, error) {
+	token, err := randomToken()
+	if err != nil {
+		return nil, "", err
+	}
+
+	for {
+		code, err := inviteCode()
+		if err != nil {
+			return nil, "", err
+		}
+		// Never restored, a room's code and password don't outlive the server
+		room, err := l.open(code, func(room *Room) {
+			room.Private = true
+			room.password = password
+			room.Engine.HostToken = token
+		}, false)
+		if err == ErrRoomExists {
+			continue
+		}
+
+		return room, token, err
+	}
+}
+
+// Private - Look up a private room by invite code, checking the password
+func (l *Lobby) Private(code string, password string) (*Room, error) {
+	room, err := l.Room(strings.ToUpper(strings.TrimSpace(code)))
+	if err != nil || !room.Private {
+		return nil, ErrInvalidInviteCode
+	}
+	if err := room.Admit(password); err != nil {
+		return nil, err
+	}
+
+	return room, nil
+}
+
+// Admit - Check the password for a private room, public rooms let everyone in
+func (r *Room) Admit(password string) error {
+	if r.password == "" {
+		return nil
+	}
+	if subtle.ConstantTimeCompare([]byte(password), []byte(r.password)) != 1 {
+		return ErrWrongPassword
+	}
+
+	return nil
+}
+
+// IsHost - Whether token is the room's host token
+func (r *Room) IsHost(token string) bool {
+	return isHost(r.Engine.HostToken, token)
+}
+
+// Start - Host starts the game without waiting for the countdown
+func (r *Room) Start(ctx context.Context, token string) error {
+	if !r.IsHost(token) {
+		return ErrNotHost
+	}
+
+	return r.act(ctx, &Action{
+		Type:  ActionTypeStartGame,
+		Token: token,
+	})
+}
+
+// Kick - Host removes a player, they get their stake back unless the game has started
+func (r *Room) Kick(ctx context.Context, token string, name string) error {
+	if !r.IsHost(token) {
+		return ErrNotHost
+	}
+
+	return r.act(ctx, &Action{
+		Type:   ActionTypeKickPlayer,
+		Player: &Player{Name: name},
+		Token:  token,
+	})
+}
+
+// Configure - Host changes the rules between games
+func (r *Room) Configure(ctx context.Context, token string, settings *RoomSettings) error {
+	if !r.IsHost(token) {
+		return ErrNotHost
+	}
+
+	return r.act(ctx, &Action{
+		Type:     ActionTypeChangeSettings,
+		Settings: settings,
+		Token:    token,
+	})
+}
+
+// host - Carry out a host action, returning the events to broadcast once the host has their reply
+func (eng *Engine) host(action *Action) ([]*Event, error) {
+	if !isHost(eng.HostToken, action.Token) {
+		return nil, ErrNotHost
+	}
+
+	switch action.Type {
+	case ActionTypeStartGame:
+		return eng.startEarly()
+	case ActionTypeKickPlayer:
+		return eng.kick(action.Player)
+	case ActionTypeChangeSettings:
+		return eng.configure(action.Settings)
+	}
+
+	return nil, nil
+}
+
+// startEarly - Seat everyone waiting and start now, rather than at the end of the countdown
+func (eng *Engine) startEarly() ([]*Event, error) {
+	switch eng.Game.GetState() {
+	case GameStateInProgress:
+		return nil, ErrGameInProgress
+	case GameStateCompleted, GameStateIntermission:
+		return nil, ErrGameComplete
+	}
+
+	events := eng.seat()
+	if err := eng.Game.Start(); err != nil {
+		return events, err
+	}
+	eng.cancelCountdown()
+
+	return append(events, NewEvent(GameStarted, eng.count).Until(eng.deadline())), nil
+}
+
+// kick - Remove a player, refunding their stake if they haven't played yet
+func (eng *Engine) kick(player *Player) ([]*Event, error) {
+	if player == nil {
+		return nil, ErrPlayerNotFound
+	}
+
+	state := eng.Game.GetState()
+	started := state == GameStateInProgress || state == GameStateCompleted || state == GameStateIntermission
+	if err := eng.Game.RemovePlayer(player.Name); err != nil {
+		return nil, err
+	}
+	if eng.Stakes != nil && !started {
+		if err := eng.Stakes.Unstake(player.Name); err != nil {
+			return nil, err
+		}
+	}
+	if eng.Game.GetState() == GameStateWaiting {
+		eng.resetCountdown()
+	}
+
+	return []*Event{NewEvent(PlayerKicked, player.Name)}, nil
+}
+
+// configure - Change the game's rules and the countdown
+func (eng *Engine) configure(settings *RoomSettings) ([]*Event, error) {
+	if settings == nil {
+		return nil, ErrNoSettings
+	}
+	if err := eng.Game.Configure(settings); err != nil {
+		return nil, err
+	}
+	if settings.WaitingCount != 0 {
+		eng.Config.WaitingCount = settings.WaitingCount
+	}
+
+	return []*Event{NewEvent(SettingsChanged, settings)}, nil
+}
+
+// isHost - Rooms without a host token have no host
+func isHost(hostToken string, token string) bool {
+	return hostToken != "" && subtle.ConstantTimeCompare([]byte(token), []byte(hostToken)) == 1
+}
+
+// inviteCode - A random code from inviteAlphabet
+func inviteCode() (string, error) {
+	b := make([]byte, InviteCodeLength)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	for i := range b {
+		b[i] = inviteAlphabet[int(b[i])%len(inviteAlphabet)]
+	}
+
+	return string(b), nil
+}
+
+// randomToken - A secret for the host to prove who they are
+func randomToken() (string, error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+
+	return hex.EncodeToString(b), nil
+}
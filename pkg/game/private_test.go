@@ -0,0 +1,106 @@
+package game
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func newTestLobby(ctx context.Context) *Lobby {
+	return NewLobby(ctx, &EngineConfig{
+		GameSpeed:    1 * time.Minute,
+		WaitingCount: 10,
+		ManualRun:    true,
+	})
+}
+
+func TestPrivateRoomInvite(t *testing.T) {
+	assert := assert.New(t)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	lobby := newTestLobby(ctx)
+	lobby.Open(MainRoom)
+
+	room, token, err := lobby.OpenPrivate("secret")
+	assert.Nil(err)
+	assert.Len(room.ID, InviteCodeLength)
+	assert.NotEmpty(token)
+
+	_, err = lobby.Private("NOPE00", "secret")
+	assert.Equal(ErrInvalidInviteCode, err)
+	_, err = lobby.Private(MainRoom, "")
+	assert.Equal(ErrInvalidInviteCode, err)
+	_, err = lobby.Private(room.ID, "guess")
+	assert.Equal(ErrWrongPassword, err)
+
+	found, err := lobby.Private(" "+room.ID+" ", "secret")
+	assert.Nil(err)
+	assert.Equal(room, found)
+
+	open, _, err := lobby.OpenPrivate("")
+	assert.Nil(err)
+	assert.Nil(open.Admit("anything"))
+}
+
+func TestPrivateRoomHost(t *testing.T) {
+	assert := assert.New(t)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	lobby := newTestLobby(ctx)
+
+	room, token, _ := lobby.OpenPrivate("")
+	assert.Nil(room.Join(ctx, &Player{Name: "Steve", First: 3, Second: 7}))
+
+	assert.Equal(ErrNotHost, room.Start(ctx, "guess"))
+	assert.Equal(ErrNotHost, room.Kick(ctx, "", "Steve"))
+	assert.Equal(ErrNotHost, room.Configure(ctx, "guess", &RoomSettings{}))
+	assert.Equal(ErrNotEnoughPlayers.Error(), room.Start(ctx, token).Error())
+
+	assert.Equal(ErrInvalidWaitingCount.Error(), room.Configure(ctx, token, &RoomSettings{WaitingCount: -1}).Error())
+	assert.Nil(room.Configure(ctx, token, &RoomSettings{WaitingCount: 5, Scoring: "distance"}))
+	assert.Equal(5, room.Engine.Config.WaitingCount)
+
+	assert.Nil(room.Join(ctx, &Player{Name: "Sarah", First: 4, Second: 8}))
+	assert.Nil(room.Join(ctx, &Player{Name: "Simon", First: 2, Second: 9}))
+	simon, _ := room.Chat.Register("Simon", "")
+	assert.Nil(room.Kick(ctx, token, "Simon"))
+	_, err := room.Chat.Session(simon)
+	assert.Equal(ErrChatTokenInvalid, err)
+	assert.Equal(ErrPlayerNotFound.Error(), room.Kick(ctx, token, "Simon").Error())
+
+	// Seats everyone waiting and starts without a countdown
+	assert.Nil(room.Start(ctx, token))
+	assert.Equal(GameStateInProgress, room.Game.GetState())
+	assert.Len(room.Game.Players, 2)
+	assert.Equal(ErrGameInProgress.Error(), room.Start(ctx, token).Error())
+
+	// The main room has no host
+	main, _ := lobby.Open(MainRoom)
+	assert.Equal(ErrNotHost, main.Start(ctx, ""))
+}
+
+func TestPrivateRoomAdjust(t *testing.T) {
+	assert := assert.New(t)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	lobby := newTestLobby(ctx)
+
+	allow := true
+	room, host, _ := lobby.OpenPrivate("")
+	assert.Nil(room.Configure(ctx, host, &RoomSettings{AllowAdjust: &allow}))
+	assert.Nil(room.Join(ctx, &Player{Name: "Steve", First: 3, Second: 7}))
+	assert.Nil(room.Join(ctx, &Player{Name: "Sarah", First: 4, Second: 8}))
+	steve, _ := room.Chat.Register("Steve", "")
+	assert.Nil(room.Start(ctx, host))
+
+	// The token decides who's adjusted, not the name sent with it
+	assert.Equal(ErrChatTokenInvalid, room.Adjust(ctx, "guess", &Player{Name: "Steve", First: 9, Second: 9}))
+	assert.Nil(room.Adjust(ctx, steve, &Player{Name: "Sarah", First: 9, Second: 9}))
+	assert.Equal([]int{9, 9}, room.Game.Players["Steve"].Picks)
+	assert.Equal([]int{4, 8}, room.Game.Players["Sarah"].Picks)
+}
@@ -0,0 +1,167 @@
+package game
+
+import (
+	"sort"
+)
+
+// Projection - A read model kept up to date from a game's log
+type Projection interface {
+	Apply(event DomainEvent)
+}
+
+// Project - Feed the log through each projection in order
+func Project(log []DomainEvent, projections ...Projection) {
+	for _, event := range log {
+		for _, projection := range projections {
+			projection.Apply(event)
+		}
+	}
+}
+
+// Leaderboard - Everyone at the table, best score first. Ties are in name order.
+type Leaderboard struct {
+	Round   int          `json:"round"`
+	Players []GamePlayer `json:"players"`
+	seated  map[string]GamePlayer
+}
+
+// NewLeaderboard - An empty table
+func NewLeaderboard() *Leaderboard {
+	return &Leaderboard{Players: []GamePlayer{}, seated: make(map[string]GamePlayer)}
+}
+
+// Apply - See Projection
+func (lb *Leaderboard) Apply(event DomainEvent) {
+	switch event.Type {
+	case DomainPlayerSeated, DomainScoreChanged, DomainWinnerNominated:
+		lb.seated[event.Player.Name] = *event.Player
+	case DomainPlayerLeft:
+		delete(lb.seated, event.Player.Name)
+	case DomainGameReset:
+		for name, player := range lb.seated {
+			player.Score = 0
+			player.Streak = 0
+			player.Bust = false
+			player.Eliminated = false
+			player.Winner = false
+			lb.seated[name] = player
+		}
+	case DomainGameRestored:
+		lb.seated = make(map[string]GamePlayer, len(event.Snapshot.Players))
+		for name, player := range event.Snapshot.Players {
+			lb.seated[name] = player
+		}
+	}
+	lb.Round = event.Round
+
+	lb.Players = make([]GamePlayer, 0, len(lb.seated))
+	for _, player := range lb.seated {
+		lb.Players = append(lb.Players, player)
+	}
+	sort.Slice(lb.Players, func(i, j int) bool {
+		if lb.Players[i].Score != lb.Players[j].Score {
+			return lb.Players[i].Score > lb.Players[j].Score
+		}
+		return lb.Players[i].Name < lb.Players[j].Name
+	})
+}
+
+// PlayerStats - How a player has done across every game in the log
+type PlayerStats struct {
+	Name      string `json:"name"`
+	Games     int    `json:"games"`
+	Wins      int    `json:"wins"`
+	Busts     int    `json:"busts"`
+	BestScore int    `json:"best_score"`
+}
+
+// Stats - Games played, how often each number came up and how each player has done.
+// Games compacted out of the log are counted from the snapshot that replaced them.
+type Stats struct {
+	Games   int                     `json:"games"`
+	Rounds  int                     `json:"rounds"`
+	Draws   map[int]int             `json:"draws"`
+	Players map[string]*PlayerStats `json:"players"`
+	seated  map[string]GamePlayer
+}
+
+// NewStats - Nothing played yet
+func NewStats() *Stats {
+	return &Stats{
+		Draws:   make(map[int]int),
+		Players: make(map[string]*PlayerStats),
+		seated:  make(map[string]GamePlayer),
+	}
+}
+
+// Apply - See Projection
+func (st *Stats) Apply(event DomainEvent) {
+	switch event.Type {
+	case DomainPlayerSeated:
+		st.seated[event.Player.Name] = *event.Player
+	case DomainPlayerLeft:
+		delete(st.seated, event.Player.Name)
+	case DomainGameStarted:
+		st.Games++
+		for name := range st.seated {
+			st.player(name).Games++
+		}
+	case DomainNumberDrawn:
+		st.Rounds++
+		st.Draws[event.Number]++
+	case DomainScoreChanged:
+		stats := st.player(event.Player.Name)
+		if event.Player.Bust && !st.seated[event.Player.Name].Bust {
+			stats.Busts++
+		}
+		if event.Player.Score > stats.BestScore {
+			stats.BestScore = event.Player.Score
+		}
+		st.seated[event.Player.Name] = *event.Player
+	case DomainWinnerNominated:
+		st.player(event.Player.Name).Wins++
+		st.seated[event.Player.Name] = *event.Player
+	case DomainGameReset:
+		for name, player := range st.seated {
+			player.Bust = false
+			st.seated[name] = player
+		}
+	case DomainGameRestored:
+		st.seated = make(map[string]GamePlayer, len(event.Snapshot.Players))
+		for name, player := range event.Snapshot.Players {
+			st.seated[name] = player
+		}
+		if event.Stats != nil {
+			st.add(event.Stats)
+		}
+	}
+}
+
+// add - Count everything in folded as well, leaving folded as it was
+func (st *Stats) add(folded *Stats) {
+	st.Games += folded.Games
+	st.Rounds += folded.Rounds
+	for number, count := range folded.Draws {
+		st.Draws[number] += count
+	}
+	for name, player := range folded.Players {
+		stats := st.player(name)
+		stats.Games += player.Games
+		stats.Wins += player.Wins
+		stats.Busts += player.Busts
+		if player.BestScore > stats.BestScore {
+			stats.BestScore = player.BestScore
+		}
+	}
+}
+
+// player - Stats for name, started on first sight
+func (st *Stats) player(name string) *PlayerStats {
+	stats, ok := st.Players[name]
+	if !ok {
+		stats = &PlayerStats{Name: name}
+		st.Players[name] = stats
+	}
+
+	return stats
+}
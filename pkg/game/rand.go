@@ -11,12 +11,24 @@ type NumberGenerator interface {
 
 // RNG - Not seeded (so not random really atm)
 type RNG struct {
-	Max int
+	Max    int
+	source *rand.Rand
 }
 
 // NewRNG - New Random Number Generator
 func NewRNG(max int) *RNG {
-	rng := &RNG{max}
+	rng := &RNG{Max: max}
+
+	return rng
+}
+
+// NewSeededRNG - Random Number Generator with its own source,
+// the same seed always gives the same sequence
+func NewSeededRNG(max int, seed int64) *RNG {
+	rng := &RNG{
+		Max:    max,
+		source: rand.New(rand.NewSource(seed)),
+	}
 
 	return rng
 }
@@ -24,6 +36,9 @@ func NewRNG(max int) *RNG {
 // GetInt - Will return an int when called
 // Rand includes 0, so pick a number between 0 and MaxNum - 1, then add 1
 func (rng *RNG) GetInt() int {
+	if rng.source != nil {
+		return rng.source.Intn(rng.Max-1) + 1
+	}
 	return rand.Intn(rng.Max-1) + 1
 }
 
@@ -0,0 +1,67 @@
+package game
+
+import "time"
+
+// GameResult - Final standings of a completed game.
+// Log is the game's domain events from when it started, or was restored if it was resumed.
+type GameResult struct {
+	Room        string        `json:"room"`
+	Winner      GamePlayer    `json:"winner"`
+	LeaderBoard []GamePlayer  `json:"leader_board"`
+	Rounds      int           `json:"rounds"`
+	Scoring     string        `json:"scoring"`
+	Condition   string        `json:"condition"`
+	SuddenDeath bool          `json:"sudden_death"`
+	StartedAt   time.Time     `json:"started_at"`
+	CompletedAt time.Time     `json:"completed_at"`
+	Log         []DomainEvent `json:"-"`
+}
+
+// ResultListener - Told about every game an engine completes.
+// Called from the engine loop, so implementations must not block.
+type ResultListener interface {
+	GameCompleted(result GameResult)
+}
+
+// publishResult - Hand the completed game to every listener
+func (eng *Engine) publishResult(winner GamePlayer) {
+	if len(eng.Listeners) == 0 {
+		return
+	}
+
+	board := eng.Game.GetRoundResult()
+	checkpoint := eng.Game.Checkpoint()
+	log := eng.Game.Log()
+	if start := gameStart(log); start > 0 {
+		log = log[start:]
+	}
+	result := GameResult{
+		Room:        eng.Room,
+		Winner:      winner,
+		LeaderBoard: board.LeaderBoard,
+		Rounds:      board.Round,
+		Scoring:     checkpoint.Scoring,
+		Condition:   checkpoint.Condition,
+		SuddenDeath: checkpoint.SuddenDeath,
+		StartedAt:   checkpoint.StartedAt.UTC(),
+		CompletedAt: eng.Clock.Now().UTC(),
+		Log:         log,
+	}
+	for _, listener := range eng.Listeners {
+		listener.GameCompleted(result)
+	}
+}
+
+// EventListener - Told about every event an engine broadcasts, with the room it's from.
+// Called from the engine loop, so implementations must not block and must be done
+// with the event's data before they return.
+type EventListener interface {
+	GameEvent(room string, event *Event)
+}
+
+// notify - Hand event to every watcher
+func (eng *Engine) notify(event *Event) {
+	for _, watcher := range eng.Watchers {
+		watcher.GameEvent(eng.Room, event)
+	}
+}
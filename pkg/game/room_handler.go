@@ -0,0 +1,278 @@
+package game
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"strings"
+
+	"github.com/go-chi/chi"
+
+	"networkgaming.co.uk/techtest/pkg/problem"
+)
+
+// RoomHandler - Private rooms, host controls need the host token as a bearer token.
+// Accounts is who players are staking as, see JoinGameHandler
+type RoomHandler struct {
+	Accounts Accounts
+	lobby    *Lobby
+}
+
+func NewRoomHandler(lobby *Lobby) *RoomHandler {
+	return &RoomHandler{lobby: lobby}
+}
+
+type CreateRoomRequest struct {
+	Password string        `json:"password,omitempty"`
+	Settings *RoomSettings `json:"settings,omitempty"`
+}
+
+type JoinRoomRequest struct {
+	JoinGameRequest
+	Password string `json:"password,omitempty"`
+}
+
+type KickPlayerRequest struct {
+	Name string `json:"name"`
+}
+
+type RoomResponse struct {
+	Status    int    `json:"status"`
+	Type      string `json:"type"`
+	Title     string `json:"title"`
+	Detail    string `json:"detail"`
+	Code      string `json:"code,omitempty"`
+	Token     string `json:"token,omitempty"`
+	Position  int    `json:"position,omitempty"`
+	ChatToken string `json:"chat_token,omitempty"`
+}
+
+// Create - POST /rooms {password,settings}, the response has the invite code and the host's token
+func (h *RoomHandler) Create(w http.ResponseWriter, r *http.Request) {
+
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Server", "NG: Small Browser Based Game Server")
+
+	request := new(CreateRoomRequest)
+	if !decodeRoomRequest(w, r, request) {
+		return
+	}
+	if request.Settings != nil {
+		// Checked up front so a bad setting doesn't leave an empty room behind
+		if err := request.Settings.validate(); err != nil {
+			writeRoomError(w, err)
+			return
+		}
+	}
+
+	room, token, err := h.lobby.OpenPrivate(request.Password)
+	if err != nil {
+		writeRoomError(w, err)
+		return
+	}
+	if request.Settings != nil {
+		if err := room.Configure(r.Context(), token, request.Settings); err != nil {
+			h.lobby.Close(room.ID)
+			writeRoomError(w, err)
+			return
+		}
+	}
+
+	writeRoomResponse(w, http.StatusCreated, RoomResponse{
+		Type:   "Success",
+		Title:  "Room Created",
+		Detail: "Share the invite code with your friends",
+		Code:   room.ID,
+		Token:  token,
+	})
+}
+
+// Join - POST /rooms/{code}/join, takes the same body as POST /join plus the room's password
+func (h *RoomHandler) Join(w http.ResponseWriter, r *http.Request) {
+
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Server", "NG: Small Browser Based Game Server")
+
+	request := new(JoinRoomRequest)
+	if !decodeRoomRequest(w, r, request) {
+		return
+	}
+
+	room, err := h.lobby.Private(chi.URLParam(r, "code"), request.Password)
+	if err != nil {
+		writeRoomError(w, err)
+		return
+	}
+	player := request.player()
+	if player.Account, err = Account(h.Accounts, r); err != nil {
+		writeRoomError(w, err)
+		return
+	}
+	position, err := room.Enter(r.Context(), player)
+	if err != nil {
+		writeRoomError(w, err)
+		return
+	}
+	token, err := room.Chat.Register(player.Name, player.Account)
+	if err != nil {
+		writeRoomError(w, err)
+		return
+	}
+
+	response := RoomResponse{
+		Type:      "Success",
+		Title:     "Joined Game",
+		Detail:    "Welcome to the game, player ;)",
+		Code:      room.ID,
+		Position:  position,
+		ChatToken: token,
+	}
+	if position > 0 {
+		response.Title = "Joined Waitlist"
+		response.Detail = fmt.Sprintf("Every seat is taken, you're number %d in line for the next one", position)
+	}
+	writeRoomResponse(w, http.StatusOK, response)
+}
+
+// Start - POST /rooms/{code}/start
+func (h *RoomHandler) Start(w http.ResponseWriter, r *http.Request) {
+
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Server", "NG: Small Browser Based Game Server")
+
+	room, ok := h.hostRoom(w, r)
+	if !ok {
+		return
+	}
+	if err := room.Start(r.Context(), bearer(r)); err != nil {
+		writeRoomError(w, err)
+		return
+	}
+
+	writeRoomResponse(w, http.StatusOK, RoomResponse{
+		Type:   "Success",
+		Title:  "Game Started",
+		Detail: "No more waiting",
+		Code:   room.ID,
+	})
+}
+
+// Kick - POST /rooms/{code}/kick {name}
+func (h *RoomHandler) Kick(w http.ResponseWriter, r *http.Request) {
+
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Server", "NG: Small Browser Based Game Server")
+
+	room, ok := h.hostRoom(w, r)
+	if !ok {
+		return
+	}
+	request := new(KickPlayerRequest)
+	if !decodeRoomRequest(w, r, request) {
+		return
+	}
+	if err := room.Kick(r.Context(), bearer(r), request.Name); err != nil {
+		writeRoomError(w, err)
+		return
+	}
+
+	writeRoomResponse(w, http.StatusOK, RoomResponse{
+		Type:   "Success",
+		Title:  "Player Kicked",
+		Detail: request.Name + " has been removed from the room",
+		Code:   room.ID,
+	})
+}
+
+// Configure - POST /rooms/{code}/settings, takes RoomSettings
+func (h *RoomHandler) Configure(w http.ResponseWriter, r *http.Request) {
+
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Server", "NG: Small Browser Based Game Server")
+
+	room, ok := h.hostRoom(w, r)
+	if !ok {
+		return
+	}
+	request := new(RoomSettings)
+	if !decodeRoomRequest(w, r, request) {
+		return
+	}
+	if err := room.Configure(r.Context(), bearer(r), request); err != nil {
+		writeRoomError(w, err)
+		return
+	}
+
+	writeRoomResponse(w, http.StatusOK, RoomResponse{
+		Type:   "Success",
+		Title:  "Settings Changed",
+		Detail: "The new rules apply from the next game",
+		Code:   room.ID,
+	})
+}
+
+// Close - DELETE /rooms/{code}, stakes for an unfinished game are refunded
+func (h *RoomHandler) Close(w http.ResponseWriter, r *http.Request) {
+
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Server", "NG: Small Browser Based Game Server")
+
+	room, ok := h.hostRoom(w, r)
+	if !ok {
+		return
+	}
+	if err := h.lobby.Close(room.ID); err != nil {
+		writeRoomError(w, err)
+		return
+	}
+
+	writeRoomResponse(w, http.StatusOK, RoomResponse{
+		Type:   "Success",
+		Title:  "Room Closed",
+		Detail: "Thanks for playing",
+		Code:   room.ID,
+	})
+}
+
+// hostRoom - The private room in the URL, writing the error response unless the request is from its host
+func (h *RoomHandler) hostRoom(w http.ResponseWriter, r *http.Request) (*Room, bool) {
+	room, err := h.lobby.Room(strings.ToUpper(chi.URLParam(r, "code")))
+	if err != nil || !room.Private {
+		writeRoomError(w, ErrInvalidInviteCode)
+		return nil, false
+	}
+	if !room.IsHost(bearer(r)) {
+		writeRoomError(w, ErrNotHost)
+		return nil, false
+	}
+
+	return room, true
+}
+
+// bearer - The token from the Authorization header
+func bearer(r *http.Request) string {
+	return strings.TrimPrefix(r.Header.Get("Authorization"), "Bearer ")
+}
+
+func decodeRoomRequest(w http.ResponseWriter, r *http.Request, request interface{}) bool {
+	r.Body = http.MaxBytesReader(w, r.Body, MaxJoinBodySize)
+	if err := json.NewDecoder(r.Body).Decode(request); err != nil {
+		log.Printf("Room - Error decoding json %s", err.Error())
+		problem.Write(w, problem.InvalidJSON(err))
+		return false
+	}
+
+	return true
+}
+
+func writeRoomError(w http.ResponseWriter, err error) {
+	log.Printf("Room Error: %s", err.Error())
+	problem.Write(w, err)
+}
+
+func writeRoomResponse(w http.ResponseWriter, status int, response RoomResponse) {
+	response.Status = status
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(response)
+}
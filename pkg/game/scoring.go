@@ -0,0 +1,182 @@
+package game
+
+import (
+	"net/http"
+	"sort"
+
+	"networkgaming.co.uk/techtest/pkg/problem"
+)
+
+// Outcome - What a player's new score means for the game
+type Outcome int
+
+// Outcomes
+const (
+	OutcomeNone Outcome = 0
+	OutcomeWin  Outcome = 1
+	OutcomeBust Outcome = 2
+)
+
+var (
+	ErrUnknownScoring = problem.New("unknown_scoring", http.StatusUnprocessableEntity, "Invalid scoring: No scoring strategy with that name")
+)
+
+// ScoringStrategy - Decides how each drawn number changes a player's score
+type ScoringStrategy interface {
+	// Points - Score change for the player when number is drawn in round (0 based)
+	Points(player GamePlayer, number int, round int) int
+	// Check - Whether the player's updated score wins or busts them
+	Check(player GamePlayer) Outcome
+}
+
+// scoringStrategies - Named strategies so a game's rules can be picked from config
+var scoringStrategies = map[string]func() ScoringStrategy{
+	"classic":     func() ScoringStrategy { return NewClassicScoring() },
+	"distance":    func() ScoringStrategy { return NewDistanceScoring() },
+	"progressive": func() ScoringStrategy { return NewProgressiveScoring(NewClassicScoring(), 10) },
+	"blackjack":   func() ScoringStrategy { return NewBlackJackScoring() },
+	"streak":      func() ScoringStrategy { return NewStreakScoring(NewClassicScoring(), 1) },
+}
+
+// NewScoringStrategy - Looks up a scoring strategy by name
+func NewScoringStrategy(name string) (ScoringStrategy, error) {
+	build, ok := scoringStrategies[name]
+	if !ok {
+		return nil, ErrUnknownScoring
+	}
+
+	return build(), nil
+}
+
+// ScoringStrategyNames - Sorted names accepted by NewScoringStrategy
+func ScoringStrategyNames() []string {
+	names := make([]string, 0, len(scoringStrategies))
+	for name := range scoringStrategies {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	return names
+}
+
+// ClassicScoring - The original rules.
+// Exact match on a pick +5, inside bounds 5 - width, outside -1, exactly 21 wins
+type ClassicScoring struct{}
+
+// NewClassicScoring - Default scoring for a new game
+func NewClassicScoring() *ClassicScoring {
+	return &ClassicScoring{}
+}
+
+// Points - See ScoringStrategy
+func (cs *ClassicScoring) Points(player GamePlayer, number int, round int) int {
+	if player.HasPick(number) {
+		return ExactMatchScore
+	} else if number <= player.Upper && number >= player.Lower {
+		return InsideBoundsScore - (player.Upper - player.Lower)
+	}
+
+	return OutOfBoundsScore
+}
+
+// Check - Rogue win on exactly BlackJack
+func (cs *ClassicScoring) Check(player GamePlayer) Outcome {
+	if player.Score == BlackJack {
+		return OutcomeWin
+	}
+
+	return OutcomeNone
+}
+
+// DistanceScoring - Like classic, but a miss costs the distance to the nearest bound
+type DistanceScoring struct {
+	ClassicScoring
+}
+
+// NewDistanceScoring - Misses are punished by how far out they were
+func NewDistanceScoring() *DistanceScoring {
+	return &DistanceScoring{}
+}
+
+// Points - See ScoringStrategy
+func (ds *DistanceScoring) Points(player GamePlayer, number int, round int) int {
+	if number > player.Upper {
+		return player.Upper - number
+	}
+	if number < player.Lower {
+		return number - player.Lower
+	}
+
+	return ds.ClassicScoring.Points(player, number, round)
+}
+
+// ProgressiveScoring - Multiplies another strategy's points as the rounds go on.
+// Rounds 0 to Step-1 score x1, Step to 2*Step-1 score x2, and so on
+type ProgressiveScoring struct {
+	Base ScoringStrategy
+	Step int
+}
+
+// NewProgressiveScoring - Wrap base so the multiplier grows every step rounds
+func NewProgressiveScoring(base ScoringStrategy, step int) *ProgressiveScoring {
+	if step < 1 {
+		step = 1
+	}
+
+	return &ProgressiveScoring{base, step}
+}
+
+// Points - See ScoringStrategy
+func (ps *ProgressiveScoring) Points(player GamePlayer, number int, round int) int {
+	return ps.Base.Points(player, number, round) * (1 + round/ps.Step)
+}
+
+// Check - Defers to the base strategy
+func (ps *ProgressiveScoring) Check(player GamePlayer) Outcome {
+	return ps.Base.Check(player)
+}
+
+// BlackJackScoring - Classic points, but going over 21 busts the player
+type BlackJackScoring struct {
+	ClassicScoring
+}
+
+// NewBlackJackScoring - Bust over 21 rules
+func NewBlackJackScoring() *BlackJackScoring {
+	return &BlackJackScoring{}
+}
+
+// Check - Exactly BlackJack wins, over BlackJack busts
+func (bs *BlackJackScoring) Check(player GamePlayer) Outcome {
+	if player.Score > BlackJack {
+		return OutcomeBust
+	}
+
+	return bs.ClassicScoring.Check(player)
+}
+
+// StreakScoring - Adds a bonus for each consecutive scoring round
+type StreakScoring struct {
+	Base  ScoringStrategy
+	Bonus int
+}
+
+// NewStreakScoring - Wrap base so scoring streaks earn bonus per round in the streak
+func NewStreakScoring(base ScoringStrategy, bonus int) *StreakScoring {
+	return &StreakScoring{base, bonus}
+}
+
+// Points - See ScoringStrategy
+func (ss *StreakScoring) Points(player GamePlayer, number int, round int) int {
+	points := ss.Base.Points(player, number, round)
+	if points > 0 {
+		points += ss.Bonus * player.Streak
+	}
+
+	return points
+}
+
+// Check - Defers to the base strategy
+func (ss *StreakScoring) Check(player GamePlayer) Outcome {
+	return ss.Base.Check(player)
+}
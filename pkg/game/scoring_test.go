@@ -0,0 +1,116 @@
+package game
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestClassicScoring(t *testing.T) {
+	assert := assert.New(t)
+
+	scoring := NewClassicScoring()
+	player := GamePlayer{Name: "Steve", Upper: 8, Lower: 3, Picks: []int{3, 8}}
+
+	assert.Equal(ExactMatchScore, scoring.Points(player, 8, 0))
+	assert.Equal(ExactMatchScore, scoring.Points(player, 3, 0))
+	assert.Equal(InsideBoundsScore-5, scoring.Points(player, 5, 0))
+	assert.Equal(OutOfBoundsScore, scoring.Points(player, 10, 0))
+
+	player.Score = BlackJack
+	assert.Equal(OutcomeWin, scoring.Check(player))
+	player.Score = BlackJack + 1
+	assert.Equal(OutcomeNone, scoring.Check(player))
+}
+
+func TestDistanceScoring(t *testing.T) {
+	assert := assert.New(t)
+
+	scoring := NewDistanceScoring()
+	player := GamePlayer{Name: "Steve", Upper: 6, Lower: 4, Picks: []int{4, 6}}
+
+	assert.Equal(-4, scoring.Points(player, 10, 0))
+	assert.Equal(-3, scoring.Points(player, 1, 0))
+	assert.Equal(ExactMatchScore, scoring.Points(player, 6, 0))
+	assert.Equal(InsideBoundsScore-2, scoring.Points(player, 5, 0))
+}
+
+func TestProgressiveScoring(t *testing.T) {
+	assert := assert.New(t)
+
+	scoring := NewProgressiveScoring(NewClassicScoring(), 10)
+	player := GamePlayer{Name: "Steve", Upper: 6, Lower: 4, Picks: []int{4, 6}}
+
+	assert.Equal(ExactMatchScore, scoring.Points(player, 6, 0))
+	assert.Equal(ExactMatchScore, scoring.Points(player, 6, 9))
+	assert.Equal(ExactMatchScore*2, scoring.Points(player, 6, 10))
+	assert.Equal(OutOfBoundsScore*3, scoring.Points(player, 1, 25))
+}
+
+func TestBlackJackScoringBusts(t *testing.T) {
+	assert := assert.New(t)
+	seq := []int{5, 5, 5, 5, 5, 1}
+	gen := NewSSNG(seq)
+
+	game := NewGame(gen)
+	game.Scoring = NewBlackJackScoring()
+	game.RegisterPlayer(&Player{Name: "PlayerA", First: 5, Second: 5})
+	game.RegisterPlayer(&Player{Name: "PlayerB", First: 1, Second: 10})
+	game.AddWaitingPlayersToGame()
+	game.Start()
+
+	// PlayerA goes 5, 10, 15, 20, 25 and busts
+	for i := 0; i < 5; i++ {
+		game.PlayRound()
+	}
+	assert.True(game.Players["PlayerA"].Bust)
+	assert.False(game.Players["PlayerB"].Bust)
+	assert.Equal(game.Players["PlayerB"].Score, game.TopScore)
+
+	// Bust players no longer score
+	game.PlayRound()
+	assert.Equal(25, game.Players["PlayerA"].Score)
+
+	winner, err := game.NominateWinner()
+	assert.Nil(err)
+	assert.Equal("PlayerB", winner.Name)
+}
+
+func TestStreakScoring(t *testing.T) {
+	assert := assert.New(t)
+	seq := []int{5, 5, 5, 1, 5}
+	gen := NewSSNG(seq)
+
+	game := NewGame(gen)
+	game.Scoring = NewStreakScoring(NewClassicScoring(), 1)
+	game.RegisterPlayer(&Player{Name: "Sarah", First: 2, Second: 3})
+	game.RegisterPlayer(&Player{Name: "Steve", First: 5, Second: 5})
+	game.AddWaitingPlayersToGame()
+	game.Start()
+
+	// 5, 5+1, 5+2, -1, 5
+	game.PlayRound()
+	assert.Equal(5, game.Players["Steve"].Score)
+	game.PlayRound()
+	assert.Equal(11, game.Players["Steve"].Score)
+	game.PlayRound()
+	assert.Equal(18, game.Players["Steve"].Score)
+	game.PlayRound()
+	assert.Equal(17, game.Players["Steve"].Score)
+	assert.Equal(0, game.Players["Steve"].Streak)
+	game.PlayRound()
+	assert.Equal(22, game.Players["Steve"].Score)
+}
+
+func TestNewScoringStrategy(t *testing.T) {
+	assert := assert.New(t)
+
+	for _, name := range ScoringStrategyNames() {
+		scoring, err := NewScoringStrategy(name)
+		assert.Nil(err)
+		assert.NotNil(scoring)
+	}
+
+	_, err := NewScoringStrategy("nope")
+	assert.Equal(ErrUnknownScoring, err)
+}
@@ -0,0 +1,99 @@
+package game
+
+import (
+	"fmt"
+	"net/http"
+
+	"networkgaming.co.uk/techtest/pkg/problem"
+)
+
+const (
+	DefaultSeats        = 10
+	MinSeats            = MinPlayersRequired
+	MaxSeats            = 50
+	DefaultWaitlistSize = 10
+)
+
+var (
+	ErrRoomFull     = problem.New("room_full", http.StatusConflict, "Room full: Every seat and waitlist place is taken")
+	ErrInvalidSeats = problem.New("invalid_seats", http.StatusUnprocessableEntity, fmt.Sprintf("Invalid settings: Seats must be between %d - %d", MinSeats, MaxSeats))
+)
+
+// WaitlistEntry - A player waiting for a seat, first in line is position 1
+type WaitlistEntry struct {
+	Name     string `json:"name"`
+	Position int    `json:"position"`
+}
+
+// Waitlist - Players registered who won't fit at the table when the waiting room is next seated
+func (g *Game) Waitlist() []WaitlistEntry {
+	waitlist := []WaitlistEntry{}
+	for i, player := range g.waitingRoom[g.free(len(g.waitingRoom)):] {
+		waitlist = append(waitlist, WaitlistEntry{Name: player.Name, Position: i + 1})
+	}
+
+	return waitlist
+}
+
+// Vacant - No one but bots is seated or waiting
+func (g *Game) Vacant() bool {
+	for _, player := range g.Players {
+		if !player.Bot {
+			return false
+		}
+	}
+	for _, player := range g.waitingRoom {
+		if !player.Bot {
+			return false
+		}
+	}
+
+	return true
+}
+
+// checkSeats - Is there a seat or a waitlist place left
+func (g *Game) checkSeats() error {
+	if len(g.Players)+len(g.waitingRoom) >= g.Seats+g.WaitlistSize {
+		return ErrRoomFull
+	}
+
+	return nil
+}
+
+// free - Seats left at the table, at most n
+func (g *Game) free(n int) int {
+	free := g.Seats - len(g.Players)
+	if free < 0 {
+		return 0
+	}
+	if free > n {
+		return n
+	}
+
+	return free
+}
+
+// seat - Seat whoever is waiting, letting the room know who joined and where the waitlist stands
+func (eng *Engine) seat() []*Event {
+	events := []*Event{}
+	joined, _ := eng.Game.AddWaitingPlayersToGame()
+	if len(joined) > 0 {
+		events = append(events, NewEvent(PlayerJoined, joined))
+		if waitlist := eng.Game.Waitlist(); len(waitlist) > 0 {
+			events = append(events, NewEvent(WaitlistUpdated, waitlist))
+		}
+	}
+
+	return events
+}
+
+// position - Where name is on the waitlist, zero if they'll be seated
+func (eng *Engine) position(name string) int {
+	for _, entry := range eng.Game.Waitlist() {
+		if entry.Name == name {
+			return entry.Position
+		}
+	}
+
+	return 0
+}
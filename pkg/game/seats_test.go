@@ -0,0 +1,85 @@
+package game
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWaitlist(t *testing.T) {
+	assert := assert.New(t)
+
+	game := NewGame(NewRNG(MaxNum))
+	game.Seats = 2
+	game.WaitlistSize = 2
+	game.RegisterPlayer(&Player{Name: "Steve", First: 3, Second: 7})
+	game.RegisterPlayer(&Player{Name: "Sarah", First: 4, Second: 8})
+	game.RegisterPlayer(&Player{Name: "Simon", First: 2, Second: 9})
+	assert.Equal([]WaitlistEntry{{Name: "Simon", Position: 1}}, game.Waitlist())
+
+	joined, _ := game.AddWaitingPlayersToGame()
+	assert.Len(joined, 2)
+	assert.Len(game.Players, 2)
+	assert.Equal([]WaitlistEntry{{Name: "Simon", Position: 1}}, game.Waitlist())
+
+	game.RegisterPlayer(&Player{Name: "Stan", First: 2, Second: 9})
+	assert.Equal(ErrRoomFull, game.ValidatePlayer(&Player{Name: "Sue", First: 2, Second: 9}))
+	assert.Equal(ErrRoomFull, game.RegisterPlayer(&Player{Name: "Sue", First: 2, Second: 9}))
+
+	// A seat frees up for the first in line
+	game.RemovePlayer("Sarah")
+	assert.Equal([]WaitlistEntry{{Name: "Stan", Position: 1}}, game.Waitlist())
+	joined, _ = game.AddWaitingPlayersToGame()
+	assert.Len(joined, 1)
+	assert.Equal("Simon", joined[0].Name)
+	assert.Nil(game.RegisterPlayer(&Player{Name: "Sue", First: 2, Second: 9}))
+	assert.Equal([]WaitlistEntry{{Name: "Stan", Position: 1}, {Name: "Sue", Position: 2}}, game.Waitlist())
+
+	assert.Equal(ErrInvalidSeats, game.Configure(&RoomSettings{Seats: MaxSeats + 1}))
+	assert.Nil(game.Configure(&RoomSettings{Seats: 3}))
+	assert.Equal([]WaitlistEntry{{Name: "Sue", Position: 1}}, game.Waitlist())
+}
+
+func TestLobbyPlaceSpillsOver(t *testing.T) {
+	assert := assert.New(t)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	lobby := newTestLobby(ctx)
+	lobby.Setup = func(room *Room) {
+		room.Game.Seats = 2
+		room.Game.WaitlistSize = 1
+	}
+	lobby.Open(MainRoom)
+
+	room, position, err := lobby.Place(ctx, MainRoom, &Player{Name: "Steve", First: 3, Second: 7})
+	assert.Nil(err)
+	assert.Equal(MainRoom, room.ID)
+	assert.Equal(0, position)
+	lobby.Place(ctx, MainRoom, &Player{Name: "Sarah", First: 4, Second: 8})
+
+	room, position, err = lobby.Place(ctx, MainRoom, &Player{Name: "Simon", First: 2, Second: 9})
+	assert.Nil(err)
+	assert.Equal(MainRoom, room.ID)
+	assert.Equal(1, position)
+
+	// Full, so on to a new room, and the same one after that
+	room, position, err = lobby.Place(ctx, MainRoom, &Player{Name: "Stan", First: 2, Second: 9})
+	assert.Nil(err)
+	assert.NotEqual(MainRoom, room.ID)
+	assert.Equal(0, position)
+	spill, _, _ := lobby.Place(ctx, MainRoom, &Player{Name: "Sue", First: 2, Second: 9})
+	assert.Equal(room.ID, spill.ID)
+	assert.Len(lobby.Rooms(), 2)
+
+	// Private rooms don't spill over
+	private, _, _ := lobby.OpenPrivate("")
+	for _, name := range []string{"Ann", "Bob", "Cat"} {
+		_, _, err = lobby.Place(ctx, private.ID, &Player{Name: name, First: 2, Second: 9})
+		assert.Nil(err)
+	}
+	room, _, err = lobby.Place(ctx, private.ID, &Player{Name: "Dan", First: 2, Second: 9})
+	assert.Equal(ErrRoomFull, err)
+	assert.Equal(private.ID, room.ID)
+}
@@ -0,0 +1,122 @@
+package game
+
+import (
+	"fmt"
+	"net/http"
+
+	"networkgaming.co.uk/techtest/pkg/names"
+	"networkgaming.co.uk/techtest/pkg/problem"
+)
+
+const (
+	MinWaitingCount = 1
+	MaxWaitingCount = 60
+)
+
+var (
+	ErrInvalidWaitingCount = problem.New("invalid_waiting_count", http.StatusUnprocessableEntity, fmt.Sprintf("Invalid settings: Countdown must be between %d - %d ticks", MinWaitingCount, MaxWaitingCount))
+	ErrInvalidPicksSetting = problem.New("invalid_picks_setting", http.StatusUnprocessableEntity, fmt.Sprintf("Invalid settings: Picks per player must be between %d - %d", MinPicksPerPlayer, MaxPicksPerPlayer))
+	ErrPicksLocked         = problem.New("picks_locked", http.StatusConflict, "Invalid settings: Picks per player can't change once players have joined")
+)
+
+// RoomSettings - Rules a room's host may change between games, zero values are left as they are
+type RoomSettings struct {
+	WaitingCount   int    `json:"waiting_count,omitempty"`
+	Scoring        string `json:"scoring,omitempty"`
+	Condition      string `json:"condition,omitempty"`
+	PicksPerPlayer int    `json:"picks_per_player,omitempty"`
+	Seats          int    `json:"seats,omitempty"`
+	AllowAdjust    *bool  `json:"allow_adjust,omitempty"`
+}
+
+// validate - Check every setting given is within bounds
+func (s *RoomSettings) validate() error {
+	if s.WaitingCount != 0 && (s.WaitingCount < MinWaitingCount || s.WaitingCount > MaxWaitingCount) {
+		return ErrInvalidWaitingCount
+	}
+	if s.PicksPerPlayer != 0 && (s.PicksPerPlayer < MinPicksPerPlayer || s.PicksPerPlayer > MaxPicksPerPlayer) {
+		return ErrInvalidPicksSetting
+	}
+	if s.Seats != 0 && (s.Seats < MinSeats || s.Seats > MaxSeats) {
+		return ErrInvalidSeats
+	}
+	if s.Scoring != "" {
+		if _, err := NewScoringStrategy(s.Scoring); err != nil {
+			return err
+		}
+	}
+	if s.Condition != "" {
+		if _, err := NewWinCondition(s.Condition); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// Configure - Change the game's rules, only while no game is being played.
+// The countdown is the engine's to apply.
+func (g *Game) Configure(settings *RoomSettings) error {
+
+	if g.state == GameStateInProgress {
+		return ErrGameInProgress
+	}
+	if g.state == GameStateCompleted {
+		return ErrGameComplete
+	}
+	if err := settings.validate(); err != nil {
+		return err
+	}
+	if settings.PicksPerPlayer != 0 && settings.PicksPerPlayer != g.PicksPerPlayer && len(g.registered) > 0 {
+		return ErrPicksLocked
+	}
+
+	g.record(DomainEvent{Type: DomainSettingsChanged, Settings: settings})
+
+	return nil
+}
+
+// configure - Apply settings already validated by Configure
+func (g *Game) configure(settings *RoomSettings) {
+	if settings.Scoring != "" {
+		g.Scoring, _ = NewScoringStrategy(settings.Scoring)
+		g.scoring = settings.Scoring
+	}
+	if settings.Condition != "" {
+		g.Condition, _ = NewWinCondition(settings.Condition)
+		g.condition = settings.Condition
+	}
+	if settings.PicksPerPlayer != 0 {
+		g.PicksPerPlayer = settings.PicksPerPlayer
+	}
+	if settings.Seats != 0 {
+		g.Seats = settings.Seats
+	}
+	if settings.AllowAdjust != nil {
+		g.AllowAdjust = *settings.AllowAdjust
+	}
+}
+
+// RemovePlayer - Take a player out of the game and the waiting room.
+// If too few players are left mid game, it's over.
+func (g *Game) RemovePlayer(name string) error {
+
+	registered, exists := g.registered[names.Skeleton(name)]
+	if !exists {
+		return ErrPlayerNotFound
+	}
+	g.release(registered.Name)
+
+	switch g.state {
+	case GameStateReady:
+		if len(g.Players) < MinPlayersRequired {
+			g.transition(GameStateWaiting)
+		}
+	case GameStateInProgress:
+		if len(g.standing()) < MinPlayersRequired {
+			g.transition(GameStateCompleted)
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,64 @@
+package game
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestConfigure(t *testing.T) {
+	assert := assert.New(t)
+
+	game := NewGame(NewRNG(MaxNum))
+	allow := true
+	assert.Nil(game.Configure(&RoomSettings{Scoring: "blackjack", Condition: "first-to", PicksPerPlayer: 3, AllowAdjust: &allow}))
+	assert.IsType(&BlackJackScoring{}, game.Scoring)
+	assert.IsType(&FirstToCondition{}, game.Condition)
+	assert.Equal(3, game.PicksPerPlayer)
+	assert.True(game.AllowAdjust)
+
+	assert.Equal(ErrUnknownScoring, game.Configure(&RoomSettings{Scoring: "golf"}))
+	assert.Equal(ErrUnknownWinCondition, game.Configure(&RoomSettings{Condition: "golf"}))
+	assert.Equal(ErrInvalidWaitingCount, game.Configure(&RoomSettings{WaitingCount: MaxWaitingCount + 1}))
+	assert.Equal(ErrInvalidPicksSetting, game.Configure(&RoomSettings{PicksPerPlayer: MaxPicksPerPlayer + 1}))
+
+	game.RegisterPlayer(&Player{Name: "Steve", Picks: []int{1, 2, 3}})
+	assert.Equal(ErrPicksLocked, game.Configure(&RoomSettings{PicksPerPlayer: 2}))
+	assert.Nil(game.Configure(&RoomSettings{PicksPerPlayer: 3}))
+
+	game.RegisterPlayer(&Player{Name: "Sarah", Picks: []int{4, 5, 6}})
+	game.AddWaitingPlayersToGame()
+	game.Start()
+	assert.Equal(ErrGameInProgress, game.Configure(&RoomSettings{Scoring: "classic"}))
+}
+
+func TestRemovePlayer(t *testing.T) {
+	assert := assert.New(t)
+
+	game := NewGame(NewRNG(MaxNum))
+	game.RegisterPlayer(&Player{Name: "Steve", First: 3, Second: 7})
+	game.RegisterPlayer(&Player{Name: "Sarah", First: 4, Second: 8})
+	game.RegisterPlayer(&Player{Name: "Simon", First: 2, Second: 9})
+	game.AddWaitingPlayersToGame()
+	game.RegisterPlayer(&Player{Name: "Stan", First: 2, Second: 9})
+
+	assert.Equal(ErrPlayerNotFound, game.RemovePlayer("Nobody"))
+
+	// Still waiting to be seated
+	assert.Nil(game.RemovePlayer("Stan"))
+	joined, _ := game.AddWaitingPlayersToGame()
+	assert.Empty(joined)
+	assert.Nil(game.CheckPlayerExists("Stan"))
+
+	game.Start()
+	assert.Nil(game.RemovePlayer("Simon"))
+	assert.Len(game.Players, 2)
+	assert.Equal(GameStateInProgress, game.GetState())
+
+	// Not enough left to play on
+	assert.Nil(game.RemovePlayer("Sarah"))
+	assert.Equal(GameStateCompleted, game.GetState())
+	winner, err := game.NominateWinner()
+	assert.Nil(err)
+	assert.Equal("Steve", winner.Name)
+}
@@ -0,0 +1,141 @@
+package game
+
+import (
+	"context"
+	"log"
+	"net/http"
+	"sync"
+	"time"
+
+	"networkgaming.co.uk/techtest/pkg/problem"
+)
+
+const (
+	// ShutdownReason - Sent to subscribers when the server goes down
+	ShutdownReason = "Server shutting down"
+)
+
+var (
+	ErrShuttingDown = problem.New("shutting_down", http.StatusServiceUnavailable, "Unavailable: The server is shutting down, no new games are being started")
+)
+
+// ShutdownNotice - Finishing is set when the game in play will be played out
+// before the server goes down, the event's deadline is when it goes down regardless
+type ShutdownNotice struct {
+	Reason    string `json:"reason"`
+	Finishing bool   `json:"finishing"`
+	deadline  time.Time
+}
+
+// Drain - Turn new players away and tell subscribers the server is going down.
+// When finish is set, wait for the game in play to finish or ctx to be done, ctx's
+// deadline being when subscribers are told it goes down regardless.
+// The engine keeps running until it's stopped.
+func (eng *Engine) Drain(ctx context.Context, reason string, finish bool) error {
+	notice := &ShutdownNotice{Reason: reason, Finishing: finish}
+	if deadline, ok := ctx.Deadline(); ok {
+		notice.deadline = deadline
+	}
+	// Not ctx, subscribers are told even when there's no time to wait
+	if _, err := eng.Do(context.Background(), &Action{Type: ActionTypeDrain, Notice: notice}); err != nil {
+		return err
+	}
+
+	eng.mu.Lock()
+	drained, done := eng.drained, eng.done
+	eng.mu.Unlock()
+	select {
+	case <-drained:
+		return nil
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// drain - Start turning players away, returning the notice for subscribers
+func (eng *Engine) drain(notice *ShutdownNotice) *Event {
+	eng.mu.Lock()
+	if !eng.draining {
+		eng.drained = make(chan struct{})
+	}
+	eng.draining = true
+	eng.finish = notice.Finishing
+	eng.mu.Unlock()
+
+	notice.Finishing = notice.Finishing && eng.inPlay()
+	event := NewEvent(ServerShuttingDown, notice)
+	if !notice.deadline.IsZero() {
+		event.Until(notice.deadline)
+	}
+	eng.settle()
+
+	return event
+}
+
+// settle - Let Drain know once there's no game left to finish
+func (eng *Engine) settle() {
+	eng.mu.Lock()
+	defer eng.mu.Unlock()
+
+	if !eng.draining || (eng.finish && eng.inPlay()) {
+		return
+	}
+	select {
+	case <-eng.drained:
+	default:
+		close(eng.drained)
+	}
+}
+
+// refuse - Actions turned away while draining
+func (eng *Engine) refuse(action *Action) bool {
+	if !eng.draining {
+		return false
+	}
+	switch action.Type {
+	case ActionTypeJoinGame, ActionTypeAddBot, ActionTypeStartGame:
+		return true
+	}
+
+	return false
+}
+
+// inPlay - A game has started and hasn't been paid out yet
+func (eng *Engine) inPlay() bool {
+	state := eng.Game.GetState()
+
+	return state == GameStateInProgress || state == GameStateCompleted
+}
+
+// Shutdown - Drain every room and let games in play finish, if finish is set,
+// until ctx is done. Then stop each engine, checkpointing any game still in play,
+// and hang up on every subscriber with reason. No rooms are opened once it's called.
+func (l *Lobby) Shutdown(ctx context.Context, reason string, finish bool) error {
+	l.mu.Lock()
+	l.draining = true
+	rooms := make([]*Room, 0, len(l.rooms))
+	for id, room := range l.rooms {
+		rooms = append(rooms, room)
+		delete(l.rooms, id)
+	}
+	l.mu.Unlock()
+
+	var wg sync.WaitGroup
+	for _, room := range rooms {
+		wg.Add(1)
+		go func(room *Room) {
+			defer wg.Done()
+			if err := room.Engine.Drain(ctx, reason, finish); err != nil {
+				log.Printf("Room %s - Game didn't finish in time: %s\n", room.ID, err.Error())
+			}
+			room.Engine.Stop(context.Background())
+			room.Broadcaster.Close(reason)
+			room.cancel()
+		}(room)
+	}
+	wg.Wait()
+
+	return ctx.Err()
+}
@@ -0,0 +1,160 @@
+package game
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gorilla/websocket"
+	"github.com/stretchr/testify/assert"
+)
+
+// startedEngine - Steve and Sarah playing on a fake clock, with every event buffered
+func startedEngine() (*Engine, *FakeClock, chan *Event) {
+	clock := NewFakeClock(time.Now())
+	game := NewGame(NewRNG(MaxNum))
+	game.Clock = clock
+	engine := NewEngine(game, &EngineConfig{
+		GameSpeed:    1 * time.Second,
+		WaitingCount: 1,
+	})
+	engine.Room = MainRoom
+	engine.Clock = clock
+	engine.Start(context.Background())
+
+	events := make(chan *Event, 1000)
+	go func() {
+		for event := range engine.Event {
+			events <- event
+		}
+	}()
+	ctx := context.Background()
+	engine.Do(ctx, &Action{Type: ActionTypeJoinGame, Player: &Player{Name: "Steve", First: 3, Second: 7}})
+	engine.Do(ctx, &Action{Type: ActionTypeJoinGame, Player: &Player{Name: "Sarah", First: 4, Second: 8}})
+	// Seated, counted down and started
+	clock.Advance(3 * time.Second)
+	next(events, GameStarted)
+
+	return engine, clock, events
+}
+
+// next - The next event of eventType
+func next(events chan *Event, eventType EventType) *Event {
+	for event := range events {
+		if event.Type == eventType.String() {
+			return event
+		}
+	}
+
+	return nil
+}
+
+func TestEngineDrainFinishesGame(t *testing.T) {
+	assert := assert.New(t)
+	engine, clock, events := startedEngine()
+	checkpoints := NewFileCheckpoints(t.TempDir())
+	engine.Checkpoints = checkpoints
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+	defer cancel()
+	drained := make(chan error)
+	go func() {
+		drained <- engine.Drain(ctx, ShutdownReason, true)
+	}()
+	event := next(events, ServerShuttingDown)
+	assert.True(event.Data.(*ShutdownNotice).Finishing)
+	assert.NotNil(event.Deadline)
+
+	_, err := engine.Do(ctx, &Action{Type: ActionTypeJoinGame, Player: &Player{Name: "Simon", First: 2, Second: 9}})
+	assert.Equal(ErrShuttingDown, err)
+
+	// Played out to the end
+	for finished := false; !finished; {
+		clock.Advance(1 * time.Second)
+		select {
+		case err = <-drained:
+			finished = true
+		default:
+		}
+	}
+	assert.Nil(err)
+	assert.NotNil(next(events, IntermissionStarted))
+	assert.Nil(engine.Stop(ctx))
+
+	// Nothing left in play to restore
+	checkpoint, err := checkpoints.Load(MainRoom)
+	assert.Nil(err)
+	assert.True(checkpoint.Settled)
+}
+
+func TestEngineDrainCheckpoints(t *testing.T) {
+	assert := assert.New(t)
+	engine, _, events := startedEngine()
+	checkpoints := NewFileCheckpoints(t.TempDir())
+	engine.Checkpoints = checkpoints
+
+	ctx := context.Background()
+	assert.Nil(engine.Drain(ctx, ShutdownReason, false))
+	assert.False(next(events, ServerShuttingDown).Data.(*ShutdownNotice).Finishing)
+	assert.Nil(engine.Stop(ctx))
+
+	data, err := os.ReadFile(filepath.Join(checkpoints.Dir, MainRoom+".json"))
+	assert.Nil(err)
+	checkpoint := Checkpoint{}
+	assert.Nil(json.Unmarshal(data, &checkpoint))
+	assert.Equal(MainRoom, checkpoint.Room)
+	assert.Equal(GameStateInProgress, checkpoint.State)
+	assert.Len(checkpoint.Game.Players, 2)
+	// Left for the next start to restore
+	assert.False(checkpoint.Settled)
+}
+
+func TestBroadcasterClose(t *testing.T) {
+	assert := assert.New(t)
+	broadcaster := NewBroadcaster(make(chan *Event))
+	broadcaster.Start(context.Background())
+
+	upgrader := websocket.Upgrader{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		sock, err := upgrader.Upgrade(w, r, nil)
+		if err == nil {
+			broadcaster.Subscribe(sock)
+		}
+	}))
+	defer server.Close()
+
+	client, _, err := websocket.DefaultDialer.Dial("ws"+strings.TrimPrefix(server.URL, "http"), nil)
+	assert.Nil(err)
+	defer client.Close()
+	assert.Eventually(func() bool { return broadcaster.Chat(NewEvent(ChatSent, nil)) }, time.Second, time.Millisecond)
+	client.ReadMessage()
+
+	broadcaster.Close("Back soon")
+	_, _, err = client.ReadMessage()
+	closed, ok := err.(*websocket.CloseError)
+	assert.True(ok)
+	assert.Equal(websocket.CloseGoingAway, closed.Code)
+	assert.Equal("Back soon", closed.Text)
+	assert.False(broadcaster.Subscribe(client))
+}
+
+func TestLobbyShutdown(t *testing.T) {
+	assert := assert.New(t)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	lobby := newTestLobby(ctx)
+	main, _ := lobby.Open(MainRoom)
+
+	assert.Nil(lobby.Shutdown(ctx, ShutdownReason, true))
+	assert.False(main.Engine.IsRunning())
+	assert.Empty(lobby.Rooms())
+	_, err := lobby.Open("")
+	assert.Equal(ErrShuttingDown, err)
+	assert.Equal(ErrShuttingDown, lobby.Validate(&Player{Name: "Steve", First: 3, Second: 7}))
+}
@@ -0,0 +1,195 @@
+package game
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"sort"
+	"time"
+)
+
+const (
+	// SimulationRoundLimit - Safety net for rule sets that might never end a game
+	SimulationRoundLimit = MaxRounds * 10
+)
+
+var (
+	ErrNoSimulationGames = errors.New("Invalid simulation: Play at least one game")
+)
+
+// SimulationConfig - One rule set and a table of bots to play it with.
+// Strategies are bot strategy names, one seat each, "fixed:3-7" plays 3 to 7.
+// RoundInterval is the simulated time between rounds, for time limited games.
+type SimulationConfig struct {
+	Games          int
+	Seed           int64
+	Strategies     []string
+	Scoring        string
+	Condition      string
+	PicksPerPlayer int
+	RoundInterval  time.Duration
+}
+
+// StrategyStats - How one strategy fared across every seat it had
+type StrategyStats struct {
+	Strategy   string  `json:"strategy"`
+	Seats      int     `json:"seats"`
+	Wins       int     `json:"wins"`
+	WinRate    float64 `json:"win_rate"`
+	RogueWins  int     `json:"rogue_wins"`
+	MeanScore  float64 `json:"mean_score"`
+	MinScore   int     `json:"min_score"`
+	LowerScore int     `json:"p25_score"`
+	MedScore   int     `json:"median_score"`
+	UpperScore int     `json:"p75_score"`
+	MaxScore   int     `json:"max_score"`
+}
+
+// SimulationResult - Totals for a rule set
+type SimulationResult struct {
+	Scoring    string          `json:"scoring"`
+	Condition  string          `json:"condition"`
+	Games      int             `json:"games"`
+	RogueRate  float64         `json:"rogue_rate"`
+	MeanRounds float64         `json:"mean_rounds"`
+	Strategies []StrategyStats `json:"strategies"`
+}
+
+// Simulate - Play config.Games headless games, no engine or ticker, and total the results.
+// Game i is drawn with seed config.Seed + i so runs are repeatable.
+func Simulate(config SimulationConfig) (SimulationResult, error) {
+	result := SimulationResult{
+		Scoring:   config.Scoring,
+		Condition: config.Condition,
+		Games:     config.Games,
+	}
+	if config.Games < 1 {
+		return result, ErrNoSimulationGames
+	}
+	if config.PicksPerPlayer < 1 {
+		config.PicksPerPlayer = DefaultPicksPerPlayer
+	}
+	if config.RoundInterval <= 0 {
+		config.RoundInterval = time.Second
+	}
+
+	scores := make(map[string][]int)
+	wins := make(map[string]int)
+	rogues := make(map[string]int)
+	rogueGames := 0
+	rounds := 0
+	history := []int{}
+
+	for i := 0; i < config.Games; i++ {
+		seed := config.Seed + int64(i)
+		game, err := newSimulatedGame(config, seed)
+		if err != nil {
+			return result, err
+		}
+		game.history = history
+
+		// Seat the bots
+		seats := make(map[string]string)
+		botRand := NewSeededRNG(MaxNum, -seed)
+		for seat, name := range config.Strategies {
+			strategy, err := NewBotStrategy(name, botRand)
+			if err != nil {
+				return result, err
+			}
+			player := fmt.Sprintf("%s #%d", name, seat+1)
+			if err := game.RegisterBot(player, strategy); err != nil {
+				return result, err
+			}
+			seats[player] = name
+		}
+		// Play it out on a simulated clock
+		clock := NewFakeClock(time.Now())
+		game.Clock = clock
+		game.AddWaitingPlayersToGame()
+		if err := game.Start(); err != nil {
+			return result, err
+		}
+		for game.GetState() == GameStateInProgress && game.Round < SimulationRoundLimit {
+			if err := game.PlayRound(); err != nil {
+				return result, err
+			}
+			clock.Advance(config.RoundInterval)
+		}
+		game.TakeEvents()
+
+		winner, err := game.NominateWinner()
+		if err != nil {
+			return result, err
+		}
+
+		rounds += game.Round
+		wins[seats[winner.Name]]++
+		if game.Rogue {
+			rogueGames++
+			rogues[seats[winner.Name]]++
+		}
+		for name, player := range game.Players {
+			scores[seats[name]] = append(scores[seats[name]], player.Score)
+		}
+		history = game.history
+	}
+
+	result.RogueRate = float64(rogueGames) / float64(config.Games)
+	result.MeanRounds = float64(rounds) / float64(config.Games)
+	for strategy, list := range scores {
+		result.Strategies = append(result.Strategies, strategyStats(strategy, list, wins[strategy], rogues[strategy]))
+	}
+	sort.Slice(result.Strategies, func(i, j int) bool {
+		return result.Strategies[i].Strategy < result.Strategies[j].Strategy
+	})
+
+	return result, nil
+}
+
+// newSimulatedGame - A fresh game with the config's rules
+func newSimulatedGame(config SimulationConfig, seed int64) (*Game, error) {
+	game := NewGame(NewSeededRNG(MaxNum, seed))
+	game.PicksPerPlayer = config.PicksPerPlayer
+
+	if config.Scoring != "" {
+		scoring, err := NewScoringStrategy(config.Scoring)
+		if err != nil {
+			return nil, err
+		}
+		game.Scoring = scoring
+	}
+	if config.Condition != "" {
+		condition, err := NewWinCondition(config.Condition)
+		if err != nil {
+			return nil, err
+		}
+		game.Condition = condition
+	}
+
+	return game, nil
+}
+
+func strategyStats(strategy string, scores []int, wins int, rogues int) StrategyStats {
+	sort.Ints(scores)
+	total := 0
+	for _, score := range scores {
+		total += score
+	}
+	at := func(p float64) int {
+		return scores[int(math.Round(p*float64(len(scores)-1)))]
+	}
+
+	return StrategyStats{
+		Strategy:   strategy,
+		Seats:      len(scores),
+		Wins:       wins,
+		WinRate:    float64(wins) / float64(len(scores)),
+		RogueWins:  rogues,
+		MeanScore:  float64(total) / float64(len(scores)),
+		MinScore:   scores[0],
+		LowerScore: at(0.25),
+		MedScore:   at(0.5),
+		UpperScore: at(0.75),
+		MaxScore:   scores[len(scores)-1],
+	}
+}
@@ -0,0 +1,50 @@
+package game
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSimulate(t *testing.T) {
+	assert := assert.New(t)
+
+	config := SimulationConfig{
+		Games:      200,
+		Seed:       42,
+		Strategies: []string{"wide", "fixed:5-5", "adaptive"},
+		Scoring:    "classic",
+		Condition:  "max-rounds",
+	}
+	result, err := Simulate(config)
+	assert.Nil(err)
+	assert.Equal(200, result.Games)
+	assert.Len(result.Strategies, 3)
+
+	wins := 0
+	for _, stats := range result.Strategies {
+		assert.Equal(200, stats.Seats)
+		assert.True(stats.MinScore <= stats.MedScore && stats.MedScore <= stats.MaxScore)
+		wins += stats.Wins
+	}
+	// One winner a game
+	assert.Equal(200, wins)
+	assert.True(result.MeanRounds > 0 && result.MeanRounds <= MaxRounds)
+
+	// Same seed, same result
+	again, _ := Simulate(config)
+	assert.Equal(result, again)
+}
+
+func TestSimulateErrors(t *testing.T) {
+	assert := assert.New(t)
+
+	_, err := Simulate(SimulationConfig{Games: 0})
+	assert.Equal(ErrNoSimulationGames, err)
+
+	_, err = Simulate(SimulationConfig{Games: 1, Strategies: []string{"wide", "nope"}})
+	assert.Equal(ErrUnknownBotStrategy, err)
+
+	_, err = Simulate(SimulationConfig{Games: 1, Strategies: []string{"wide"}})
+	assert.Equal(ErrNotEnoughPlayers, err)
+}
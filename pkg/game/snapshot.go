@@ -0,0 +1,24 @@
+package game
+
+// Snapshot - Everything a new subscriber needs to catch up, sent before any other event
+type Snapshot struct {
+	Room  string        `json:"room"`
+	State State         `json:"state"`
+	Game  RoundResult   `json:"game"`
+	Chat  []ChatMessage `json:"chat"`
+}
+
+// snapshot - Current state of the engine's game, call from the engine loop
+func (eng *Engine) snapshot() *Snapshot {
+	chat := []ChatMessage{}
+	if eng.Chat != nil {
+		chat = eng.Chat.History()
+	}
+
+	return &Snapshot{
+		Room:  eng.Room,
+		State: eng.Game.GetState(),
+		Game:  eng.Game.GetRoundResult(),
+		Chat:  chat,
+	}
+}
@@ -0,0 +1,48 @@
+package game
+
+import "net/http"
+
+// Stakes - Optional wagering on games, see wallet.Pot
+type Stakes interface {
+	// Stake - Take a player's stake from account as they join
+	Stake(name string, account string) error
+	// Unstake - Hand a stake back when the join fails
+	Unstake(name string) error
+	// Payout - Pay the pot out, tied is everyone staked on the winner's score including winner
+	Payout(winner string, tied []string) error
+	// Refund - Hand every stake back when the game is cancelled
+	Refund() error
+}
+
+// Accounts - Who a wallet token was issued to, see wallet.Wallet
+type Accounts interface {
+	// Authenticate - The account token belongs to
+	Authenticate(token string) (string, error)
+}
+
+// Account - The wallet account behind the request's bearer token, none without accounts
+func Account(accounts Accounts, r *http.Request) (string, error) {
+	if accounts == nil {
+		return "", nil
+	}
+
+	return accounts.Authenticate(bearer(r))
+}
+
+// tiedNames - Names of the winner and everyone still standing on the winner's score,
+// but bots who have nothing staked. A winner who didn't top the board, like a rogue
+// exact match, only shares with players on their own score.
+func tiedNames(result RoundResult, winner GamePlayer) []string {
+	names := []string{}
+	for _, player := range result.LeaderBoard {
+		if player.Bot || player.Score != winner.Score {
+			continue
+		}
+		if player.Name != winner.Name && player.IsOut() {
+			continue
+		}
+		names = append(names, player.Name)
+	}
+
+	return names
+}
@@ -0,0 +1,151 @@
+package game
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+
+	"networkgaming.co.uk/techtest/pkg/problem"
+)
+
+var (
+	ErrIllegalTransition = problem.New("illegal_transition", http.StatusConflict, "Invalid state: The game can't move there from where it is")
+)
+
+// States - Every game state, in the order they're drawn
+var States = []State{
+	GameStateWaiting,
+	GameStateReady,
+	GameStateInProgress,
+	GameStateCompleted,
+	GameStateIntermission,
+	GameStateCancelled,
+}
+
+// transitions - Every move a game may make, and what makes it.
+// Staying put is never a transition and always allowed.
+var transitions = map[State]map[State]string{
+	GameStateWaiting: {
+		GameStateReady:      "GetReady",
+		GameStateInProgress: "Start",
+		GameStateCancelled:  "Cancel",
+	},
+	GameStateReady: {
+		GameStateWaiting:    "RemovePlayer, ExpirePlayers",
+		GameStateInProgress: "Start",
+		GameStateCancelled:  "Cancel",
+	},
+	GameStateInProgress: {
+		GameStateCompleted: "PlayRound, RemovePlayer",
+		GameStateCancelled: "Cancel",
+	},
+	GameStateCompleted: {
+		GameStateIntermission: "Intermission",
+		GameStateWaiting:      "Reset",
+		GameStateCancelled:    "Cancel",
+	},
+	GameStateIntermission: {
+		GameStateWaiting:   "Reset",
+		GameStateCancelled: "Cancel",
+	},
+	GameStateCancelled: {
+		GameStateWaiting: "Reset",
+	},
+}
+
+func (s State) String() string {
+	switch s {
+	case GameStateReady:
+		return "Ready"
+	case GameStateInProgress:
+		return "In Progress"
+	case GameStateCompleted:
+		return "Completed"
+	case GameStateWaiting:
+		return "Waiting"
+	case GameStateCancelled:
+		return "Cancelled"
+	case GameStateIntermission:
+		return "Intermission"
+	}
+
+	return fmt.Sprintf("State %d", int(s))
+}
+
+// TransitionError - A move the transition table doesn't allow, reported as ErrIllegalTransition
+type TransitionError struct {
+	From State
+	To   State
+}
+
+func (e *TransitionError) Error() string {
+	return fmt.Sprintf("Invalid state: The game can't go from %s to %s", e.From, e.To)
+}
+
+// Unwrap - So errors.Is and problem.From see ErrIllegalTransition
+func (e *TransitionError) Unwrap() error {
+	return ErrIllegalTransition
+}
+
+// Transition - Event data when a game changes state
+type Transition struct {
+	From State `json:"from"`
+	To   State `json:"to"`
+}
+
+// CanTransition - Whether a game may move from one state to the other
+func CanTransition(from State, to State) bool {
+	if from == to {
+		return true
+	}
+	_, ok := transitions[from][to]
+
+	return ok
+}
+
+// check - A TransitionError unless the game may move to state
+func (g *Game) check(to State) error {
+	if !CanTransition(g.state, to) {
+		return &TransitionError{From: g.state, To: to}
+	}
+
+	return nil
+}
+
+// StateDiagram - The transition table as a Graphviz digraph
+func StateDiagram() string {
+	var b strings.Builder
+	b.WriteString("digraph game {\n")
+	b.WriteString("  rankdir=LR;\n")
+	for _, from := range States {
+		for _, to := range States {
+			if cause, ok := transitions[from][to]; ok {
+				fmt.Fprintf(&b, "  %q -> %q [label=%q];\n", from.String(), to.String(), cause)
+			}
+		}
+	}
+	b.WriteString("}\n")
+
+	return b.String()
+}
+
+// MermaidDiagram - The transition table as a Mermaid state diagram
+func MermaidDiagram() string {
+	var b strings.Builder
+	b.WriteString("stateDiagram-v2\n")
+	fmt.Fprintf(&b, "  [*] --> %s\n", mermaidID(GameStateWaiting))
+	for _, from := range States {
+		for _, to := range States {
+			if cause, ok := transitions[from][to]; ok {
+				fmt.Fprintf(&b, "  %s --> %s: %s\n", mermaidID(from), mermaidID(to), cause)
+			}
+		}
+	}
+
+	return b.String()
+}
+
+// mermaidID - State names without spaces, as Mermaid wants them
+func mermaidID(s State) string {
+	return strings.ReplaceAll(s.String(), " ", "")
+}
@@ -0,0 +1,93 @@
+package game
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"networkgaming.co.uk/techtest/pkg/problem"
+)
+
+func TestCanTransition(t *testing.T) {
+	assert := assert.New(t)
+
+	assert.True(CanTransition(GameStateWaiting, GameStateReady))
+	assert.True(CanTransition(GameStateInProgress, GameStateCompleted))
+	assert.True(CanTransition(GameStateCancelled, GameStateWaiting))
+	assert.False(CanTransition(GameStateInProgress, GameStateWaiting))
+	assert.False(CanTransition(GameStateCancelled, GameStateInProgress))
+	assert.False(CanTransition(GameStateCompleted, GameStateInProgress))
+	// Staying put is always fine
+	for _, state := range States {
+		assert.True(CanTransition(state, state))
+	}
+}
+
+func TestIllegalTransitions(t *testing.T) {
+	assert := assert.New(t)
+
+	game := NewGame(NewSeededRNG(MaxNum, 3))
+	game.RegisterPlayer(&Player{Name: "Steve", First: 3, Second: 7})
+	game.RegisterPlayer(&Player{Name: "Sarah", First: 4, Second: 8})
+	game.AddWaitingPlayersToGame()
+	assert.Equal(ErrGameNotInProgress, game.PlayRound())
+	assert.Nil(game.Start())
+
+	// Can't clear the table mid game
+	err := game.Reset()
+	assert.True(errors.Is(err, ErrIllegalTransition))
+	assert.Equal(&TransitionError{From: GameStateInProgress, To: GameStateWaiting}, err)
+	assert.Equal("illegal_transition", problem.From(err).Code)
+	assert.Equal(GameStateInProgress, game.GetState())
+
+	// Calling it off is fine, and again changes nothing
+	assert.Nil(game.Cancel())
+	assert.Nil(game.Cancel())
+	assert.True(errors.Is(game.Start(), ErrIllegalTransition))
+	assert.Nil(game.Reset())
+	assert.Equal(GameStateWaiting, game.GetState())
+}
+
+func TestTransitionEvents(t *testing.T) {
+	assert := assert.New(t)
+
+	game := NewGame(NewSeededRNG(MaxNum, 3))
+	game.RegisterPlayer(&Player{Name: "Steve", First: 3, Second: 7})
+	game.RegisterPlayer(&Player{Name: "Sarah", First: 4, Second: 8})
+	game.AddWaitingPlayersToGame()
+	game.GetReady()
+	game.Start()
+	game.Cancel()
+
+	transitions := []Transition{}
+	for _, event := range game.TakeEvents() {
+		if event.Type == StateChanged.String() {
+			transitions = append(transitions, event.Data.(Transition))
+		}
+	}
+	assert.Equal([]Transition{
+		{From: GameStateWaiting, To: GameStateReady},
+		{From: GameStateReady, To: GameStateInProgress},
+		{From: GameStateInProgress, To: GameStateCancelled},
+	}, transitions)
+}
+
+func TestStateDiagrams(t *testing.T) {
+	assert := assert.New(t)
+
+	dot := StateDiagram()
+	assert.True(strings.HasPrefix(dot, "digraph game {"))
+	assert.Contains(dot, `"In Progress" -> "Completed" [label="PlayRound, RemovePlayer"];`)
+
+	mermaid := MermaidDiagram()
+	assert.True(strings.HasPrefix(mermaid, "stateDiagram-v2\n  [*] --> Waiting\n"))
+	assert.Contains(mermaid, "  Cancelled --> Waiting: Reset\n")
+
+	edges := 0
+	for _, to := range transitions {
+		edges += len(to)
+	}
+	assert.Equal(edges, strings.Count(dot, " -> "))
+	assert.Equal(edges+1, strings.Count(mermaid, " --> "))
+}
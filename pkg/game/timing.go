@@ -0,0 +1,74 @@
+package game
+
+import (
+	"math"
+	"time"
+)
+
+const (
+	// MinRoundInterval - RoundAcceleration never draws numbers quicker than this
+	MinRoundInterval = 250 * time.Millisecond
+)
+
+// interval - Time until the next tick for the phase the game is in
+func (eng *Engine) interval() time.Duration {
+	switch eng.Game.GetState() {
+	case GameStateWaiting:
+		return eng.or(eng.Config.WaitingInterval)
+	case GameStateReady:
+		return eng.or(eng.Config.CountdownStep)
+	case GameStateInProgress, GameStateCompleted:
+		return eng.roundInterval()
+	case GameStateIntermission:
+		return eng.or(eng.Config.Intermission)
+	}
+
+	return eng.Config.GameSpeed
+}
+
+// roundInterval - Time until the next round is drawn, shortened for every round played
+// when the game accelerates
+func (eng *Engine) roundInterval() time.Duration {
+	interval := eng.or(eng.Config.RoundInterval)
+	if eng.Config.RoundAcceleration <= 0 || eng.Config.RoundAcceleration >= 1 {
+		return interval
+	}
+
+	floor := MinRoundInterval
+	if interval < floor {
+		floor = interval
+	}
+	round := eng.Game.GetRoundResult().Round
+	accelerated := time.Duration(float64(interval) * math.Pow(1-eng.Config.RoundAcceleration, float64(round)))
+	if accelerated < floor {
+		return floor
+	}
+
+	return accelerated
+}
+
+// or - d, or the GameSpeed if it's not set
+func (eng *Engine) or(d time.Duration) time.Duration {
+	if d > 0 {
+		return d
+	}
+
+	return eng.Config.GameSpeed
+}
+
+// schedule - Time the next tick for the phase the game is now in
+func (eng *Engine) schedule() {
+	if eng.ticker != nil {
+		eng.ticker.Reset(eng.interval())
+	}
+}
+
+// deadline - When the next tick is due
+func (eng *Engine) deadline() time.Time {
+	return eng.Clock.Now().Add(eng.interval())
+}
+
+// countdownDeadline - When the countdown ends and the game starts
+func (eng *Engine) countdownDeadline() time.Time {
+	return eng.Clock.Now().Add(time.Duration(eng.count) * eng.or(eng.Config.CountdownStep))
+}
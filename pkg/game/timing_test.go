@@ -0,0 +1,104 @@
+package game
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRoundInterval(t *testing.T) {
+	assert := assert.New(t)
+
+	mockGame := NewMockGame(10)
+	mockGame.State = GameStateInProgress
+	engine := NewEngine(mockGame, &EngineConfig{
+		GameSpeed:         1 * time.Second,
+		CountdownStep:     2 * time.Second,
+		RoundInterval:     1 * time.Second,
+		RoundAcceleration: 0.5,
+	})
+
+	for _, want := range []time.Duration{time.Second, 500 * time.Millisecond, MinRoundInterval, MinRoundInterval} {
+		assert.Equal(want, engine.interval())
+		mockGame.Round++
+	}
+
+	mockGame.State = GameStateReady
+	assert.Equal(2*time.Second, engine.interval())
+	// Falls back to the GameSpeed
+	mockGame.State = GameStateIntermission
+	assert.Equal(1*time.Second, engine.interval())
+}
+
+func TestEnginePhaseTimings(t *testing.T) {
+	assert := assert.New(t)
+
+	clock := NewFakeClock(time.Now())
+	game := NewGame(NewRNG(MaxNum))
+	game.Clock = clock
+	engine := NewEngine(game, &EngineConfig{
+		GameSpeed:       1 * time.Minute,
+		WaitingCount:    2,
+		WaitingInterval: 1 * time.Second,
+		CountdownStep:   2 * time.Second,
+		RoundInterval:   3 * time.Second,
+		Intermission:    30 * time.Second,
+	})
+	engine.Clock = clock
+	engine.Start(context.Background())
+
+	events := make(chan *Event, 1000)
+	go func() {
+		for event := range engine.Event {
+			events <- event
+		}
+	}()
+	next := func(eventTypes ...EventType) *Event {
+		for event := range events {
+			for _, eventType := range eventTypes {
+				if event.Type == eventType.String() {
+					return event
+				}
+			}
+		}
+		return nil
+	}
+	after := func(d time.Duration) time.Time {
+		return clock.Now().Add(d).UTC()
+	}
+
+	ctx := context.Background()
+	engine.Do(ctx, &Action{Type: ActionTypeJoinGame, Player: &Player{Name: "Steve", First: 3, Second: 7}})
+	engine.Do(ctx, &Action{Type: ActionTypeJoinGame, Player: &Player{Name: "Sarah", First: 4, Second: 8}})
+
+	clock.Advance(1 * time.Second)
+	assert.Nil(next(GameReady).Deadline)
+
+	clock.Advance(2 * time.Second)
+	event := next(CountdownStarted)
+	assert.Equal(after(4*time.Second), *event.Deadline)
+	clock.Advance(2 * time.Second)
+	event = next(CountingDown)
+	assert.Equal(after(2*time.Second), *event.Deadline)
+	clock.Advance(2 * time.Second)
+	event = next(GameStarted)
+	assert.Equal(after(3*time.Second), *event.Deadline)
+
+	for event.Type != GameCompleted.String() {
+		clock.Advance(3 * time.Second)
+		event = next(PlayedRound, GameCompleted)
+	}
+	event = next(IntermissionStarted)
+	assert.Equal(after(30*time.Second), *event.Deadline)
+	assert.Equal(GameStateIntermission, game.GetState())
+	assert.Equal(Transition{From: GameStateCompleted, To: GameStateIntermission}, next(StateChanged).Data)
+
+	// The winner stays up for the whole intermission
+	clock.Advance(29 * time.Second)
+	assert.Len(events, 0)
+	clock.Advance(1 * time.Second)
+	next(GameReset)
+	assert.Equal(GameStateWaiting, game.GetState())
+}
@@ -0,0 +1,201 @@
+package game
+
+import (
+	"net/http"
+	"sort"
+	"time"
+
+	"networkgaming.co.uk/techtest/pkg/problem"
+)
+
+var (
+	ErrUnknownWinCondition = problem.New("unknown_win_condition", http.StatusUnprocessableEntity, "Invalid win condition: No win condition with that name")
+)
+
+// WinCondition - Decides when a game is over.
+// AfterRound is called once each round has been scored (unless a rogue
+// BlackJack already ended the game) and returns whether the game is over,
+// plus any events the round caused for the engine to broadcast.
+type WinCondition interface {
+	AfterRound(g *Game) (bool, []*Event)
+}
+
+// winConditions - Named conditions so a game's rules can be picked from config
+var winConditions = map[string]func() WinCondition{
+	"max-rounds":    func() WinCondition { return NewMaxRoundsCondition(MaxRounds) },
+	"first-to":      func() WinCondition { return NewFirstToCondition(BlackJack, MaxRounds) },
+	"last-standing": func() WinCondition { return NewLastStandingCondition(5, MaxRounds) },
+	"sudden-death":  func() WinCondition { return NewSuddenDeathCondition(NewMaxRoundsCondition(MaxRounds), 10) },
+	"time-limit":    func() WinCondition { return NewTimeLimitCondition(1 * time.Minute) },
+}
+
+// NewWinCondition - Looks up a win condition by name
+func NewWinCondition(name string) (WinCondition, error) {
+	build, ok := winConditions[name]
+	if !ok {
+		return nil, ErrUnknownWinCondition
+	}
+
+	return build(), nil
+}
+
+// WinConditionNames - Sorted names accepted by NewWinCondition
+func WinConditionNames() []string {
+	names := make([]string, 0, len(winConditions))
+	for name := range winConditions {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	return names
+}
+
+// MaxRoundsCondition - The original rule, the game is over after a set number of rounds
+type MaxRoundsCondition struct {
+	Rounds int
+}
+
+// NewMaxRoundsCondition - Default win condition for a new game
+func NewMaxRoundsCondition(rounds int) *MaxRoundsCondition {
+	return &MaxRoundsCondition{rounds}
+}
+
+// AfterRound - See WinCondition
+func (mc *MaxRoundsCondition) AfterRound(g *Game) (bool, []*Event) {
+	return g.Round >= mc.Rounds, nil
+}
+
+// FirstToCondition - First player to reach Target wins outright.
+// Falls back to the top score after Rounds rounds.
+type FirstToCondition struct {
+	Target int
+	Rounds int
+}
+
+// NewFirstToCondition - First to target points, capped at rounds
+func NewFirstToCondition(target int, rounds int) *FirstToCondition {
+	return &FirstToCondition{target, rounds}
+}
+
+// AfterRound - See WinCondition
+func (fc *FirstToCondition) AfterRound(g *Game) (bool, []*Event) {
+	reached := []GamePlayer{}
+	for _, player := range g.Players {
+		if !player.IsOut() && player.Score >= fc.Target {
+			reached = append(reached, player)
+		}
+	}
+	// More than one over the line in the same round is settled by NominateWinner
+	if len(reached) == 1 {
+		g.nominate(reached[0])
+		return true, nil
+	}
+
+	return len(reached) > 1 || g.Round >= fc.Rounds, nil
+}
+
+// LastStandingCondition - Every Every rounds the lowest scorer is eliminated,
+// the last player left wins. Falls back to the top score after Rounds rounds.
+type LastStandingCondition struct {
+	Every  int
+	Rounds int
+}
+
+// NewLastStandingCondition - Eliminate the lowest scorer every k rounds, capped at rounds
+func NewLastStandingCondition(every int, rounds int) *LastStandingCondition {
+	if every < 1 {
+		every = 1
+	}
+
+	return &LastStandingCondition{every, rounds}
+}
+
+// AfterRound - See WinCondition
+func (lc *LastStandingCondition) AfterRound(g *Game) (bool, []*Event) {
+	events := []*Event{}
+
+	if g.Round%lc.Every == 0 {
+		standing := g.standing()
+		if len(standing) > 1 {
+			// Lowest score goes, ties lose on the lowest bounds then reverse alphabetical,
+			// the mirror of NominateWinner
+			sort.Slice(standing, func(i, j int) bool {
+				a, b := standing[i], standing[j]
+				if a.Score != b.Score {
+					return a.Score < b.Score
+				}
+				if a.Upper != b.Upper {
+					return a.Upper < b.Upper
+				}
+				if a.Lower != b.Lower {
+					return a.Lower < b.Lower
+				}
+				return a.Name > b.Name
+			})
+			loser := standing[0]
+			loser.Eliminated = true
+			g.update(loser)
+			events = append(events, NewEvent(PlayerEliminated, loser))
+		}
+	}
+
+	standing := g.standing()
+	if len(standing) == 1 {
+		g.nominate(standing[0])
+		return true, events
+	}
+
+	return g.Round >= lc.Rounds, events
+}
+
+// SuddenDeathCondition - When Base ends the game with the top score tied,
+// keep playing tiebreak rounds until there is a single leader.
+// After MaxExtraRounds the usual NominateWinner tiebreaks apply.
+// The extra rounds are counted from the round the game went to sudden death,
+// so they carry over a checkpoint with the game.
+type SuddenDeathCondition struct {
+	Base           WinCondition
+	MaxExtraRounds int
+}
+
+// NewSuddenDeathCondition - Wrap base with up to maxExtraRounds tiebreak rounds
+func NewSuddenDeathCondition(base WinCondition, maxExtraRounds int) *SuddenDeathCondition {
+	return &SuddenDeathCondition{Base: base, MaxExtraRounds: maxExtraRounds}
+}
+
+// AfterRound - See WinCondition
+func (sc *SuddenDeathCondition) AfterRound(g *Game) (bool, []*Event) {
+	if !g.SuddenDeath {
+		over, events := sc.Base.AfterRound(g)
+		if !over || g.Winner.Name != "" {
+			return over, events
+		}
+		leaders := g.leaders()
+		if len(leaders) < 2 {
+			return true, events
+		}
+		g.record(DomainEvent{Type: DomainSuddenDeath})
+		return false, append(events, NewEvent(SuddenDeath, leaders))
+	}
+
+	if len(g.leaders()) == 1 || g.Round-g.suddenDeathAt >= sc.MaxExtraRounds {
+		return true, nil
+	}
+
+	return false, nil
+}
+
+// TimeLimitCondition - The game is over once Limit has passed on the game's clock since it started
+type TimeLimitCondition struct {
+	Limit time.Duration
+}
+
+// NewTimeLimitCondition - Play rounds until limit has passed
+func NewTimeLimitCondition(limit time.Duration) *TimeLimitCondition {
+	return &TimeLimitCondition{limit}
+}
+
+// AfterRound - See WinCondition
+func (tc *TimeLimitCondition) AfterRound(g *Game) (bool, []*Event) {
+	return g.Clock.Now().Sub(g.StartedAt) >= tc.Limit, nil
+}
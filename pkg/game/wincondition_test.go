@@ -0,0 +1,157 @@
+package game
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMaxRoundsCondition(t *testing.T) {
+	assert := assert.New(t)
+	seq := []int{1, 1, 1, 1, 1}
+	gen := NewSSNG(seq)
+
+	game := NewGame(gen)
+	game.Condition = NewMaxRoundsCondition(3)
+	game.RegisterPlayer(&Player{Name: "Steve", First: 3, Second: 9})
+	game.RegisterPlayer(&Player{Name: "Sarah", First: 1, Second: 2})
+	game.AddWaitingPlayersToGame()
+	game.Start()
+	game.TakeEvents()
+
+	for i := 0; i < 3; i++ {
+		assert.Nil(game.PlayRound())
+	}
+	assert.Equal(GameStateCompleted, game.GetState())
+	assert.Equal(ErrGameComplete, game.PlayRound())
+	assert.Equal([]int{1, 1, 1}, game.Numbers)
+}
+
+func TestFirstToCondition(t *testing.T) {
+	assert := assert.New(t)
+	seq := []int{5, 5, 5}
+	gen := NewSSNG(seq)
+
+	game := NewGame(gen)
+	game.Condition = NewFirstToCondition(10, MaxRounds)
+	game.RegisterPlayer(&Player{Name: "Steve", First: 5, Second: 5})
+	game.RegisterPlayer(&Player{Name: "Sarah", First: 1, Second: 2})
+	game.AddWaitingPlayersToGame()
+	game.Start()
+	game.TakeEvents()
+
+	game.PlayRound()
+	assert.Equal(GameStateInProgress, game.GetState())
+	game.PlayRound()
+	assert.Equal(GameStateCompleted, game.GetState())
+
+	winner, _ := game.NominateWinner()
+	assert.Equal("Steve", winner.Name)
+	// Nominated by the condition, not a rogue win
+	assert.False(game.Rogue)
+}
+
+func TestLastStandingCondition(t *testing.T) {
+	assert := assert.New(t)
+	seq := []int{5, 5, 5, 5}
+	gen := NewSSNG(seq)
+
+	game := NewGame(gen)
+	game.Condition = NewLastStandingCondition(2, MaxRounds)
+	game.RegisterPlayer(&Player{Name: "PlayerA", First: 5, Second: 5})
+	game.RegisterPlayer(&Player{Name: "PlayerB", First: 4, Second: 6})
+	game.RegisterPlayer(&Player{Name: "PlayerC", First: 1, Second: 2})
+	game.AddWaitingPlayersToGame()
+	game.Start()
+	game.TakeEvents()
+
+	// Round 1: nobody goes
+	game.PlayRound()
+	assert.Empty(game.TakeEvents())
+	// Round 2: PlayerC is lowest
+	game.PlayRound()
+	events := game.TakeEvents()
+	assert.Len(events, 1)
+	assert.Equal(PlayerEliminated.String(), events[0].Type)
+	assert.True(game.Players["PlayerC"].Eliminated)
+	assert.Equal(GameStateInProgress, game.GetState())
+	// Round 4: PlayerB is lowest, PlayerA is the last one standing
+	game.PlayRound()
+	game.PlayRound()
+	assert.True(game.Players["PlayerB"].Eliminated)
+	assert.Equal(GameStateCompleted, game.GetState())
+
+	winner, _ := game.NominateWinner()
+	assert.Equal("PlayerA", winner.Name)
+}
+
+func TestSuddenDeathCondition(t *testing.T) {
+	assert := assert.New(t)
+	seq := []int{5, 5, 8, 8}
+	gen := NewSSNG(seq)
+
+	game := NewGame(gen)
+	game.Condition = NewSuddenDeathCondition(NewMaxRoundsCondition(2), 5)
+	game.RegisterPlayer(&Player{Name: "PlayerA", First: 5, Second: 5})
+	game.RegisterPlayer(&Player{Name: "PlayerB", First: 5, Second: 8})
+	game.AddWaitingPlayersToGame()
+	game.Start()
+	game.TakeEvents()
+
+	// A: 5, 10  B: 5, 10
+	game.PlayRound()
+	game.PlayRound()
+	events := game.TakeEvents()
+	assert.Len(events, 1)
+	assert.Equal(SuddenDeath.String(), events[0].Type)
+	assert.True(game.SuddenDeath)
+	assert.Equal(GameStateInProgress, game.GetState())
+
+	// A: 9  B: 15
+	game.PlayRound()
+	assert.Equal(GameStateCompleted, game.GetState())
+
+	winner, _ := game.NominateWinner()
+	assert.Equal("PlayerB", winner.Name)
+
+	game.Reset()
+	assert.False(game.SuddenDeath)
+	assert.Empty(game.Numbers)
+}
+
+func TestTimeLimitCondition(t *testing.T) {
+	assert := assert.New(t)
+	seq := []int{5, 5, 5}
+	gen := NewSSNG(seq)
+
+	game := NewGame(gen)
+	clock := NewFakeClock(time.Now())
+	game.Clock = clock
+	game.Condition = NewTimeLimitCondition(1 * time.Minute)
+	game.RegisterPlayer(&Player{Name: "Steve", First: 5, Second: 5})
+	game.RegisterPlayer(&Player{Name: "Sarah", First: 1, Second: 2})
+	game.AddWaitingPlayersToGame()
+	game.Start()
+	game.TakeEvents()
+
+	game.PlayRound()
+	assert.Equal(GameStateInProgress, game.GetState())
+
+	clock.Advance(1 * time.Minute)
+	game.PlayRound()
+	assert.Equal(GameStateCompleted, game.GetState())
+}
+
+func TestNewWinCondition(t *testing.T) {
+	assert := assert.New(t)
+
+	for _, name := range WinConditionNames() {
+		condition, err := NewWinCondition(name)
+		assert.Nil(err)
+		assert.NotNil(condition)
+	}
+
+	_, err := NewWinCondition("nope")
+	assert.Equal(ErrUnknownWinCondition, err)
+}
@@ -0,0 +1,126 @@
+package matchmaking
+
+import (
+	"encoding/json"
+	"log"
+	"net/http"
+
+	"networkgaming.co.uk/techtest/pkg/game"
+	"networkgaming.co.uk/techtest/pkg/problem"
+)
+
+// MatchmakingHandler - Accounts is who players are staking as, see game.JoinGameHandler
+type MatchmakingHandler struct {
+	Accounts   game.Accounts
+	matchmaker *Matchmaker
+	ratings    *Ratings
+}
+
+func NewMatchmakingHandler(matchmaker *Matchmaker, ratings *Ratings) *MatchmakingHandler {
+	return &MatchmakingHandler{matchmaker: matchmaker, ratings: ratings}
+}
+
+type TicketResponse struct {
+	Status int     `json:"status"`
+	Type   string  `json:"type"`
+	Title  string  `json:"title"`
+	Detail string  `json:"detail"`
+	Ticket string  `json:"ticket,omitempty"`
+	Rating float64 `json:"rating,omitempty"`
+}
+
+// Enqueue - POST /matchmaking, takes the same body as POST /join.
+// Subscribe to /matchmaking/subscribe?ticket=[ID] for QueuePosition and MatchFound events.
+func (h *MatchmakingHandler) Enqueue(w http.ResponseWriter, r *http.Request) {
+
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Server", "NG: Small Browser Based Game Server")
+
+	request := new(game.JoinGameRequest)
+	r.Body = http.MaxBytesReader(w, r.Body, game.MaxJoinBodySize)
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		log.Printf("Matchmaking - Error decoding json %s", err.Error())
+		problem.Write(w, problem.InvalidJSON(err))
+		return
+	}
+
+	account, err := game.Account(h.Accounts, r)
+	if err != nil {
+		problem.Write(w, err)
+		return
+	}
+
+	ticket, err := h.matchmaker.Enqueue(&game.Player{
+		Name:    request.Name,
+		First:   request.First,
+		Second:  request.Second,
+		Picks:   request.Picks,
+		Range:   request.Range,
+		Account: account,
+	})
+	if err != nil {
+		log.Printf("Matchmaking Error: %s", err.Error())
+		problem.Write(w, err)
+		return
+	}
+
+	writeResponse(w, http.StatusOK, TicketResponse{
+		Type:   "Success",
+		Title:  "Queued",
+		Detail: "Looking for players at your level",
+		Ticket: ticket.ID,
+		Rating: ticket.Rating,
+	})
+}
+
+// Cancel - DELETE /matchmaking?ticket=[ID], with the wallet token the ticket was queued with
+func (h *MatchmakingHandler) Cancel(w http.ResponseWriter, r *http.Request) {
+
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Server", "NG: Small Browser Based Game Server")
+
+	account, err := game.Account(h.Accounts, r)
+	if err != nil {
+		problem.Write(w, err)
+		return
+	}
+
+	id := r.URL.Query().Get("ticket")
+	if err := h.matchmaker.Cancel(id, account); err != nil {
+		problem.Write(w, err)
+		return
+	}
+
+	writeResponse(w, http.StatusOK, TicketResponse{
+		Type:   "Success",
+		Title:  "Left Queue",
+		Detail: "You are no longer queued",
+		Ticket: id,
+	})
+}
+
+// GetRating - GET /matchmaking/rating with a wallet token for its rating,
+// or ?name=[NAME] without one for a bot's
+func (h *MatchmakingHandler) GetRating(w http.ResponseWriter, r *http.Request) {
+
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Server", "NG: Small Browser Based Game Server")
+
+	account := ""
+	if r.Header.Get("Authorization") != "" {
+		var err error
+		if account, err = game.Account(h.Accounts, r); err != nil {
+			problem.Write(w, err)
+			return
+		}
+	}
+
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(h.ratings.Get(account, r.URL.Query().Get("name")))
+}
+
+func writeResponse(w http.ResponseWriter, status int, response TicketResponse) {
+	response.Status = status
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(response)
+}
@@ -0,0 +1,373 @@
+package matchmaking
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"log"
+	"math"
+	"net/http"
+	"sort"
+	"sync"
+	"time"
+
+	"networkgaming.co.uk/techtest/pkg/game"
+	"networkgaming.co.uk/techtest/pkg/problem"
+)
+
+const (
+	// ticketEvents - Buffered so a slow client never holds up matching
+	ticketEvents = 16
+	// matchedTTL - How long a matched ticket can still be looked up, for clients that subscribe late
+	matchedTTL = 1 * time.Minute
+)
+
+var (
+	ErrAlreadyQueued  = problem.New("name_taken", http.StatusConflict, "Invalid request: There is already a player queued with that name")
+	ErrTicketNotFound = problem.New("ticket_not_found", http.StatusNotFound, "Invalid request: No ticket with that ID")
+)
+
+// Rooms - Where matched players are seated, see game.Lobby
+type Rooms interface {
+	Validate(player *game.Player) error
+	Seat(players []*game.Player) (string, error)
+	ChatToken(room string, player *game.Player) (string, error)
+}
+
+// Config - Parameters for grouping players.
+// A group is seated as soon as it has MatchSize players, or once its longest
+// waiting player has been queued for FillAfter if it has at least MinPlayers.
+// Players match when their ratings are within both of their windows, which
+// start at InitialWindow and grow by WidenPerSecond up to MaxWindow.
+type Config struct {
+	MatchSize      int
+	MinPlayers     int
+	FillAfter      time.Duration
+	InitialWindow  float64
+	WidenPerSecond float64
+	MaxWindow      float64
+	Interval       time.Duration
+}
+
+// Ticket - A player waiting in the queue, events are sent until the match is found
+// then the channel is closed. The ID is random and only given to the player, it's
+// their secret to follow and cancel the ticket with.
+type Ticket struct {
+	ID         string
+	Player     *game.Player
+	Rating     float64
+	EnqueuedAt time.Time
+	MatchedAt  time.Time
+	Events     chan *game.Event
+}
+
+// Match - MatchFound event data
+type Match struct {
+	Ticket    string   `json:"ticket"`
+	Room      string   `json:"room"`
+	Players   []string `json:"players"`
+	ChatToken string   `json:"chat_token,omitempty"`
+}
+
+// Position - QueuePosition event data
+type Position struct {
+	Ticket   string  `json:"ticket"`
+	Position int     `json:"position"`
+	Queued   int     `json:"queued"`
+	Rating   float64 `json:"rating"`
+	Window   float64 `json:"window"`
+	Waited   float64 `json:"waited"`
+}
+
+// Matchmaker - Queues join requests and seats players of similar skill together
+type Matchmaker struct {
+	Config  *Config
+	Now     func() time.Time
+	ratings *Ratings
+	rooms   Rooms
+	queue   []*Ticket
+	matched map[string]*Ticket
+	mu      sync.Mutex
+}
+
+// NewMatchmaker - Seats players in rooms, grouped by ratings
+func NewMatchmaker(rooms Rooms, ratings *Ratings, config *Config) *Matchmaker {
+	return &Matchmaker{
+		Config:  config,
+		Now:     time.Now,
+		ratings: ratings,
+		rooms:   rooms,
+		queue:   make([]*Ticket, 0),
+		matched: make(map[string]*Ticket),
+	}
+}
+
+// Enqueue - Queue a player, they are validated now so a bad pick can't spoil a match later
+func (mm *Matchmaker) Enqueue(player *game.Player) (*Ticket, error) {
+	if err := mm.rooms.Validate(player); err != nil {
+		return nil, err
+	}
+
+	mm.mu.Lock()
+	defer mm.mu.Unlock()
+
+	for _, ticket := range mm.queue {
+		if ticket.Player.Name == player.Name {
+			return nil, ErrAlreadyQueued
+		}
+	}
+
+	id, err := ticketID()
+	if err != nil {
+		return nil, err
+	}
+	ticket := &Ticket{
+		ID:         id,
+		Player:     player,
+		Rating:     mm.ratings.Get(player.Account, player.Name).Rating,
+		EnqueuedAt: mm.Now(),
+		Events:     make(chan *game.Event, ticketEvents),
+	}
+	mm.queue = append(mm.queue, ticket)
+
+	return ticket, nil
+}
+
+// Ticket - Look up a queued, or recently matched, ticket
+func (mm *Matchmaker) Ticket(id string) (*Ticket, error) {
+	mm.mu.Lock()
+	defer mm.mu.Unlock()
+
+	for _, ticket := range mm.queue {
+		if ticket.ID == id {
+			return ticket, nil
+		}
+	}
+	if ticket, matched := mm.matched[id]; matched {
+		return ticket, nil
+	}
+
+	return nil, ErrTicketNotFound
+}
+
+// Cancel - Take a ticket out of the queue, only for the account that queued it
+func (mm *Matchmaker) Cancel(id string, account string) error {
+	mm.mu.Lock()
+	defer mm.mu.Unlock()
+
+	for i, ticket := range mm.queue {
+		if ticket.ID == id && ticket.Player.Account == account {
+			mm.queue = append(mm.queue[:i], mm.queue[i+1:]...)
+			close(ticket.Events)
+			return nil
+		}
+	}
+
+	return ErrTicketNotFound
+}
+
+// ticketID - A random hex string, prefixed
+func ticketID() (string, error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+
+	return "ticket-" + hex.EncodeToString(b), nil
+}
+
+// Start - Run Match every Config.Interval until ctx is done, then close every ticket
+func (mm *Matchmaker) Start(ctx context.Context) {
+	go func() {
+		ticker := time.NewTicker(mm.Config.Interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ticker.C:
+				mm.Match()
+			case <-ctx.Done():
+				mm.close()
+				return
+			}
+		}
+	}()
+}
+
+// close - Tell everyone still queued the server's going down and close their tickets
+func (mm *Matchmaker) close() {
+	mm.mu.Lock()
+	defer mm.mu.Unlock()
+
+	for _, ticket := range mm.queue {
+		deliver(ticket, game.NewEvent(game.ServerShuttingDown, &game.ShutdownNotice{Reason: game.ShutdownReason}))
+		close(ticket.Events)
+	}
+	mm.queue = nil
+}
+
+// Match - One matching pass, seats every group that's ready and tells everyone
+// else where they are in the queue
+func (mm *Matchmaker) Match() []Match {
+	mm.mu.Lock()
+	groups := mm.group()
+	mm.mu.Unlock()
+
+	matches := []Match{}
+	seated := []*Ticket{}
+	failed := []*Ticket{}
+	for _, group := range groups {
+		players := make([]*game.Player, len(group))
+		names := make([]string, len(group))
+		for i, ticket := range group {
+			players[i] = ticket.Player
+			names[i] = ticket.Player.Name
+		}
+
+		room, err := mm.rooms.Seat(players)
+		if err != nil {
+			log.Printf("Matchmaking - Unable to seat %v: %s\n", names, err.Error())
+			failed = append(failed, group...)
+			continue
+		}
+		for _, ticket := range group {
+			match := Match{Ticket: ticket.ID, Room: room, Players: names}
+			// Only sent down the ticket's own subscription, and left out of the returned matches
+			token, err := mm.rooms.ChatToken(room, ticket.Player)
+			if err != nil {
+				log.Printf("Matchmaking - Unable to issue a chat token to %s: %s\n", ticket.Player.Name, err.Error())
+			}
+			found := match
+			found.ChatToken = token
+			deliver(ticket, game.NewEvent(game.MatchFound, found))
+			close(ticket.Events)
+			matches = append(matches, match)
+			seated = append(seated, ticket)
+		}
+	}
+
+	mm.mu.Lock()
+	defer mm.mu.Unlock()
+
+	now := mm.Now()
+	for id, ticket := range mm.matched {
+		if now.Sub(ticket.MatchedAt) > matchedTTL {
+			delete(mm.matched, id)
+		}
+	}
+	for _, ticket := range seated {
+		ticket.MatchedAt = now
+		mm.matched[ticket.ID] = ticket
+	}
+
+	// Back to the queue in the order they first joined
+	mm.queue = append(mm.queue, failed...)
+	sort.SliceStable(mm.queue, func(i, j int) bool {
+		return mm.queue[i].EnqueuedAt.Before(mm.queue[j].EnqueuedAt)
+	})
+	for i, ticket := range mm.queue {
+		send(ticket, game.NewEvent(game.QueuePosition, Position{
+			Ticket:   ticket.ID,
+			Position: i + 1,
+			Queued:   len(mm.queue),
+			Rating:   ticket.Rating,
+			Window:   mm.window(ticket, now),
+			Waited:   now.Sub(ticket.EnqueuedAt).Seconds(),
+		}))
+	}
+
+	return matches
+}
+
+// group - Take every group that's ready out of the queue, longest waiting first.
+// Call with the lock held.
+func (mm *Matchmaker) group() [][]*Ticket {
+	now := mm.Now()
+	used := make(map[*Ticket]bool)
+	groups := [][]*Ticket{}
+
+	for _, anchor := range mm.queue {
+		if used[anchor] {
+			continue
+		}
+
+		// Closest ratings first
+		candidates := []*Ticket{}
+		for _, ticket := range mm.queue {
+			if ticket != anchor && !used[ticket] {
+				candidates = append(candidates, ticket)
+			}
+		}
+		sort.SliceStable(candidates, func(i, j int) bool {
+			return math.Abs(candidates[i].Rating-anchor.Rating) < math.Abs(candidates[j].Rating-anchor.Rating)
+		})
+
+		group := []*Ticket{anchor}
+		for _, candidate := range candidates {
+			if len(group) >= mm.Config.MatchSize {
+				break
+			}
+			if mm.fits(candidate, group, now) {
+				group = append(group, candidate)
+			}
+		}
+
+		full := len(group) >= mm.Config.MatchSize
+		waited := len(group) >= mm.Config.MinPlayers && now.Sub(anchor.EnqueuedAt) >= mm.Config.FillAfter
+		if full || waited {
+			for _, ticket := range group {
+				used[ticket] = true
+			}
+			groups = append(groups, group)
+		}
+	}
+
+	remaining := make([]*Ticket, 0, len(mm.queue))
+	for _, ticket := range mm.queue {
+		if !used[ticket] {
+			remaining = append(remaining, ticket)
+		}
+	}
+	mm.queue = remaining
+
+	return groups
+}
+
+// fits - Whether ticket is within everyone's window, and they are in its
+func (mm *Matchmaker) fits(ticket *Ticket, group []*Ticket, now time.Time) bool {
+	for _, member := range group {
+		window := math.Min(mm.window(ticket, now), mm.window(member, now))
+		if math.Abs(ticket.Rating-member.Rating) > window {
+			return false
+		}
+	}
+
+	return true
+}
+
+// window - How far from their rating a player will accept, widening as they wait
+func (mm *Matchmaker) window(ticket *Ticket, now time.Time) float64 {
+	waited := now.Sub(ticket.EnqueuedAt).Seconds()
+
+	return math.Min(mm.Config.InitialWindow+mm.Config.WidenPerSecond*waited, mm.Config.MaxWindow)
+}
+
+// deliver - Make room for an event that mustn't be missed by dropping stale updates
+func deliver(ticket *Ticket, event *game.Event) {
+	for {
+		select {
+		case <-ticket.Events:
+			continue
+		default:
+		}
+		break
+	}
+	send(ticket, event)
+}
+
+// send - Never blocks, a client that isn't reading misses updates
+func send(ticket *Ticket, event *game.Event) {
+	select {
+	case ticket.Events <- event:
+	default:
+	}
+}
@@ -0,0 +1,135 @@
+package matchmaking
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"networkgaming.co.uk/techtest/pkg/game"
+)
+
+type mockRooms struct {
+	seated [][]string
+}
+
+func (mr *mockRooms) Validate(player *game.Player) error {
+	return game.NewGame(nil).ValidatePlayer(player)
+}
+
+func (mr *mockRooms) Seat(players []*game.Player) (string, error) {
+	names := []string{}
+	for _, player := range players {
+		names = append(names, player.Name)
+	}
+	mr.seated = append(mr.seated, names)
+
+	return "room", nil
+}
+
+func (mr *mockRooms) ChatToken(room string, player *game.Player) (string, error) {
+	return room + ":" + player.Name, nil
+}
+
+func newTestMatchmaker(rooms Rooms, ratings *Ratings, now *time.Time) *Matchmaker {
+	mm := NewMatchmaker(rooms, ratings, &Config{
+		MatchSize:      3,
+		MinPlayers:     2,
+		FillAfter:      30 * time.Second,
+		InitialWindow:  100,
+		WidenPerSecond: 10,
+		MaxWindow:      1000,
+		Interval:       time.Second,
+	})
+	mm.Now = func() time.Time { return *now }
+
+	return mm
+}
+
+func TestMatchmakerFullMatch(t *testing.T) {
+	assert := assert.New(t)
+
+	now := time.Now()
+	rooms := &mockRooms{}
+	mm := newTestMatchmaker(rooms, NewRatings(), &now)
+
+	for _, name := range []string{"Steve", "Sarah", "Simon"} {
+		_, err := mm.Enqueue(&game.Player{Name: name, First: 3, Second: 7})
+		assert.Nil(err)
+	}
+	_, err := mm.Enqueue(&game.Player{Name: "Steve", First: 3, Second: 7})
+	assert.Equal(ErrAlreadyQueued, err)
+	_, err = mm.Enqueue(&game.Player{Name: "Stan", First: 3, Second: 70})
+	assert.Equal(game.ErrInvalidNumber, err)
+
+	matches := mm.Match()
+	assert.Len(matches, 3)
+	assert.Equal([][]string{{"Steve", "Sarah", "Simon"}}, rooms.seated)
+
+	// Told where to go, then closed
+	ticket, err := mm.Ticket(matches[0].Ticket)
+	assert.Nil(err)
+	event := <-ticket.Events
+	assert.Equal(game.MatchFound.String(), event.Type)
+	// Only the ticket's holder is given their chat token
+	assert.Equal("room:"+ticket.Player.Name, event.Data.(Match).ChatToken)
+	assert.Empty(matches[0].ChatToken)
+	_, open := <-ticket.Events
+	assert.False(open)
+}
+
+func TestMatchmakerSkillAndWindow(t *testing.T) {
+	assert := assert.New(t)
+
+	now := time.Now()
+	rooms := &mockRooms{}
+	ratings := NewRatings()
+	ratings.ratings["Pro"] = Rating{Name: "Pro", Rating: 2100}
+	ratings.ratings["Ace"] = Rating{Name: "Ace", Rating: 2050}
+	mm := newTestMatchmaker(rooms, ratings, &now)
+
+	novice, _ := mm.Enqueue(&game.Player{Name: "Novice", First: 3, Second: 7, Account: "account-1"})
+	mm.Enqueue(&game.Player{Name: "Pro", First: 3, Second: 7})
+	mm.Enqueue(&game.Player{Name: "Ace", First: 3, Second: 7})
+	assert.Regexp("^ticket-[0-9a-f]{32}$", novice.ID)
+
+	// Not full, and nobody has waited long enough to settle for two
+	assert.Empty(mm.Match())
+	event := <-novice.Events
+	assert.Equal(game.QueuePosition.String(), event.Type)
+	assert.Equal(1, event.Data.(Position).Position)
+
+	// Pro and Ace are close enough to play once they've waited, the novice is left out
+	now = now.Add(30 * time.Second)
+	matches := mm.Match()
+	assert.Len(matches, 2)
+	assert.ElementsMatch([]string{"Pro", "Ace"}, rooms.seated[0])
+
+	// Novice is still waiting
+	_, err := mm.Ticket(novice.ID)
+	assert.Nil(err)
+	// Only the account that queued can cancel
+	assert.Equal(ErrTicketNotFound, mm.Cancel(novice.ID, "account-2"))
+	assert.Nil(mm.Cancel(novice.ID, "account-1"))
+	assert.Equal(ErrTicketNotFound, mm.Cancel(novice.ID, "account-1"))
+}
+
+func TestMatchmakerWindowWidens(t *testing.T) {
+	assert := assert.New(t)
+
+	now := time.Now()
+	rooms := &mockRooms{}
+	ratings := NewRatings()
+	ratings.ratings["Pro"] = Rating{Name: "Pro", Rating: 2000}
+	mm := newTestMatchmaker(rooms, ratings, &now)
+
+	mm.Enqueue(&game.Player{Name: "Novice", First: 3, Second: 7})
+	mm.Enqueue(&game.Player{Name: "Pro", First: 3, Second: 7})
+
+	// 500 apart, needs a 500 point window, 100 + 10 a second
+	now = now.Add(30 * time.Second)
+	assert.Empty(mm.Match())
+	now = now.Add(9 * time.Second)
+	assert.Empty(mm.Match())
+	now = now.Add(1 * time.Second)
+	assert.Len(mm.Match(), 2)
+}
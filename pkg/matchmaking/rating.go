@@ -0,0 +1,115 @@
+package matchmaking
+
+import (
+	"math"
+	"sort"
+	"sync"
+
+	"networkgaming.co.uk/techtest/pkg/game"
+)
+
+const (
+	DefaultRating = 1500
+	DefaultK      = 32
+)
+
+// Rating - A player's skill, from the games they've completed
+type Rating struct {
+	Name   string  `json:"name"`
+	Rating float64 `json:"rating"`
+	Games  int     `json:"games"`
+}
+
+// Ratings - Elo ratings for every player seen, updated as games complete.
+// They're held against the player's wallet account, so nobody can take over a rating
+// by joining with its name. Bots have no account and are rated by name.
+// Implements game.ResultListener.
+type Ratings struct {
+	K       float64
+	ratings map[string]Rating
+	mu      sync.RWMutex
+}
+
+// NewRatings - Everyone starts on DefaultRating
+func NewRatings() *Ratings {
+	return &Ratings{
+		K:       DefaultK,
+		ratings: make(map[string]Rating),
+	}
+}
+
+// Get - The rating of account, or of name without one, DefaultRating if they haven't played yet
+func (r *Ratings) Get(account string, name string) Rating {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	return r.get(account, name)
+}
+
+func (r *Ratings) get(account string, name string) Rating {
+	rating, exists := r.ratings[ratingKey(account, name)]
+	if !exists {
+		return Rating{Name: name, Rating: DefaultRating}
+	}
+
+	return rating
+}
+
+// ratingKey - Who a rating is held against
+func ratingKey(account string, name string) string {
+	if account != "" {
+		return account
+	}
+
+	return name
+}
+
+// GameCompleted - Multiplayer Elo, every player is scored against every other.
+// The winner beats everyone, the rest are ranked by score with equal scores drawing.
+func (r *Ratings) GameCompleted(result game.GameResult) {
+	players := append([]game.GamePlayer{}, result.LeaderBoard...)
+	if len(players) < 2 {
+		return
+	}
+	sort.SliceStable(players, func(i, j int) bool {
+		if players[i].Name == result.Winner.Name {
+			return true
+		}
+		if players[j].Name == result.Winner.Name {
+			return false
+		}
+		return players[i].Score > players[j].Score
+	})
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	before := make([]Rating, len(players))
+	for i, player := range players {
+		before[i] = r.get(player.Account, player.Name)
+	}
+
+	k := r.K / float64(len(players)-1)
+	for i, player := range players {
+		delta := 0.0
+		for j, other := range players {
+			if i == j {
+				continue
+			}
+			expected := 1 / (1 + math.Pow(10, (before[j].Rating-before[i].Rating)/400))
+			actual := 0.0
+			if i < j {
+				actual = 1
+			}
+			if i > 0 && j > 0 && player.Score == other.Score {
+				actual = 0.5
+			}
+			delta += actual - expected
+		}
+		rating := before[i]
+		rating.Name = player.Name
+		rating.Rating += k * delta
+		rating.Games++
+		r.ratings[ratingKey(player.Account, player.Name)] = rating
+	}
+}
@@ -0,0 +1,38 @@
+package matchmaking
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"networkgaming.co.uk/techtest/pkg/game"
+)
+
+func TestRatingsGameCompleted(t *testing.T) {
+	assert := assert.New(t)
+
+	ratings := NewRatings()
+	assert.Equal(float64(DefaultRating), ratings.Get("account-1", "Steve").Rating)
+
+	ratings.GameCompleted(game.GameResult{
+		Winner: game.GamePlayer{Name: "Steve", Score: 10, Account: "account-1"},
+		LeaderBoard: []game.GamePlayer{
+			{Name: "Sarah", Score: 10, Account: "account-2"},
+			{Name: "Steve", Score: 10, Account: "account-1"},
+			{Name: "Simon", Score: -3, Bot: true},
+		},
+	})
+
+	steve := ratings.Get("account-1", "Steve")
+	sarah := ratings.Get("account-2", "Sarah")
+	simon := ratings.Get("", "Simon")
+	// Winner on the tiebreak still beats Sarah
+	assert.True(steve.Rating > sarah.Rating)
+	assert.True(sarah.Rating > simon.Rating)
+	assert.Equal(1, steve.Games)
+	// Elo is zero sum
+	assert.InDelta(3*DefaultRating, steve.Rating+sarah.Rating+simon.Rating, 0.0001)
+
+	// Held against the account, not whoever joins with the name
+	assert.Equal(float64(DefaultRating), ratings.Get("account-3", "Steve").Rating)
+	assert.Equal(float64(DefaultRating), ratings.Get("", "Steve").Rating)
+}
@@ -0,0 +1,151 @@
+package names
+
+import (
+	"net/http"
+	"strings"
+	"unicode"
+
+	"golang.org/x/text/cases"
+	"golang.org/x/text/unicode/norm"
+
+	"networkgaming.co.uk/techtest/pkg/problem"
+)
+
+const (
+	DefaultMinLength = 2
+	DefaultMaxLength = 20
+)
+
+var (
+	ErrNameEmpty      = problem.New("name_empty", http.StatusUnprocessableEntity, "Invalid name: Choose a name")
+	ErrNameTooShort   = problem.New("name_too_short", http.StatusUnprocessableEntity, "Invalid name: That name is too short")
+	ErrNameTooLong    = problem.New("name_too_long", http.StatusUnprocessableEntity, "Invalid name: That name is too long")
+	ErrNameCharacters = problem.New("name_characters", http.StatusUnprocessableEntity, "Invalid name: Use letters, numbers, spaces and - _ . ' only, starting with a letter or number")
+	ErrNameReserved   = problem.New("name_reserved", http.StatusUnprocessableEntity, "Invalid name: That name is reserved")
+	ErrNameBlocked    = problem.New("name_blocked", http.StatusUnprocessableEntity, "Invalid name: That name isn't allowed")
+)
+
+// DefaultReserved - Names that could pass for the server or its staff
+var DefaultReserved = []string{"admin", "administrator", "host", "house", "moderator", "mod", "server", "system"}
+
+// DefaultReservedWords - Words no player name may contain, so nobody can pass for a bot
+var DefaultReservedWords = []string{"bot"}
+
+// confusables - Characters that look like a Latin letter or digit, mapped to it.
+// Applied after case folding, so only lower case forms are needed.
+var confusables = map[rune]rune{
+	// Cyrillic
+	'а': 'a', 'в': 'b', 'е': 'e', 'ё': 'e', 'һ': 'h', 'і': 'i', 'ї': 'i', 'ј': 'j', 'к': 'k',
+	'м': 'm', 'н': 'h', 'о': 'o', 'р': 'p', 'с': 'c', 'т': 't', 'у': 'y', 'х': 'x', 'ѕ': 's',
+	'ԁ': 'd', 'ԛ': 'q', 'ԝ': 'w', 'ь': 'b',
+	// Greek
+	'α': 'a', 'β': 'b', 'ε': 'e', 'η': 'n', 'ι': 'i', 'κ': 'k', 'ν': 'v', 'ο': 'o', 'ρ': 'p',
+	'τ': 't', 'υ': 'u', 'χ': 'x', 'ς': 'c',
+	// Latin lookalikes
+	'ı': 'i', 'ɡ': 'g', 'ɑ': 'a', 'ℓ': 'l',
+	// Digits
+	'0': 'o', '1': 'l',
+}
+
+// Policy - What makes a name acceptable.
+// Reserved names and Blocked words are compared by Skeleton, so lookalikes are caught too.
+type Policy struct {
+	MinLength     int
+	MaxLength     int
+	Reserved      []string
+	ReservedWords []string
+	Blocked       []string
+}
+
+// DefaultPolicy - 2 to 20 characters, with the default reserved names and words
+func DefaultPolicy() *Policy {
+	return &Policy{
+		MinLength:     DefaultMinLength,
+		MaxLength:     DefaultMaxLength,
+		Reserved:      DefaultReserved,
+		ReservedWords: DefaultReservedWords,
+	}
+}
+
+// Normalize - The name as it should be shown, or why it isn't allowed.
+// Compatibility characters are folded (NFKC), whitespace is trimmed and runs of it collapsed.
+func (p *Policy) Normalize(name string) (string, error) {
+	name = strings.Join(strings.Fields(norm.NFKC.String(name)), " ")
+	if name == "" {
+		return "", ErrNameEmpty
+	}
+
+	length := len([]rune(name))
+	if length < p.MinLength {
+		return "", ErrNameTooShort
+	}
+	if length > p.MaxLength {
+		return "", ErrNameTooLong
+	}
+	for i, r := range name {
+		if i == 0 && !unicode.IsLetter(r) && !unicode.IsDigit(r) {
+			return "", ErrNameCharacters
+		}
+		if !allowed(r) {
+			return "", ErrNameCharacters
+		}
+	}
+
+	skeleton := Skeleton(name)
+	for _, reserved := range p.Reserved {
+		if skeleton == Skeleton(reserved) {
+			return "", ErrNameReserved
+		}
+	}
+	for _, word := range words(name) {
+		for _, reserved := range p.ReservedWords {
+			if Skeleton(word) == Skeleton(reserved) {
+				return "", ErrNameReserved
+			}
+		}
+	}
+	for _, blocked := range p.Blocked {
+		if blocked = Skeleton(blocked); blocked != "" && strings.Contains(skeleton, blocked) {
+			return "", ErrNameBlocked
+		}
+	}
+
+	return name, nil
+}
+
+// Skeleton - What a name looks like, for spotting names that are too close to tell apart.
+// Case, accents, separators and lookalike characters are all ignored,
+// so "Steve", "stéve", "St-eve" and "Stеve" (with a Cyrillic e) are the same.
+func Skeleton(name string) string {
+	folded := norm.NFD.String(cases.Fold().String(norm.NFKC.String(name)))
+
+	var skeleton strings.Builder
+	for _, r := range folded {
+		if unicode.Is(unicode.Mn, r) || unicode.IsSpace(r) || unicode.IsPunct(r) {
+			continue
+		}
+		if mapped, confusable := confusables[r]; confusable {
+			r = mapped
+		}
+		skeleton.WriteRune(r)
+	}
+
+	return skeleton.String()
+}
+
+// allowed - Letters, numbers, marks and a few separators
+func allowed(r rune) bool {
+	switch r {
+	case ' ', '-', '_', '.', '\'':
+		return true
+	}
+
+	return unicode.IsLetter(r) || unicode.IsDigit(r) || unicode.Is(unicode.Mn, r) || unicode.Is(unicode.Mc, r)
+}
+
+// words - The name split on separators
+func words(name string) []string {
+	return strings.FieldsFunc(name, func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+}
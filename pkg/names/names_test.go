@@ -0,0 +1,63 @@
+package names
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNormalize(t *testing.T) {
+	assert := assert.New(t)
+
+	policy := DefaultPolicy()
+	policy.Blocked = []string{"darn"}
+
+	valid := map[string]string{
+		"Steve":            "Steve",
+		"  Steve   Smith ": "Steve Smith",
+		"Zoë":              "Zoë",
+		"Ｓｔｅｖｅ":            "Steve",
+		"O'Brien-Smith_2":  "O'Brien-Smith_2",
+		"Ремо":             "Ремо",
+		"Robot Wars":       "Robot Wars",
+		"D4rn":             "D4rn",
+	}
+	for name, expected := range valid {
+		normalized, err := policy.Normalize(name)
+		assert.Nil(err, name)
+		assert.Equal(expected, normalized)
+	}
+
+	invalid := map[string]error{
+		"":                      ErrNameEmpty,
+		"   ":                   ErrNameEmpty,
+		"S":                     ErrNameTooShort,
+		strings.Repeat("a", 21): ErrNameTooLong,
+		"<script>":              ErrNameCharacters,
+		"Steve\x00":             ErrNameCharacters,
+		"Steve\u200b":           ErrNameCharacters,
+		"-Steve":                ErrNameCharacters,
+		"Admin":                 ErrNameReserved,
+		"Аdmіn":                 ErrNameReserved,
+		"adaptive bot 1":        ErrNameReserved,
+		"Big Darn Steve":        ErrNameBlocked,
+		"Steve the d-a-r-n-ed":  ErrNameBlocked,
+	}
+	for name, expected := range invalid {
+		_, err := policy.Normalize(name)
+		assert.Equal(expected, err, name)
+	}
+}
+
+func TestSkeleton(t *testing.T) {
+	assert := assert.New(t)
+
+	steve := Skeleton("Steve")
+	for _, name := range []string{"steve", "STEVE", "Stéve", "St-eve", "St eve", "Stеve", "Ѕteve", "Ｓｔｅｖｅ"} {
+		assert.Equal(steve, Skeleton(name), name)
+	}
+	assert.Equal(Skeleton("Simon"), Skeleton("Sim0n"))
+	assert.NotEqual(steve, Skeleton("Steven"))
+	assert.NotEqual(steve, Skeleton("Stave"))
+}
@@ -0,0 +1,95 @@
+package problem
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+)
+
+const (
+	// ContentType - RFC 7807 problem details
+	ContentType = "application/problem+json"
+	// TypeBase - Problem types are TypeBase followed by the code
+	TypeBase = "/problems/"
+
+	CodeInvalidJSON  = "invalid_json"
+	CodeTooLarge     = "request_too_large"
+	CodeRateLimited  = "rate_limited"
+	CodeUnauthorized = "unauthorized"
+	CodeInternal     = "internal_error"
+)
+
+var (
+	ErrUnauthorized = New(CodeUnauthorized, http.StatusUnauthorized, "Unauthorized: Admin token required")
+)
+
+// Error - An error with a stable code for clients to check and the HTTP status it's reported with
+type Error struct {
+	Code    string
+	Status  int
+	Message string
+}
+
+// New - Message is what's shown as the problem's detail
+func New(code string, status int, message string) *Error {
+	return &Error{Code: code, Status: status, Message: message}
+}
+
+func (e *Error) Error() string {
+	return e.Message
+}
+
+// Problem - The error as problem details
+func (e *Error) Problem() Problem {
+	return Problem{
+		Type:   TypeBase + e.Code,
+		Title:  http.StatusText(e.Status),
+		Status: e.Status,
+		Detail: e.Message,
+		Code:   e.Code,
+	}
+}
+
+// Problem - RFC 7807 problem details, Code is the type without its TypeBase
+// and RetryAfter is only set when rate limited
+type Problem struct {
+	Type       string `json:"type"`
+	Title      string `json:"title"`
+	Status     int    `json:"status"`
+	Detail     string `json:"detail"`
+	Code       string `json:"code"`
+	RetryAfter int    `json:"retry_after,omitempty"`
+}
+
+// From - The problem for err, errors without a code are internal errors
+func From(err error) Problem {
+	var coded *Error
+	if errors.As(err, &coded) {
+		return coded.Problem()
+	}
+
+	return New(CodeInternal, http.StatusInternalServerError, err.Error()).Problem()
+}
+
+// InvalidJSON - A request body that couldn't be decoded, or was too large to
+func InvalidJSON(err error) *Error {
+	var tooLarge *http.MaxBytesError
+	if errors.As(err, &tooLarge) {
+		return New(CodeTooLarge, http.StatusRequestEntityTooLarge, "Invalid request: The request body is too large")
+	}
+
+	return New(CodeInvalidJSON, http.StatusBadRequest, "Invalid JSON: "+err.Error())
+}
+
+// Write - Respond with the problem for err
+func Write(w http.ResponseWriter, err error) {
+	WriteProblem(w, From(err))
+}
+
+// WriteProblem - Respond with p as application/problem+json
+func WriteProblem(w http.ResponseWriter, p Problem) {
+	w.Header().Set("Content-Type", ContentType)
+	w.Header().Set("Server", "NG: Small Browser Based Game Server")
+	w.WriteHeader(p.Status)
+	json.NewEncoder(w).Encode(p)
+}
@@ -0,0 +1,64 @@
+package problem
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFrom(t *testing.T) {
+	assert := assert.New(t)
+
+	errTaken := New("name_taken", http.StatusConflict, "Invalid name: Taken")
+	p := From(errTaken)
+	assert.Equal("/problems/name_taken", p.Type)
+	assert.Equal("Conflict", p.Title)
+	assert.Equal(http.StatusConflict, p.Status)
+	assert.Equal("Invalid name: Taken", p.Detail)
+	assert.Equal("name_taken", p.Code)
+
+	// Wrapped errors keep their code
+	assert.Equal("name_taken", From(fmt.Errorf("joining: %w", errTaken)).Code)
+
+	// Anything else is an internal error
+	p = From(errors.New("disk on fire"))
+	assert.Equal(CodeInternal, p.Code)
+	assert.Equal(http.StatusInternalServerError, p.Status)
+}
+
+func TestInvalidJSON(t *testing.T) {
+	assert := assert.New(t)
+
+	err := InvalidJSON(errors.New("unexpected EOF"))
+	assert.Equal(CodeInvalidJSON, err.Code)
+	assert.Equal(http.StatusBadRequest, err.Status)
+
+	body := http.MaxBytesReader(httptest.NewRecorder(), readCloser{strings.NewReader("{}")}, 1)
+	_, read := body.Read(make([]byte, 2))
+	err = InvalidJSON(read)
+	assert.Equal(CodeTooLarge, err.Code)
+	assert.Equal(http.StatusRequestEntityTooLarge, err.Status)
+}
+
+func TestWrite(t *testing.T) {
+	assert := assert.New(t)
+
+	recorder := httptest.NewRecorder()
+	Write(recorder, ErrUnauthorized)
+	assert.Equal(http.StatusUnauthorized, recorder.Code)
+	assert.Equal(ContentType, recorder.Header().Get("Content-Type"))
+	assert.Contains(recorder.Body.String(), `"code":"unauthorized"`)
+}
+
+type readCloser struct {
+	*strings.Reader
+}
+
+func (readCloser) Close() error {
+	return nil
+}
@@ -0,0 +1,74 @@
+package ratelimit
+
+import (
+	"net/http"
+	"sync"
+	"time"
+)
+
+const (
+	// ConnRetryAfter - Suggested wait when a client has too many connections open
+	ConnRetryAfter = 5 * time.Second
+)
+
+// Conns - Caps how many connections each key has open at once
+type Conns struct {
+	Max  int
+	open map[string]int
+	mu   sync.Mutex
+}
+
+// NewConns - Zero max never limits
+func NewConns(max int) *Conns {
+	return &Conns{
+		Max:  max,
+		open: make(map[string]int),
+	}
+}
+
+// Acquire - Open a connection for key if it has one to spare, Release it when it closes
+func (c *Conns) Acquire(key string) bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.Max > 0 && c.open[key] >= c.Max {
+		return false
+	}
+	c.open[key]++
+
+	return true
+}
+
+// Release - Close a connection opened with Acquire
+func (c *Conns) Release(key string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.open[key] <= 1 {
+		delete(c.open, key)
+		return
+	}
+	c.open[key]--
+}
+
+// Open - Connections key has open
+func (c *Conns) Open(key string) int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return c.open[key]
+}
+
+// Middleware - Cap connections by IP, for handlers that hold the connection
+// open until they return like websocket subscriptions
+func (c *Conns) Middleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ip := IP(r)
+		if !c.Acquire(ip) {
+			WriteTooManyRequests(w, ConnRetryAfter, "Too many connections open, close one first")
+			return
+		}
+		defer c.Release(ip)
+		next.ServeHTTP(w, r)
+	})
+}
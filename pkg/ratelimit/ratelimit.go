@@ -0,0 +1,128 @@
+package ratelimit
+
+import (
+	"math"
+	"net"
+	"net/http"
+	"sync"
+	"time"
+)
+
+const (
+	// pruneAfter - Buckets kept before idle ones are dropped
+	pruneAfter = 1024
+)
+
+// Rate - Burst tokens to start with, refilled at PerSecond.
+// The zero Rate never limits.
+type Rate struct {
+	PerSecond float64
+	Burst     int
+}
+
+// Bucket - A token bucket, every action takes a token
+type Bucket struct {
+	Rate   Rate
+	tokens float64
+	last   time.Time
+}
+
+// NewBucket - A full bucket
+func NewBucket(rate Rate) *Bucket {
+	return &Bucket{Rate: rate, tokens: float64(rate.Burst)}
+}
+
+// Take - Take a token at now, or how long until there's one to take
+func (b *Bucket) Take(now time.Time) (bool, time.Duration) {
+	if b.Rate.PerSecond <= 0 || b.Rate.Burst <= 0 {
+		return true, 0
+	}
+
+	b.refill(now)
+	if b.tokens >= 1 {
+		b.tokens--
+		return true, 0
+	}
+
+	wait := (1 - b.tokens) / b.Rate.PerSecond
+	return false, time.Duration(wait * float64(time.Second))
+}
+
+// full - Has the bucket refilled since it was last used
+func (b *Bucket) full(now time.Time) bool {
+	b.refill(now)
+
+	return b.tokens >= float64(b.Rate.Burst)
+}
+
+func (b *Bucket) refill(now time.Time) {
+	if !b.last.IsZero() {
+		b.tokens = math.Min(float64(b.Rate.Burst), b.tokens+now.Sub(b.last).Seconds()*b.Rate.PerSecond)
+	}
+	b.last = now
+}
+
+// Limiter - A bucket per key, usually the client's IP
+type Limiter struct {
+	Rate    Rate
+	Now     func() time.Time
+	buckets map[string]*Bucket
+	mu      sync.Mutex
+}
+
+func NewLimiter(rate Rate) *Limiter {
+	return &Limiter{
+		Rate:    rate,
+		Now:     time.Now,
+		buckets: make(map[string]*Bucket),
+	}
+}
+
+// Allow - Take a token for key, or how long until there's one to take
+func (l *Limiter) Allow(key string) (bool, time.Duration) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	now := l.Now()
+	if len(l.buckets) >= pruneAfter {
+		l.prune(now)
+	}
+	bucket, exists := l.buckets[key]
+	if !exists {
+		bucket = NewBucket(l.Rate)
+		l.buckets[key] = bucket
+	}
+
+	return bucket.Take(now)
+}
+
+// Middleware - Limit requests by IP, answering 429 once they run out
+func (l *Limiter) Middleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if ok, wait := l.Allow(IP(r)); !ok {
+			WriteTooManyRequests(w, wait, "Too many requests, slow down")
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// prune - Drop buckets that have refilled, they're the same as new ones.
+// Call with the lock held.
+func (l *Limiter) prune(now time.Time) {
+	for key, bucket := range l.buckets {
+		if bucket.full(now) {
+			delete(l.buckets, key)
+		}
+	}
+}
+
+// IP - The client's address, without the port
+func IP(r *http.Request) string {
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		return r.RemoteAddr
+	}
+
+	return host
+}
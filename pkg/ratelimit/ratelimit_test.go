@@ -0,0 +1,93 @@
+package ratelimit
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBucket(t *testing.T) {
+	assert := assert.New(t)
+
+	now := time.Now()
+	bucket := NewBucket(Rate{PerSecond: 2, Burst: 3})
+	for i := 0; i < 3; i++ {
+		ok, _ := bucket.Take(now)
+		assert.True(ok)
+	}
+	ok, wait := bucket.Take(now)
+	assert.False(ok)
+	assert.Equal(500*time.Millisecond, wait)
+
+	// Half a second buys one more
+	ok, _ = bucket.Take(now.Add(500 * time.Millisecond))
+	assert.True(ok)
+	ok, _ = bucket.Take(now.Add(500 * time.Millisecond))
+	assert.False(ok)
+
+	// Never more than the burst
+	for i := 0; i < 3; i++ {
+		ok, _ = bucket.Take(now.Add(time.Minute))
+		assert.True(ok)
+	}
+	ok, _ = bucket.Take(now.Add(time.Minute))
+	assert.False(ok)
+
+	// The zero Rate never limits
+	unlimited := NewBucket(Rate{})
+	for i := 0; i < 100; i++ {
+		ok, _ = unlimited.Take(now)
+		assert.True(ok)
+	}
+}
+
+func TestLimiterMiddleware(t *testing.T) {
+	assert := assert.New(t)
+
+	now := time.Now()
+	limiter := NewLimiter(Rate{PerSecond: 0.5, Burst: 1})
+	limiter.Now = func() time.Time { return now }
+	handler := limiter.Middleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+
+	serve := func(remoteAddr string) *httptest.ResponseRecorder {
+		request := httptest.NewRequest(http.MethodPost, "/join", nil)
+		request.RemoteAddr = remoteAddr
+		recorder := httptest.NewRecorder()
+		handler.ServeHTTP(recorder, request)
+		return recorder
+	}
+
+	assert.Equal(http.StatusOK, serve("10.0.0.1:5000").Code)
+	limited := serve("10.0.0.1:5001")
+	assert.Equal(http.StatusTooManyRequests, limited.Code)
+	assert.Equal("2", limited.Header().Get("Retry-After"))
+	assert.Contains(limited.Body.String(), `"retry_after":2`)
+
+	// Another IP has its own bucket
+	assert.Equal(http.StatusOK, serve("10.0.0.2:5000").Code)
+
+	now = now.Add(2 * time.Second)
+	assert.Equal(http.StatusOK, serve("10.0.0.1:5002").Code)
+}
+
+func TestConns(t *testing.T) {
+	assert := assert.New(t)
+
+	conns := NewConns(2)
+	assert.True(conns.Acquire("10.0.0.1"))
+	assert.True(conns.Acquire("10.0.0.1"))
+	assert.False(conns.Acquire("10.0.0.1"))
+	assert.True(conns.Acquire("10.0.0.2"))
+
+	conns.Release("10.0.0.1")
+	assert.Equal(1, conns.Open("10.0.0.1"))
+	assert.True(conns.Acquire("10.0.0.1"))
+
+	conns.Release("10.0.0.2")
+	assert.Equal(0, conns.Open("10.0.0.2"))
+}
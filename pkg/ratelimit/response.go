@@ -0,0 +1,30 @@
+package ratelimit
+
+import (
+	"math"
+	"net/http"
+	"strconv"
+	"time"
+
+	"networkgaming.co.uk/techtest/pkg/problem"
+)
+
+// RetryAfter - Whole seconds to wait, at least one
+func RetryAfter(wait time.Duration) int {
+	return int(math.Max(1, math.Ceil(wait.Seconds())))
+}
+
+// Problem - A rate_limited problem, telling the client how long to wait
+func Problem(wait time.Duration, detail string) problem.Problem {
+	limited := problem.New(problem.CodeRateLimited, http.StatusTooManyRequests, detail).Problem()
+	limited.RetryAfter = RetryAfter(wait)
+
+	return limited
+}
+
+// WriteTooManyRequests - 429 with a Retry-After header
+func WriteTooManyRequests(w http.ResponseWriter, wait time.Duration, detail string) {
+	limited := Problem(wait, detail)
+	w.Header().Set("Retry-After", strconv.Itoa(limited.RetryAfter))
+	problem.WriteProblem(w, limited)
+}
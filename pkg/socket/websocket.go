@@ -1,16 +1,26 @@
 package socket
 
 import (
+	"encoding/json"
 	"log"
 	"net/http"
+	"time"
 
+	"github.com/go-chi/chi"
 	"github.com/gorilla/websocket"
 	"networkgaming.co.uk/techtest/pkg/game"
+	"networkgaming.co.uk/techtest/pkg/matchmaking"
+	"networkgaming.co.uk/techtest/pkg/problem"
+	"networkgaming.co.uk/techtest/pkg/ratelimit"
+	"networkgaming.co.uk/techtest/pkg/tournament"
 )
 
+// GameWebSocketHandler - Commands limits how fast each connection may send, the zero Rate never limits
 type GameWebSocketHandler struct {
 	Upgrader    websocket.Upgrader
 	Broadcaster *game.Broadcaster
+	Lobby       *game.Lobby
+	Commands    ratelimit.Rate
 }
 
 func New(broadcaster *game.Broadcaster) *GameWebSocketHandler {
@@ -21,8 +31,57 @@ func New(broadcaster *game.Broadcaster) *GameWebSocketHandler {
 	}
 }
 
+// ChatRequest - Sent by subscribers over the socket, {"type":"chat","text":"..."}
+type ChatRequest struct {
+	Type string `json:"type"`
+	Text string `json:"text"`
+}
+
+// Subscribe - Game events for the main room, or ?room=[ID] when there's a lobby.
+// Private rooms with a password also need ?password=[PASSWORD].
+// With a lobby the room's snapshot is sent first, and subscribers with the ?token=[TOKEN]
+// they were given when they joined the room can chat.
 func (gws *GameWebSocketHandler) Subscribe(w http.ResponseWriter, r *http.Request) {
 	//ctx := r.Context()
+	broadcaster := gws.Broadcaster
+	var room *game.Room
+	if gws.Lobby != nil {
+		id := r.URL.Query().Get("room")
+		if id == "" {
+			id = game.MainRoom
+		}
+		found, err := gws.Lobby.Room(id)
+		if err != nil {
+			problem.Write(w, err)
+			return
+		}
+		if err := found.Admit(r.URL.Query().Get("password")); err != nil {
+			problem.Write(w, err)
+			return
+		}
+		room = found
+		broadcaster = room.Broadcaster
+	}
+	var session *game.ChatSession
+	if token := r.URL.Query().Get("token"); token != "" && room != nil {
+		found, err := room.Chat.Session(token)
+		if err != nil {
+			problem.Write(w, err)
+			return
+		}
+		session = found
+	}
+
+	var snapshot *game.Snapshot
+	if room != nil {
+		observed, err := room.Observe(r.Context())
+		if err != nil {
+			problem.Write(w, err)
+			return
+		}
+		snapshot = observed
+	}
+
 	gws.Upgrader.CheckOrigin = func(r *http.Request) bool { return true }
 	sock, err := gws.Upgrader.Upgrade(w, r, nil)
 	if err != nil {
@@ -30,13 +89,32 @@ func (gws *GameWebSocketHandler) Subscribe(w http.ResponseWriter, r *http.Reques
 		return
 	}
 	//defer sock.Close()
-	gws.Broadcaster.SubChannel <- sock
+	if room != nil {
+		broadcaster.Whisper(sock, game.NewEvent(game.GameSnapshot, snapshot))
+	}
+	if !broadcaster.Subscribe(sock) {
+		game.HangUp(sock, game.ShutdownReason)
+		return
+	}
+	commands := ratelimit.NewBucket(gws.Commands)
 	for {
-		_, _, err := sock.ReadMessage()
+		_, message, err := sock.ReadMessage()
 		if err != nil {
 			log.Println("Websocket - Read:", err)
 			break
 		}
+		if ok, wait := commands.Take(time.Now()); !ok {
+			limited := ratelimit.Problem(wait, "Slow down, you're sending messages too quickly")
+			broadcaster.Whisper(sock, game.NewEvent(game.RateLimited, limited))
+			continue
+		}
+		request := ChatRequest{}
+		if room == nil || json.Unmarshal(message, &request) != nil || request.Type != "chat" {
+			continue
+		}
+		if err := room.Say(session, request.Text); err != nil {
+			broadcaster.Whisper(sock, game.NewEvent(game.ChatRejected, problem.From(err)))
+		}
 		// select {
 		// // case <-time.After(5 * time.Second):
 		// // 	fmt.Println("Socket - 5 second timeout!")
@@ -47,3 +125,87 @@ func (gws *GameWebSocketHandler) Subscribe(w http.ResponseWriter, r *http.Reques
 		// }
 	}
 }
+
+type TicketWebSocketHandler struct {
+	Upgrader   websocket.Upgrader
+	Matchmaker *matchmaking.Matchmaker
+}
+
+func NewTicketHandler(matchmaker *matchmaking.Matchmaker) *TicketWebSocketHandler {
+	upgrader := websocket.Upgrader{}
+	return &TicketWebSocketHandler{
+		Upgrader:   upgrader,
+		Matchmaker: matchmaker,
+	}
+}
+
+// Subscribe - Queue events for ?ticket=[ID], closed once the match is found.
+// The ID is the ticket's secret, only the player who queued it has it.
+func (tws *TicketWebSocketHandler) Subscribe(w http.ResponseWriter, r *http.Request) {
+	ticket, err := tws.Matchmaker.Ticket(r.URL.Query().Get("ticket"))
+	if err != nil {
+		problem.Write(w, err)
+		return
+	}
+
+	tws.Upgrader.CheckOrigin = func(r *http.Request) bool { return true }
+	sock, err := tws.Upgrader.Upgrade(w, r, nil)
+	if err != nil {
+		log.Println("upgrade:", err)
+		return
+	}
+	defer sock.Close()
+
+	shuttingDown := false
+	for event := range ticket.Events {
+		if err := sock.WriteJSON(event); err != nil {
+			log.Println("Websocket - Write:", err)
+			return
+		}
+		shuttingDown = event.Type == game.ServerShuttingDown.String()
+	}
+	if shuttingDown {
+		game.HangUp(sock, game.ShutdownReason)
+		return
+	}
+	sock.WriteMessage(websocket.CloseMessage, websocket.FormatCloseMessage(websocket.CloseNormalClosure, "Ticket closed"))
+}
+
+type TournamentWebSocketHandler struct {
+	Upgrader    websocket.Upgrader
+	Tournaments *tournament.Tournaments
+}
+
+func NewTournamentHandler(tournaments *tournament.Tournaments) *TournamentWebSocketHandler {
+	upgrader := websocket.Upgrader{}
+	return &TournamentWebSocketHandler{
+		Upgrader:    upgrader,
+		Tournaments: tournaments,
+	}
+}
+
+// Subscribe - Tournament events for /tournaments/{id}/subscribe
+func (tws *TournamentWebSocketHandler) Subscribe(w http.ResponseWriter, r *http.Request) {
+	t, err := tws.Tournaments.Get(chi.URLParam(r, "id"))
+	if err != nil {
+		problem.Write(w, err)
+		return
+	}
+
+	tws.Upgrader.CheckOrigin = func(r *http.Request) bool { return true }
+	sock, err := tws.Upgrader.Upgrade(w, r, nil)
+	if err != nil {
+		log.Println("upgrade:", err)
+		return
+	}
+	if !t.Broadcaster.Subscribe(sock) {
+		game.HangUp(sock, game.ShutdownReason)
+		return
+	}
+	for {
+		if _, _, err := sock.ReadMessage(); err != nil {
+			log.Println("Websocket - Read:", err)
+			break
+		}
+	}
+}
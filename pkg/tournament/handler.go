@@ -0,0 +1,177 @@
+package tournament
+
+import (
+	"encoding/json"
+	"log"
+	"net/http"
+
+	"github.com/go-chi/chi"
+	"networkgaming.co.uk/techtest/pkg/game"
+	"networkgaming.co.uk/techtest/pkg/problem"
+)
+
+// TournamentHandler - REST resource for /tournaments, creating, starting and cancelling need the admin token
+// TournamentHandler - Accounts is who players are staking as, see game.JoinGameHandler
+type TournamentHandler struct {
+	Accounts    game.Accounts
+	tournaments *Tournaments
+	admin       *game.AdminHandler
+}
+
+func NewTournamentHandler(tournaments *Tournaments, admin *game.AdminHandler) *TournamentHandler {
+	return &TournamentHandler{tournaments: tournaments, admin: admin}
+}
+
+type TournamentResponse struct {
+	Status     int    `json:"status"`
+	Type       string `json:"type"`
+	Title      string `json:"title"`
+	Detail     string `json:"detail"`
+	Tournament *View  `json:"tournament,omitempty"`
+}
+
+// List - GET /tournaments
+func (h *TournamentHandler) List(w http.ResponseWriter, r *http.Request) {
+
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Server", "NG: Small Browser Based Game Server")
+
+	views := []View{}
+	for _, t := range h.tournaments.List() {
+		views = append(views, t.View())
+	}
+
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(views)
+}
+
+// Create - POST /tournaments {name,format,table_size,rounds,advance}
+func (h *TournamentHandler) Create(w http.ResponseWriter, r *http.Request) {
+
+	if !h.admin.Authorized(w, r) {
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Server", "NG: Small Browser Based Game Server")
+
+	config := Config{}
+	if err := json.NewDecoder(r.Body).Decode(&config); err != nil {
+		log.Printf("Create Tournament - Error decoding json %s", err.Error())
+		problem.Write(w, problem.InvalidJSON(err))
+		return
+	}
+
+	t, err := h.tournaments.Create(config)
+	if err != nil {
+		problem.Write(w, err)
+		return
+	}
+
+	view := t.View()
+	writeResponse(w, http.StatusCreated, TournamentResponse{
+		Type:       "Success",
+		Title:      "Tournament Created",
+		Detail:     "Registration is open",
+		Tournament: &view,
+	})
+}
+
+// Get - GET /tournaments/{id}
+func (h *TournamentHandler) Get(w http.ResponseWriter, r *http.Request) {
+
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Server", "NG: Small Browser Based Game Server")
+
+	t, err := h.tournaments.Get(chi.URLParam(r, "id"))
+	if err != nil {
+		problem.Write(w, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(t.View())
+}
+
+// Register - POST /tournaments/{id}/register, takes the same body as POST /join
+func (h *TournamentHandler) Register(w http.ResponseWriter, r *http.Request) {
+
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Server", "NG: Small Browser Based Game Server")
+
+	request := new(game.JoinGameRequest)
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		log.Printf("Register Tournament - Error decoding json %s", err.Error())
+		problem.Write(w, problem.InvalidJSON(err))
+		return
+	}
+
+	account, err := game.Account(h.Accounts, r)
+	if err != nil {
+		problem.Write(w, err)
+		return
+	}
+
+	id := chi.URLParam(r, "id")
+	err = h.tournaments.Register(id, &game.Player{
+		Name:    request.Name,
+		First:   request.First,
+		Second:  request.Second,
+		Picks:   request.Picks,
+		Range:   request.Range,
+		Account: account,
+	})
+	if err != nil {
+		log.Printf("Register Tournament Error: %s", err.Error())
+		problem.Write(w, err)
+		return
+	}
+
+	writeResponse(w, http.StatusOK, TournamentResponse{
+		Type:   "Success",
+		Title:  "Registered",
+		Detail: "You'll be seated when the tournament starts",
+	})
+}
+
+// Start - POST /tournaments/{id}/start
+func (h *TournamentHandler) Start(w http.ResponseWriter, r *http.Request) {
+	h.change(w, r, h.tournaments.Start, "Tournament Started")
+}
+
+// Cancel - DELETE /tournaments/{id}
+func (h *TournamentHandler) Cancel(w http.ResponseWriter, r *http.Request) {
+	h.change(w, r, h.tournaments.Cancel, "Tournament Cancelled")
+}
+
+// change - Admin only state changes, responding with the updated tournament
+func (h *TournamentHandler) change(w http.ResponseWriter, r *http.Request, action func(id string) error, title string) {
+
+	if !h.admin.Authorized(w, r) {
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Server", "NG: Small Browser Based Game Server")
+
+	id := chi.URLParam(r, "id")
+	if err := action(id); err != nil {
+		problem.Write(w, err)
+		return
+	}
+
+	t, _ := h.tournaments.Get(id)
+	view := t.View()
+	writeResponse(w, http.StatusOK, TournamentResponse{
+		Type:       "Success",
+		Title:      title,
+		Detail:     string(view.State),
+		Tournament: &view,
+	})
+}
+
+func writeResponse(w http.ResponseWriter, status int, response TournamentResponse) {
+	response.Status = status
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(response)
+}
@@ -0,0 +1,251 @@
+package tournament
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"sort"
+	"sync"
+
+	"networkgaming.co.uk/techtest/pkg/game"
+)
+
+// Rooms - Where each table is played, see game.Lobby
+type Rooms interface {
+	Validate(player *game.Player) error
+	Seat(players []*game.Player) (string, error)
+	Close(id string) error
+}
+
+// Tournaments - Every tournament, driving their tables through rooms.
+// Implements game.ResultListener, add it to the listeners of every room it may seat.
+type Tournaments struct {
+	ctx         context.Context
+	rooms       Rooms
+	tournaments map[string]*Tournament
+	tables      map[string]*Tournament
+	next        int
+	mu          sync.Mutex
+}
+
+// NewTournaments - Tournament broadcasters run until ctx is done
+func NewTournaments(ctx context.Context, rooms Rooms) *Tournaments {
+	return &Tournaments{
+		ctx:         ctx,
+		rooms:       rooms,
+		tournaments: make(map[string]*Tournament),
+		tables:      make(map[string]*Tournament),
+	}
+}
+
+// Create - Open a tournament for registration
+func (ts *Tournaments) Create(config Config) (*Tournament, error) {
+	if err := config.validate(); err != nil {
+		return nil, err
+	}
+
+	ts.mu.Lock()
+	ts.next++
+	t := newTournament(fmt.Sprintf("tournament-%d", ts.next), config)
+	ts.tournaments[t.ID] = t
+	ts.mu.Unlock()
+
+	t.Broadcaster.Start(ts.ctx)
+
+	return t, nil
+}
+
+// Get - Look up a tournament
+func (ts *Tournaments) Get(id string) (*Tournament, error) {
+	ts.mu.Lock()
+	defer ts.mu.Unlock()
+
+	t, exists := ts.tournaments[id]
+	if !exists {
+		return nil, ErrTournamentNotFound
+	}
+
+	return t, nil
+}
+
+// List - Every tournament, oldest first
+func (ts *Tournaments) List() []*Tournament {
+	ts.mu.Lock()
+	defer ts.mu.Unlock()
+
+	list := make([]*Tournament, 0, len(ts.tournaments))
+	for _, t := range ts.tournaments {
+		list = append(list, t)
+	}
+	sort.Slice(list, func(i, j int) bool {
+		return list[i].ID < list[j].ID
+	})
+
+	return list
+}
+
+// Register - Enter a player, their picks are used for every game they play
+func (ts *Tournaments) Register(id string, player *game.Player) error {
+	t, err := ts.Get(id)
+	if err != nil {
+		return err
+	}
+	if err := ts.rooms.Validate(player); err != nil {
+		return err
+	}
+	if err := t.register(player); err != nil {
+		return err
+	}
+	t.emit(game.NewEvent(game.PlayerRegistered, player.Name))
+
+	return nil
+}
+
+// Start - Close registration and seat the first round
+func (ts *Tournaments) Start(id string) error {
+	t, err := ts.Get(id)
+	if err != nil {
+		return err
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if t.state != StateRegistering {
+		return ErrRegistrationClosed
+	}
+	if len(t.entrants) < game.MinPlayersRequired {
+		return ErrNotEnoughEntrants
+	}
+	t.state = StateRunning
+	t.emit(game.NewEvent(game.TournamentStarted, t.view()))
+
+	return ts.seat(t)
+}
+
+// Cancel - Stop the tournament and close any tables still being played
+func (ts *Tournaments) Cancel(id string) error {
+	t, err := ts.Get(id)
+	if err != nil {
+		return err
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if t.state == StateCompleted || t.state == StateCancelled {
+		return ErrTournamentNotActive
+	}
+	t.state = StateCancelled
+	if len(t.rounds) > 0 {
+		ts.close(t.rounds[len(t.rounds)-1])
+	}
+
+	return nil
+}
+
+// GameCompleted - Record results for tournament tables.
+// Runs apart from the engine loop as closing the room waits on the engine.
+func (ts *Tournaments) GameCompleted(result game.GameResult) {
+	ts.mu.Lock()
+	_, exists := ts.tables[result.Room]
+	ts.mu.Unlock()
+
+	if exists {
+		go ts.record(result)
+	}
+}
+
+// record - Update the standings and move the tournament on once the round is complete
+func (ts *Tournaments) record(result game.GameResult) {
+	ts.mu.Lock()
+	t, exists := ts.tables[result.Room]
+	delete(ts.tables, result.Room)
+	ts.mu.Unlock()
+
+	if !exists {
+		return
+	}
+	if err := ts.rooms.Close(result.Room); err != nil {
+		log.Printf("Tournament - Unable to close room %s: %s\n", result.Room, err.Error())
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if t.state != StateRunning {
+		return
+	}
+
+	complete := false
+	round := t.rounds[len(t.rounds)-1]
+	for _, table := range round.Tables {
+		if table.Room == result.Room && table.Result == nil {
+			complete = t.record(table, result)
+			t.emit(game.NewEvent(game.TournamentGameCompleted, *table))
+		}
+	}
+	if !complete {
+		return
+	}
+
+	if t.finished() {
+		t.emit(game.NewEvent(game.TournamentCompleted, t.view()))
+		return
+	}
+	if err := ts.seat(t); err != nil {
+		log.Printf("Tournament - Unable to seat round %d of %s: %s\n", len(t.rounds), t.ID, err.Error())
+	}
+}
+
+// seat - Draw the next round and open a room for each table.
+// The tournament is cancelled if a table can't be seated, call with its lock held.
+func (ts *Tournaments) seat(t *Tournament) error {
+	round := &Round{Number: len(t.rounds) + 1, Tables: []*Table{}}
+	t.rounds = append(t.rounds, round)
+
+	for _, players := range t.draw() {
+		table := &Table{Players: make([]string, len(players))}
+		for i, player := range players {
+			table.Players[i] = player.Name
+		}
+		round.Tables = append(round.Tables, table)
+
+		// Track the room before anyone can finish playing in it
+		ts.mu.Lock()
+		room, err := ts.rooms.Seat(players)
+		if room != "" {
+			table.Room = room
+			ts.tables[room] = t
+		}
+		ts.mu.Unlock()
+
+		if err != nil {
+			t.state = StateCancelled
+			ts.close(round)
+			return err
+		}
+	}
+	t.emit(game.NewEvent(game.TournamentRoundStarted, t.view()))
+
+	return nil
+}
+
+// close - Close the rooms of any tables in round that are still being played
+func (ts *Tournaments) close(round *Round) {
+	open := []string{}
+	ts.mu.Lock()
+	for _, table := range round.Tables {
+		if _, playing := ts.tables[table.Room]; playing {
+			open = append(open, table.Room)
+			delete(ts.tables, table.Room)
+		}
+	}
+	ts.mu.Unlock()
+
+	for _, room := range open {
+		if err := ts.rooms.Close(room); err != nil {
+			log.Printf("Tournament - Unable to close room %s: %s\n", room, err.Error())
+		}
+	}
+}
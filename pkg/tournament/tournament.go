@@ -0,0 +1,393 @@
+package tournament
+
+import (
+	"net/http"
+	"sort"
+	"sync"
+
+	"networkgaming.co.uk/techtest/pkg/game"
+	"networkgaming.co.uk/techtest/pkg/problem"
+)
+
+const (
+	FormatKnockout = "knockout"
+	FormatSwiss    = "swiss"
+
+	DefaultTableSize = 4
+	DefaultRounds    = 3
+	DefaultAdvance   = 1
+
+	// tournamentEvents - Buffered so a slow broadcaster never holds up the tournament
+	tournamentEvents = 64
+)
+
+// State - Where the tournament is up to
+type State string
+
+const (
+	StateRegistering State = "registering"
+	StateRunning     State = "running"
+	StateCompleted   State = "completed"
+	StateCancelled   State = "cancelled"
+)
+
+var (
+	ErrUnknownFormat       = problem.New("unknown_format", http.StatusUnprocessableEntity, "Invalid tournament: Format must be knockout or swiss")
+	ErrInvalidTableSize    = problem.New("invalid_table_size", http.StatusUnprocessableEntity, "Invalid tournament: Tables must seat at least two players")
+	ErrInvalidAdvance      = problem.New("invalid_advance", http.StatusUnprocessableEntity, "Invalid tournament: Advance must leave at least one player out of each table")
+	ErrInvalidRounds       = problem.New("invalid_rounds", http.StatusUnprocessableEntity, "Invalid tournament: Swiss tournaments need at least one round")
+	ErrRegistrationClosed  = problem.New("registration_closed", http.StatusConflict, "Invalid action: Tournament registration is closed")
+	ErrAlreadyRegistered   = problem.New("name_taken", http.StatusConflict, "Invalid action: There is already a player registered with that name")
+	ErrNotEnoughEntrants   = problem.New("not_enough_entrants", http.StatusConflict, "Invalid action: Not enough players registered for the tournament")
+	ErrTournamentNotFound  = problem.New("tournament_not_found", http.StatusNotFound, "Invalid request: No tournament with that ID")
+	ErrTournamentNotActive = problem.New("tournament_not_active", http.StatusConflict, "Invalid action: Tournament is not running")
+)
+
+// Config - How the tournament is played.
+// Knockout tournaments advance the top Advance players from each table until one table
+// is left, Swiss tournaments play Rounds rounds with players seated by their standing.
+type Config struct {
+	Name      string `json:"name"`
+	Format    string `json:"format"`
+	TableSize int    `json:"table_size"`
+	Rounds    int    `json:"rounds,omitempty"`
+	Advance   int    `json:"advance,omitempty"`
+}
+
+// validate - Fill in defaults and check the rules make sense
+func (c *Config) validate() error {
+	if c.Format == "" {
+		c.Format = FormatKnockout
+	}
+	if c.TableSize == 0 {
+		c.TableSize = DefaultTableSize
+	}
+	if c.TableSize < game.MinPlayersRequired {
+		return ErrInvalidTableSize
+	}
+
+	switch c.Format {
+	case FormatKnockout:
+		if c.Advance == 0 {
+			c.Advance = DefaultAdvance
+		}
+		if c.Advance < 1 || c.Advance >= c.TableSize {
+			return ErrInvalidAdvance
+		}
+	case FormatSwiss:
+		if c.Rounds == 0 {
+			c.Rounds = DefaultRounds
+		}
+		if c.Rounds < 1 {
+			return ErrInvalidRounds
+		}
+	default:
+		return ErrUnknownFormat
+	}
+
+	return nil
+}
+
+// Standing - A player's cumulative results.
+// Points are the number of players beaten at each table, Score is the total game score.
+type Standing struct {
+	Name       string `json:"name"`
+	Points     int    `json:"points"`
+	Score      int    `json:"score"`
+	Wins       int    `json:"wins"`
+	Games      int    `json:"games"`
+	Eliminated bool   `json:"eliminated"`
+}
+
+// Table - One game in a round, played in its own room
+type Table struct {
+	Room    string           `json:"room"`
+	Players []string         `json:"players"`
+	Result  *game.GameResult `json:"result,omitempty"`
+}
+
+// Round - Every table played at the same time
+type Round struct {
+	Number int      `json:"number"`
+	Tables []*Table `json:"tables"`
+}
+
+// complete - Whether every table has a result
+func (r *Round) complete() bool {
+	for _, table := range r.Tables {
+		if table.Result == nil {
+			return false
+		}
+	}
+
+	return true
+}
+
+// View - A copy of the tournament for the REST resource and events
+type View struct {
+	ID        string     `json:"id"`
+	Config    Config     `json:"config"`
+	State     State      `json:"state"`
+	Champion  string     `json:"champion,omitempty"`
+	Entrants  []string   `json:"entrants"`
+	Standings []Standing `json:"standings"`
+	Rounds    []Round    `json:"rounds"`
+}
+
+// Tournament - Players registered for a series of games, with their standings carried between them
+type Tournament struct {
+	ID          string
+	Config      Config
+	Event       chan *game.Event
+	Broadcaster *game.Broadcaster
+	state       State
+	champion    string
+	rounds      []*Round
+	entrants    []*game.Player
+	standings   map[string]*Standing
+	mu          sync.Mutex
+}
+
+// newTournament - Open for registration
+func newTournament(id string, config Config) *Tournament {
+	event := make(chan *game.Event, tournamentEvents)
+
+	return &Tournament{
+		ID:          id,
+		Config:      config,
+		Event:       event,
+		Broadcaster: game.NewBroadcaster(event),
+		state:       StateRegistering,
+		rounds:      make([]*Round, 0),
+		entrants:    make([]*game.Player, 0),
+		standings:   make(map[string]*Standing),
+	}
+}
+
+// View - Snapshot of the tournament
+func (t *Tournament) View() View {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	return t.view()
+}
+
+func (t *Tournament) view() View {
+	entrants := make([]string, len(t.entrants))
+	for i, player := range t.entrants {
+		entrants[i] = player.Name
+	}
+
+	rounds := make([]Round, len(t.rounds))
+	for i, round := range t.rounds {
+		tables := make([]*Table, len(round.Tables))
+		for j, table := range round.Tables {
+			copied := *table
+			tables[j] = &copied
+		}
+		rounds[i] = Round{Number: round.Number, Tables: tables}
+	}
+
+	return View{
+		ID:        t.ID,
+		Config:    t.Config,
+		State:     t.state,
+		Champion:  t.champion,
+		Entrants:  entrants,
+		Standings: t.ranked(),
+		Rounds:    rounds,
+	}
+}
+
+// State - Where the tournament is up to
+func (t *Tournament) State() State {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	return t.state
+}
+
+// register - Add a player while registration is open
+func (t *Tournament) register(player *game.Player) error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if t.state != StateRegistering {
+		return ErrRegistrationClosed
+	}
+	if _, exists := t.standings[player.Name]; exists {
+		return ErrAlreadyRegistered
+	}
+
+	t.entrants = append(t.entrants, player)
+	t.standings[player.Name] = &Standing{Name: player.Name}
+
+	return nil
+}
+
+// ranked - Standings best first, ties go to whoever registered first.
+// Call with the lock held.
+func (t *Tournament) ranked() []Standing {
+	ranked := make([]Standing, len(t.entrants))
+	for i, player := range t.entrants {
+		ranked[i] = *t.standings[player.Name]
+	}
+	sort.SliceStable(ranked, func(i, j int) bool {
+		if ranked[i].Eliminated != ranked[j].Eliminated {
+			return !ranked[i].Eliminated
+		}
+		if ranked[i].Points != ranked[j].Points {
+			return ranked[i].Points > ranked[j].Points
+		}
+		return ranked[i].Score > ranked[j].Score
+	})
+
+	return ranked
+}
+
+// draw - Split the players still in into tables for the next round.
+// Swiss tables are filled in standing order so players meet others on similar points,
+// knockout tables are dealt in a snake so the top seeds are kept apart.
+// Call with the lock held.
+func (t *Tournament) draw() [][]*game.Player {
+	players := []*game.Player{}
+	for _, standing := range t.ranked() {
+		if standing.Eliminated {
+			continue
+		}
+		for _, player := range t.entrants {
+			if player.Name == standing.Name {
+				players = append(players, player)
+			}
+		}
+	}
+
+	count := (len(players) + t.Config.TableSize - 1) / t.Config.TableSize
+	for count > 1 && len(players)/count < game.MinPlayersRequired {
+		count--
+	}
+	tables := make([][]*game.Player, count)
+
+	if t.Config.Format == FormatSwiss {
+		for i := 0; i < count; i++ {
+			tables[i] = players[i*len(players)/count : (i+1)*len(players)/count]
+		}
+		return tables
+	}
+
+	for i, player := range players {
+		table := i % count
+		if (i/count)%2 == 1 {
+			table = count - 1 - table
+		}
+		tables[table] = append(tables[table], player)
+	}
+
+	return tables
+}
+
+// record - Add a table's result to the standings, true once the round is complete.
+// Call with the lock held.
+func (t *Tournament) record(table *Table, result game.GameResult) bool {
+	table.Result = &result
+
+	placed := placings(table, result)
+	for place, name := range placed {
+		standing := t.standings[name]
+		standing.Points += len(placed) - 1 - place
+		standing.Games++
+		if name == result.Winner.Name {
+			standing.Wins++
+		}
+		for _, player := range result.LeaderBoard {
+			if player.Name == name {
+				standing.Score += player.Score
+			}
+		}
+	}
+
+	round := t.rounds[len(t.rounds)-1]
+	if !round.complete() {
+		return false
+	}
+
+	if t.Config.Format == FormatKnockout {
+		for _, table := range round.Tables {
+			placed := placings(table, *table.Result)
+			advance := t.Config.Advance
+			if advance > len(placed)-1 {
+				advance = len(placed) - 1
+			}
+			for _, name := range placed[advance:] {
+				t.standings[name].Eliminated = true
+			}
+		}
+	}
+
+	return true
+}
+
+// finished - Whether the last complete round was the final one, naming the champion if so.
+// Call with the lock held.
+func (t *Tournament) finished() bool {
+	round := t.rounds[len(t.rounds)-1]
+
+	switch t.Config.Format {
+	case FormatKnockout:
+		if len(round.Tables) > 1 {
+			return false
+		}
+		t.champion = round.Tables[0].Result.Winner.Name
+	case FormatSwiss:
+		if len(t.rounds) < t.Config.Rounds {
+			return false
+		}
+		t.champion = t.ranked()[0].Name
+	}
+	t.state = StateCompleted
+
+	return true
+}
+
+// emit - Never blocks, the tournament carries on if no one is listening
+func (t *Tournament) emit(event *game.Event) {
+	select {
+	case t.Event <- event:
+	default:
+	}
+}
+
+// placings - Names at a table best first, the winner then everyone else by score.
+// Anyone seated who isn't on the leader board finishes last.
+func placings(table *Table, result game.GameResult) []string {
+	board := append([]game.GamePlayer{}, result.LeaderBoard...)
+	sort.SliceStable(board, func(i, j int) bool {
+		if board[i].Name == result.Winner.Name {
+			return true
+		}
+		if board[j].Name == result.Winner.Name {
+			return false
+		}
+		return board[i].Score > board[j].Score
+	})
+
+	seated := make(map[string]bool)
+	for _, name := range table.Players {
+		seated[name] = true
+	}
+
+	placed := []string{}
+	for _, player := range board {
+		if seated[player.Name] {
+			placed = append(placed, player.Name)
+			delete(seated, player.Name)
+		}
+	}
+	for _, name := range table.Players {
+		if seated[name] {
+			placed = append(placed, name)
+		}
+	}
+
+	return placed
+}
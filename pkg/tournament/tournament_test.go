@@ -0,0 +1,180 @@
+package tournament
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"networkgaming.co.uk/techtest/pkg/game"
+)
+
+type mockRooms struct {
+	seated map[string][]string
+	closed []string
+	next   int
+}
+
+func newMockRooms() *mockRooms {
+	return &mockRooms{seated: make(map[string][]string)}
+}
+
+func (mr *mockRooms) Validate(player *game.Player) error {
+	return game.NewGame(nil).ValidatePlayer(player)
+}
+
+func (mr *mockRooms) Seat(players []*game.Player) (string, error) {
+	mr.next++
+	id := fmt.Sprintf("room-%d", mr.next)
+	for _, player := range players {
+		mr.seated[id] = append(mr.seated[id], player.Name)
+	}
+
+	return id, nil
+}
+
+func (mr *mockRooms) Close(id string) error {
+	mr.closed = append(mr.closed, id)
+	return nil
+}
+
+// play - Complete a table, players score in the order given and the first wins
+func play(ts *Tournaments, room string, names ...string) {
+	board := []game.GamePlayer{}
+	for i, name := range names {
+		board = append(board, game.GamePlayer{Name: name, Score: 10 - i})
+	}
+	ts.record(game.GameResult{Room: room, Winner: board[0], LeaderBoard: board})
+}
+
+func register(t *testing.T, ts *Tournaments, id string, names ...string) {
+	for _, name := range names {
+		assert.Nil(t, ts.Register(id, &game.Player{Name: name, First: 3, Second: 7}))
+	}
+}
+
+func TestTournamentConfig(t *testing.T) {
+	assert := assert.New(t)
+
+	ts := NewTournaments(context.Background(), newMockRooms())
+
+	_, err := ts.Create(Config{Format: "league"})
+	assert.Equal(ErrUnknownFormat, err)
+	_, err = ts.Create(Config{TableSize: 1})
+	assert.Equal(ErrInvalidTableSize, err)
+	_, err = ts.Create(Config{TableSize: 4, Advance: 4})
+	assert.Equal(ErrInvalidAdvance, err)
+
+	tournament, err := ts.Create(Config{Name: "Weekly"})
+	assert.Nil(err)
+	assert.Equal(Config{Name: "Weekly", Format: FormatKnockout, TableSize: DefaultTableSize, Advance: DefaultAdvance}, tournament.Config)
+	assert.Equal(StateRegistering, tournament.State())
+}
+
+func TestTournamentRegistration(t *testing.T) {
+	assert := assert.New(t)
+
+	ts := NewTournaments(context.Background(), newMockRooms())
+	tournament, _ := ts.Create(Config{})
+
+	assert.Equal(ErrTournamentNotFound, ts.Register("nope", &game.Player{Name: "Steve", First: 3, Second: 7}))
+	assert.Equal(game.ErrInvalidNumber, ts.Register(tournament.ID, &game.Player{Name: "Steve", First: 3, Second: 70}))
+	register(t, ts, tournament.ID, "Steve")
+	assert.Equal(ErrAlreadyRegistered, ts.Register(tournament.ID, &game.Player{Name: "Steve", First: 3, Second: 7}))
+	assert.Equal(ErrNotEnoughEntrants, ts.Start(tournament.ID))
+
+	register(t, ts, tournament.ID, "Sarah")
+	assert.Nil(ts.Start(tournament.ID))
+	assert.Equal(ErrRegistrationClosed, ts.Register(tournament.ID, &game.Player{Name: "Simon", First: 3, Second: 7}))
+	assert.Equal(ErrRegistrationClosed, ts.Start(tournament.ID))
+}
+
+func TestTournamentKnockout(t *testing.T) {
+	assert := assert.New(t)
+
+	rooms := newMockRooms()
+	ts := NewTournaments(context.Background(), rooms)
+	tournament, _ := ts.Create(Config{Format: FormatKnockout, TableSize: 4, Advance: 1})
+	register(t, ts, tournament.ID, "Ann", "Bob", "Cat", "Dan", "Eve", "Fay", "Gus", "Hal")
+
+	assert.Nil(ts.Start(tournament.ID))
+	assert.Equal(StateRunning, tournament.State())
+
+	// Seeds are dealt in a snake
+	assert.Equal([]string{"Ann", "Dan", "Eve", "Hal"}, rooms.seated["room-1"])
+	assert.Equal([]string{"Bob", "Cat", "Fay", "Gus"}, rooms.seated["room-2"])
+
+	// Results for rooms that aren't tournament tables are ignored
+	play(ts, "room-99", "Ann", "Dan")
+	play(ts, "room-1", "Eve", "Ann", "Dan", "Hal")
+	assert.Len(tournament.View().Rounds, 1)
+	play(ts, "room-2", "Cat", "Bob", "Gus", "Fay")
+
+	// The final
+	view := tournament.View()
+	assert.Len(view.Rounds, 2)
+	assert.Equal([]string{"room-1", "room-2"}, rooms.closed)
+	assert.Equal([]string{"Cat", "Eve"}, rooms.seated["room-3"])
+	eliminated := 0
+	for _, standing := range view.Standings {
+		if standing.Eliminated {
+			eliminated++
+		}
+	}
+	assert.Equal(6, eliminated)
+
+	play(ts, "room-3", "Cat", "Eve")
+	view = tournament.View()
+	assert.Equal(StateCompleted, view.State)
+	assert.Equal("Cat", view.Champion)
+	assert.Equal(Standing{Name: "Cat", Points: 4, Score: 20, Wins: 2, Games: 2}, view.Standings[0])
+}
+
+func TestTournamentSwiss(t *testing.T) {
+	assert := assert.New(t)
+
+	rooms := newMockRooms()
+	ts := NewTournaments(context.Background(), rooms)
+	tournament, _ := ts.Create(Config{Format: FormatSwiss, TableSize: 2, Rounds: 2})
+	register(t, ts, tournament.ID, "Ann", "Bob", "Cat", "Dan", "Eve")
+
+	assert.Nil(ts.Start(tournament.ID))
+
+	// Five players at tables of two, one table has three
+	assert.Equal([]string{"Ann", "Bob"}, rooms.seated["room-1"])
+	assert.Equal([]string{"Cat", "Dan", "Eve"}, rooms.seated["room-2"])
+	play(ts, "room-1", "Bob", "Ann")
+	play(ts, "room-2", "Eve", "Dan", "Cat")
+
+	// Round two pairs players on similar points
+	assert.Equal([]string{"Eve", "Bob"}, rooms.seated["room-3"])
+	assert.Equal([]string{"Dan", "Ann", "Cat"}, rooms.seated["room-4"])
+	play(ts, "room-3", "Eve", "Bob")
+	play(ts, "room-4", "Ann", "Dan", "Cat")
+
+	view := tournament.View()
+	assert.Equal(StateCompleted, view.State)
+	assert.Equal("Eve", view.Champion)
+	assert.Equal(5, len(view.Standings))
+	assert.Equal("Cat", view.Standings[4].Name)
+}
+
+func TestTournamentCancel(t *testing.T) {
+	assert := assert.New(t)
+
+	rooms := newMockRooms()
+	ts := NewTournaments(context.Background(), rooms)
+	tournament, _ := ts.Create(Config{TableSize: 2})
+	register(t, ts, tournament.ID, "Ann", "Bob", "Cat", "Dan")
+
+	assert.Nil(ts.Start(tournament.ID))
+	play(ts, "room-1", "Ann", "Dan")
+	assert.Nil(ts.Cancel(tournament.ID))
+	assert.Equal([]string{"room-1", "room-2"}, rooms.closed)
+	assert.Equal(StateCancelled, tournament.State())
+	assert.Equal(ErrTournamentNotActive, ts.Cancel(tournament.ID))
+
+	// Too late to count
+	play(ts, "room-2", "Bob", "Cat")
+	assert.Nil(tournament.View().Rounds[0].Tables[1].Result)
+}
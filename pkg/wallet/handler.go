@@ -0,0 +1,85 @@
+package wallet
+
+import (
+	"encoding/json"
+	"log"
+	"net/http"
+	"strings"
+
+	"networkgaming.co.uk/techtest/pkg/problem"
+)
+
+type WalletHandler struct {
+	wallet *Wallet
+}
+
+func NewWalletHandler(wallet *Wallet) *WalletHandler {
+	return &WalletHandler{wallet}
+}
+
+// Open - POST /wallet, the response has the new account and its token
+func (h *WalletHandler) Open(w http.ResponseWriter, r *http.Request) {
+
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Server", "NG: Small Browser Based Game Server")
+
+	account, err := h.wallet.Open()
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(account)
+}
+
+// GetBalance - GET /wallet with the account's token as Authorization: Bearer [TOKEN]
+func (h *WalletHandler) GetBalance(w http.ResponseWriter, r *http.Request) {
+
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Server", "NG: Small Browser Based Game Server")
+
+	account, err := h.wallet.Authenticate(bearer(r))
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	balance, err := h.wallet.Balance(account)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(balance)
+}
+
+// GetTransactions - GET /wallet/transactions with the account's token, see GetBalance
+func (h *WalletHandler) GetTransactions(w http.ResponseWriter, r *http.Request) {
+
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Server", "NG: Small Browser Based Game Server")
+
+	account, err := h.wallet.Authenticate(bearer(r))
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	transactions, err := h.wallet.Transactions(account)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(transactions)
+}
+
+func bearer(r *http.Request) string {
+	return strings.TrimPrefix(r.Header.Get("Authorization"), "Bearer ")
+}
+
+func writeError(w http.ResponseWriter, err error) {
+	log.Printf("Wallet Error: %s", err.Error())
+	problem.Write(w, err)
+}
@@ -0,0 +1,231 @@
+package wallet
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+)
+
+// SplitRule - How the pot is shared when the top score is tied
+type SplitRule int
+
+// SplitRules
+const (
+	// SplitWinnerTakesAll - The nominated winner takes it all, ties are settled by NominateWinner
+	SplitWinnerTakesAll SplitRule = 0
+	// SplitEven - Shared evenly between the winner and everyone on their score, any odd remainder goes to the winner
+	SplitEven SplitRule = 1
+)
+
+// PotConfig - Parameters for wagering on games
+type PotConfig struct {
+	Stake        int64
+	RakePercent  int64
+	Split        SplitRule
+	HouseAccount string
+}
+
+// Pot - Takes stakes from players as they join a game and pays out when it ends.
+// Implements game.Stakes.
+type Pot struct {
+	Config *PotConfig
+	wallet *Wallet
+	id     string
+	game   int
+	holds  map[string]string
+	mu     sync.Mutex
+}
+
+// NewPot - Pot drawing stakes from wallet
+func NewPot(wallet *Wallet, config *PotConfig) *Pot {
+	return &Pot{
+		Config: config,
+		wallet: wallet,
+		id:     fmt.Sprintf("pot-%d", time.Now().UnixNano()),
+		holds:  make(map[string]string),
+	}
+}
+
+// Stake - Hold the player's stake for the current game from their account
+func (p *Pot) Stake(name string, account string) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if _, staked := p.holds[name]; staked {
+		return nil
+	}
+	if account == "" {
+		return ErrInvalidToken
+	}
+
+	id := p.txID("hold", name)
+	if _, err := p.wallet.Hold(id, account, p.Config.Stake, p.reference()); err != nil {
+		return err
+	}
+	p.holds[name] = id
+
+	return nil
+}
+
+// Unstake - Release the player's stake, when their join fails after staking
+func (p *Pot) Unstake(name string) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	holdID, staked := p.holds[name]
+	if !staked {
+		return nil
+	}
+	if _, err := p.wallet.Release(p.txID("release", name), holdID); err != nil {
+		return err
+	}
+	delete(p.holds, name)
+
+	return nil
+}
+
+// Payout - Collect every stake, take the rake and pay the rest out per the split rule.
+// tied is everyone on the winner's score, including winner, the winner takes it all otherwise.
+func (p *Pot) Payout(winner string, tied []string) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	// Paid into the accounts the stakes came from
+	var total int64
+	accounts := make(map[string]string, len(p.holds))
+	for _, name := range p.names() {
+		tx, err := p.wallet.Settle(p.txID("settle", name), p.holds[name])
+		if err != nil {
+			return err
+		}
+		total += tx.Amount
+		accounts[name] = tx.Account
+	}
+
+	rake := total * p.Config.RakePercent / 100
+	if rake > 0 && p.Config.HouseAccount != "" {
+		if _, err := p.wallet.Credit(p.txID("rake", p.Config.HouseAccount), p.Config.HouseAccount, rake, p.reference()); err != nil {
+			return err
+		}
+		total -= rake
+	}
+
+	winners := []string{winner}
+	if p.Config.Split == SplitEven && len(tied) > 1 && contains(tied, winner) {
+		winners = append([]string{}, tied...)
+		sort.Strings(winners)
+	}
+	share := total / int64(len(winners))
+	remainder := total - share*int64(len(winners))
+	for _, name := range winners {
+		amount := share
+		if name == winner {
+			amount += remainder
+		}
+		if amount <= 0 || accounts[name] == "" {
+			continue
+		}
+		if _, err := p.wallet.Credit(p.txID("payout", name), accounts[name], amount, p.reference()); err != nil {
+			return err
+		}
+	}
+
+	p.next()
+
+	return nil
+}
+
+// Refund - Release every stake, when the game is cancelled
+func (p *Pot) Refund() error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	for _, name := range p.names() {
+		if _, err := p.wallet.Release(p.txID("release", name), p.holds[name]); err != nil {
+			return err
+		}
+	}
+	p.next()
+
+	return nil
+}
+
+// potCheckpoint - Enough to settle or release the stakes held for the current game
+type potCheckpoint struct {
+	ID    string            `json:"id"`
+	Game  int               `json:"game"`
+	Holds map[string]string `json:"holds"`
+}
+
+// Checkpoint - The stakes held for the current game, implements game.StakesCheckpointer
+func (p *Pot) Checkpoint() (json.RawMessage, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	return json.Marshal(potCheckpoint{ID: p.id, Game: p.game, Holds: p.holds})
+}
+
+// Restore - Take over the stakes a checkpoint held, keeping its transaction IDs.
+// Every hold must still be open in the wallet, they're gone with an in-memory ledger.
+func (p *Pot) Restore(data json.RawMessage) error {
+	checkpoint := potCheckpoint{}
+	if err := json.Unmarshal(data, &checkpoint); err != nil {
+		return err
+	}
+	for _, holdID := range checkpoint.Holds {
+		if err := p.wallet.Held(holdID); err != nil {
+			return err
+		}
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.id = checkpoint.ID
+	p.game = checkpoint.Game
+	p.holds = make(map[string]string, len(checkpoint.Holds))
+	for name, holdID := range checkpoint.Holds {
+		p.holds[name] = holdID
+	}
+
+	return nil
+}
+
+// next - Move on to the next game's stakes. Call with the lock held.
+func (p *Pot) next() {
+	p.holds = make(map[string]string)
+	p.game++
+}
+
+// names - Staked players in a stable order. Call with the lock held.
+func (p *Pot) names() []string {
+	names := make([]string, 0, len(p.holds))
+	for name := range p.holds {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	return names
+}
+
+func contains(names []string, name string) bool {
+	for _, n := range names {
+		if n == name {
+			return true
+		}
+	}
+
+	return false
+}
+
+// reference - Ties ledger entries to the game they were for
+func (p *Pot) reference() string {
+	return fmt.Sprintf("%s:%d", p.id, p.game)
+}
+
+// txID - Deterministic so retries of the same step are idempotent
+func (p *Pot) txID(step string, name string) string {
+	return fmt.Sprintf("%s:%s:%s", p.reference(), step, name)
+}
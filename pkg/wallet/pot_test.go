@@ -0,0 +1,113 @@
+package wallet
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPotWinnerTakesAll(t *testing.T) {
+	assert := assert.New(t)
+
+	wallet := NewWallet(NewMemoryStore(), 100)
+	pot := NewPot(wallet, &PotConfig{Stake: 10, RakePercent: 10, HouseAccount: "house"})
+
+	// Stakes come from the player's account, whatever name they play under
+	assert.Equal(ErrInvalidToken, pot.Stake("Steve", ""))
+	assert.Nil(pot.Stake("Steve", "account-1"))
+	assert.Nil(pot.Stake("Sarah", "account-2"))
+	assert.Nil(pot.Stake("Simon", "account-3"))
+
+	balance, _ := wallet.Balance("account-1")
+	assert.Equal(int64(90), balance.Available)
+	assert.Equal(int64(10), balance.Held)
+
+	assert.Nil(pot.Payout("Sarah", []string{"Sarah", "Steve"}))
+
+	// 30 in the pot, 3 rake
+	sarah, _ := wallet.Balance("account-2")
+	assert.Equal(int64(90+27), sarah.Available)
+	assert.Equal(int64(0), sarah.Held)
+	steve, _ := wallet.Balance("account-1")
+	assert.Equal(int64(90), steve.Available)
+	// The house never stakes, so it's only ever paid the rake
+	house, _ := wallet.Balance("house")
+	assert.Equal(int64(3), house.Available)
+	// Nothing's paid to the name
+	transactions, _ := wallet.Transactions("Sarah")
+	assert.Empty(transactions)
+}
+
+func TestPotSplitEven(t *testing.T) {
+	assert := assert.New(t)
+
+	wallet := NewWallet(NewMemoryStore(), 100)
+	pot := NewPot(wallet, &PotConfig{Stake: 10, Split: SplitEven})
+
+	pot.Stake("Steve", "Steve")
+	pot.Stake("Sarah", "Sarah")
+	pot.Stake("Simon", "Simon")
+
+	assert.Nil(pot.Payout("Sarah", []string{"Sarah", "Steve"}))
+
+	// 30 split two ways
+	sarah, _ := wallet.Balance("Sarah")
+	assert.Equal(int64(90+15), sarah.Available)
+	steve, _ := wallet.Balance("Steve")
+	assert.Equal(int64(90+15), steve.Available)
+	simon, _ := wallet.Balance("Simon")
+	assert.Equal(int64(90), simon.Available)
+
+	// Only shared when the winner is one of the tied
+	pot.Stake("Steve", "Steve")
+	pot.Stake("Sarah", "Sarah")
+	pot.Stake("Simon", "Simon")
+	assert.Nil(pot.Payout("Simon", []string{"Sarah", "Steve"}))
+	simon, _ = wallet.Balance("Simon")
+	assert.Equal(int64(80+30), simon.Available)
+}
+
+func TestPotRefund(t *testing.T) {
+	assert := assert.New(t)
+
+	wallet := NewWallet(NewMemoryStore(), 5)
+	pot := NewPot(wallet, &PotConfig{Stake: 10})
+
+	// Can't afford it
+	assert.Equal(ErrInsufficientFunds, pot.Stake("Steve", "Steve"))
+
+	wallet.Credit("c1", "Steve", 5, "")
+	assert.Nil(pot.Stake("Steve", "Steve"))
+	// Staking twice is a no-op
+	assert.Nil(pot.Stake("Steve", "Steve"))
+	balance, _ := wallet.Balance("Steve")
+	assert.Equal(int64(10), balance.Held)
+
+	assert.Nil(pot.Refund())
+	balance, _ = wallet.Balance("Steve")
+	assert.Equal(int64(10), balance.Available)
+	assert.Equal(int64(0), balance.Held)
+}
+
+func TestPotRestore(t *testing.T) {
+	assert := assert.New(t)
+
+	wallet := NewWallet(NewMemoryStore(), 100)
+	pot := NewPot(wallet, &PotConfig{Stake: 10})
+	pot.Stake("Steve", "Steve")
+	pot.Stake("Sarah", "Sarah")
+	checkpoint, err := pot.Checkpoint()
+	assert.Nil(err)
+
+	// A new pot after a restart releases what the old one held
+	restored := NewPot(wallet, &PotConfig{Stake: 10})
+	assert.Nil(restored.Restore(checkpoint))
+	assert.Nil(restored.Refund())
+	steve, _ := wallet.Balance("Steve")
+	assert.Equal(int64(100), steve.Available)
+	assert.Equal(int64(0), steve.Held)
+
+	// Holds already released, or lost with an in-memory ledger, can't be taken over
+	assert.Equal(ErrHoldSettled, NewPot(wallet, &PotConfig{Stake: 10}).Restore(checkpoint))
+	assert.Equal(ErrHoldNotFound, NewPot(NewWallet(NewMemoryStore(), 100), &PotConfig{Stake: 10}).Restore(checkpoint))
+}
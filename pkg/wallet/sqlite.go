@@ -0,0 +1,144 @@
+package wallet
+
+import (
+	"database/sql"
+	"strings"
+	"time"
+
+	// Registers the sqlite3 driver
+	_ "github.com/mattn/go-sqlite3"
+)
+
+const sqliteSchema = `
+CREATE TABLE IF NOT EXISTS transactions (
+	seq        INTEGER PRIMARY KEY AUTOINCREMENT,
+	id         TEXT NOT NULL UNIQUE,
+	account    TEXT NOT NULL,
+	type       TEXT NOT NULL,
+	amount     INTEGER NOT NULL,
+	hold_id    TEXT NOT NULL DEFAULT '',
+	reference  TEXT NOT NULL DEFAULT '',
+	created_at INTEGER NOT NULL
+);
+CREATE INDEX IF NOT EXISTS transactions_account ON transactions (account, seq);
+CREATE TABLE IF NOT EXISTS accounts (
+	account    TEXT PRIMARY KEY,
+	token_hash TEXT NOT NULL UNIQUE
+);
+`
+
+// SQLiteStore - Ledger kept in a SQLite database file
+type SQLiteStore struct {
+	db *sql.DB
+}
+
+// NewSQLiteStore - Open (or create) the ledger at path
+func NewSQLiteStore(path string) (*SQLiteStore, error) {
+	db, err := sql.Open("sqlite3", path)
+	if err != nil {
+		return nil, err
+	}
+	// SQLite only allows one writer, let the pool queue them
+	db.SetMaxOpenConns(1)
+
+	if _, err := db.Exec(sqliteSchema); err != nil {
+		db.Close()
+		return nil, err
+	}
+
+	return &SQLiteStore{db}, nil
+}
+
+// Append - See Store
+func (ss *SQLiteStore) Append(tx Transaction) error {
+	_, err := ss.db.Exec(
+		`INSERT INTO transactions (id, account, type, amount, hold_id, reference, created_at) VALUES (?, ?, ?, ?, ?, ?, ?)`,
+		tx.ID, tx.Account, string(tx.Type), tx.Amount, tx.HoldID, tx.Reference, tx.CreatedAt.UnixNano(),
+	)
+	if err != nil && strings.Contains(err.Error(), "UNIQUE") {
+		return ErrDuplicateTransaction
+	}
+
+	return err
+}
+
+// Get - See Store
+func (ss *SQLiteStore) Get(id string) (Transaction, error) {
+	row := ss.db.QueryRow(
+		`SELECT id, account, type, amount, hold_id, reference, created_at FROM transactions WHERE id = ?`,
+		id,
+	)
+	tx, err := scanTransaction(row)
+	if err == sql.ErrNoRows {
+		return tx, ErrTransactionNotFound
+	}
+
+	return tx, err
+}
+
+// List - See Store
+func (ss *SQLiteStore) List(account string) ([]Transaction, error) {
+	rows, err := ss.db.Query(
+		`SELECT id, account, type, amount, hold_id, reference, created_at FROM transactions WHERE account = ? ORDER BY seq`,
+		account,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	list := make([]Transaction, 0)
+	for rows.Next() {
+		tx, err := scanTransaction(rows)
+		if err != nil {
+			return nil, err
+		}
+		list = append(list, tx)
+	}
+
+	return list, rows.Err()
+}
+
+// AddAccount - See Store
+func (ss *SQLiteStore) AddAccount(account string, tokenHash string) error {
+	_, err := ss.db.Exec(`INSERT INTO accounts (account, token_hash) VALUES (?, ?)`, account, tokenHash)
+	if err != nil && strings.Contains(err.Error(), "UNIQUE") {
+		return ErrDuplicateAccount
+	}
+
+	return err
+}
+
+// Account - See Store
+func (ss *SQLiteStore) Account(tokenHash string) (string, error) {
+	var account string
+	err := ss.db.QueryRow(`SELECT account FROM accounts WHERE token_hash = ?`, tokenHash).Scan(&account)
+	if err == sql.ErrNoRows {
+		return "", ErrInvalidToken
+	}
+
+	return account, err
+}
+
+// Close - Close the database
+func (ss *SQLiteStore) Close() error {
+	return ss.db.Close()
+}
+
+type scanner interface {
+	Scan(dest ...interface{}) error
+}
+
+func scanTransaction(row scanner) (Transaction, error) {
+	tx := Transaction{}
+	var txType string
+	var createdAt int64
+	err := row.Scan(&tx.ID, &tx.Account, &txType, &tx.Amount, &tx.HoldID, &tx.Reference, &createdAt)
+	if err != nil {
+		return tx, err
+	}
+	tx.Type = TransactionType(txType)
+	tx.CreatedAt = time.Unix(0, createdAt).UTC()
+
+	return tx, nil
+}
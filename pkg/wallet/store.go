@@ -0,0 +1,123 @@
+package wallet
+
+import (
+	"net/http"
+	"sync"
+
+	"networkgaming.co.uk/techtest/pkg/problem"
+)
+
+var (
+	ErrDuplicateTransaction = problem.New("duplicate_transaction", http.StatusConflict, "Invalid transaction: Transaction ID already exists")
+	ErrDuplicateAccount     = problem.New("duplicate_account", http.StatusConflict, "Invalid account: Account already exists")
+)
+
+// Store - Append only ledger backend for a Wallet
+type Store interface {
+	// Append - Write a new transaction, ErrDuplicateTransaction if the ID is taken
+	Append(tx Transaction) error
+	// Get - Transaction by ID, ErrTransactionNotFound if there isn't one
+	Get(id string) (Transaction, error)
+	// List - An account's transactions, oldest first
+	List(account string) ([]Transaction, error)
+	// AddAccount - Register an account and the hash of its token, ErrDuplicateAccount if either is taken
+	AddAccount(account string, tokenHash string) error
+	// Account - The account a token hash was registered for, ErrInvalidToken if there isn't one
+	Account(tokenHash string) (string, error)
+	Close() error
+}
+
+// MemoryStore - Ledger kept in memory, lost on restart
+type MemoryStore struct {
+	transactions []Transaction
+	byID         map[string]int
+	accounts     map[string]string
+	mu           sync.RWMutex
+}
+
+// NewMemoryStore - Empty in memory ledger
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{
+		transactions: make([]Transaction, 0),
+		byID:         make(map[string]int),
+		accounts:     make(map[string]string),
+	}
+}
+
+// Append - See Store
+func (ms *MemoryStore) Append(tx Transaction) error {
+	ms.mu.Lock()
+	defer ms.mu.Unlock()
+
+	if _, exists := ms.byID[tx.ID]; exists {
+		return ErrDuplicateTransaction
+	}
+	ms.byID[tx.ID] = len(ms.transactions)
+	ms.transactions = append(ms.transactions, tx)
+
+	return nil
+}
+
+// Get - See Store
+func (ms *MemoryStore) Get(id string) (Transaction, error) {
+	ms.mu.RLock()
+	defer ms.mu.RUnlock()
+
+	i, exists := ms.byID[id]
+	if !exists {
+		return Transaction{}, ErrTransactionNotFound
+	}
+
+	return ms.transactions[i], nil
+}
+
+// List - See Store
+func (ms *MemoryStore) List(account string) ([]Transaction, error) {
+	ms.mu.RLock()
+	defer ms.mu.RUnlock()
+
+	list := make([]Transaction, 0)
+	for _, tx := range ms.transactions {
+		if tx.Account == account {
+			list = append(list, tx)
+		}
+	}
+
+	return list, nil
+}
+
+// AddAccount - See Store
+func (ms *MemoryStore) AddAccount(account string, tokenHash string) error {
+	ms.mu.Lock()
+	defer ms.mu.Unlock()
+
+	if _, exists := ms.accounts[tokenHash]; exists {
+		return ErrDuplicateAccount
+	}
+	for _, existing := range ms.accounts {
+		if existing == account {
+			return ErrDuplicateAccount
+		}
+	}
+	ms.accounts[tokenHash] = account
+
+	return nil
+}
+
+// Account - See Store
+func (ms *MemoryStore) Account(tokenHash string) (string, error) {
+	ms.mu.RLock()
+	defer ms.mu.RUnlock()
+
+	account, exists := ms.accounts[tokenHash]
+	if !exists {
+		return "", ErrInvalidToken
+	}
+
+	return account, nil
+}
+
+// Close - Nothing to close
+func (ms *MemoryStore) Close() error {
+	return nil
+}
@@ -0,0 +1,355 @@
+package wallet
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"net/http"
+	"sync"
+	"time"
+
+	"networkgaming.co.uk/techtest/pkg/problem"
+)
+
+// TransactionType - What a ledger entry does to an account
+type TransactionType string
+
+// TransactionTypes
+const (
+	TransactionCredit  TransactionType = "credit"
+	TransactionDebit   TransactionType = "debit"
+	TransactionHold    TransactionType = "hold"
+	TransactionRelease TransactionType = "release"
+)
+
+var (
+	ErrInvalidAmount        = problem.New("invalid_amount", http.StatusUnprocessableEntity, "Invalid amount: Amounts must be more than zero")
+	ErrInvalidAccount       = problem.New("invalid_account", http.StatusBadRequest, "Invalid account: Account name is required")
+	ErrInvalidTransactionID = problem.New("invalid_transaction_id", http.StatusUnprocessableEntity, "Invalid transaction: Transaction ID is required")
+	ErrInsufficientFunds    = problem.New("insufficient_funds", http.StatusPaymentRequired, "Invalid transaction: Not enough funds in the wallet")
+	ErrTransactionConflict  = problem.New("transaction_conflict", http.StatusConflict, "Invalid transaction: Transaction ID already used for a different transaction")
+	ErrTransactionNotFound  = problem.New("transaction_not_found", http.StatusNotFound, "Invalid transaction: No transaction with that ID")
+	ErrHoldNotFound         = problem.New("hold_not_found", http.StatusNotFound, "Invalid transaction: No hold with that ID")
+	ErrHoldSettled          = problem.New("hold_settled", http.StatusConflict, "Invalid transaction: Hold has already been released or debited")
+	ErrInvalidToken         = problem.New("invalid_wallet_token", http.StatusUnauthorized, "Invalid account: Send the token from POST /wallet as Authorization: Bearer [TOKEN]")
+)
+
+// Transaction - A single ledger entry.
+// Debits and releases against a hold carry the hold's ID in HoldID.
+type Transaction struct {
+	ID        string          `json:"id"`
+	Account   string          `json:"account"`
+	Type      TransactionType `json:"type"`
+	Amount    int64           `json:"amount"`
+	HoldID    string          `json:"hold_id,omitempty"`
+	Reference string          `json:"reference,omitempty"`
+	CreatedAt time.Time       `json:"created_at"`
+}
+
+// same - Whether a retried transaction matches the one already in the ledger
+func (tx Transaction) same(other Transaction) bool {
+	return tx.Account == other.Account &&
+		tx.Type == other.Type &&
+		tx.Amount == other.Amount &&
+		tx.HoldID == other.HoldID
+}
+
+// Balance - An account's funds, Held is reserved by open holds
+type Balance struct {
+	Account   string `json:"account"`
+	Available int64  `json:"available"`
+	Held      int64  `json:"held"`
+}
+
+// Account - A new account and the token that proves it's yours, the token is only ever shown once
+type Account struct {
+	Account string `json:"account"`
+	Token   string `json:"token"`
+}
+
+// Wallet - Ledger of credits, debits, holds and releases.
+// Every call takes a transaction ID, retrying a call with the same ID
+// returns the original transaction rather than applying it twice.
+type Wallet struct {
+	StartingBalance int64
+	store           Store
+	mu              sync.Mutex
+}
+
+// NewWallet - Wallet over store, accounts are granted startingBalance the first time they hold funds
+func NewWallet(store Store, startingBalance int64) *Wallet {
+	return &Wallet{
+		StartingBalance: startingBalance,
+		store:           store,
+	}
+}
+
+// Open - Create an account for a player to stake from, only its token's hash is kept
+func (w *Wallet) Open() (Account, error) {
+	id, err := random(8)
+	if err != nil {
+		return Account{}, err
+	}
+	token, err := random(32)
+	if err != nil {
+		return Account{}, err
+	}
+	account := Account{Account: "account-" + id, Token: token}
+	if err := w.store.AddAccount(account.Account, hash(token)); err != nil {
+		return Account{}, err
+	}
+
+	return account, nil
+}
+
+// Authenticate - The account token was issued for, implements game.Accounts
+func (w *Wallet) Authenticate(token string) (string, error) {
+	if token == "" {
+		return "", ErrInvalidToken
+	}
+
+	return w.store.Account(hash(token))
+}
+
+// Credit - Add funds to an account
+func (w *Wallet) Credit(id string, account string, amount int64, reference string) (Transaction, error) {
+	return w.apply(Transaction{ID: id, Account: account, Type: TransactionCredit, Amount: amount, Reference: reference})
+}
+
+// Debit - Take available funds from an account
+func (w *Wallet) Debit(id string, account string, amount int64, reference string) (Transaction, error) {
+	return w.apply(Transaction{ID: id, Account: account, Type: TransactionDebit, Amount: amount, Reference: reference})
+}
+
+// Hold - Reserve available funds, to be released or debited later
+func (w *Wallet) Hold(id string, account string, amount int64, reference string) (Transaction, error) {
+	return w.apply(Transaction{ID: id, Account: account, Type: TransactionHold, Amount: amount, Reference: reference})
+}
+
+// Release - Return a hold's funds to the account
+func (w *Wallet) Release(id string, holdID string) (Transaction, error) {
+	return w.settle(id, holdID, TransactionRelease)
+}
+
+// Settle - Debit a hold's funds from the account
+func (w *Wallet) Settle(id string, holdID string) (Transaction, error) {
+	return w.settle(id, holdID, TransactionDebit)
+}
+
+// Balance - Current funds for account, nothing until it's first been used
+func (w *Wallet) Balance(account string) (Balance, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if account == "" {
+		return Balance{}, ErrInvalidAccount
+	}
+
+	return w.balance(account)
+}
+
+// Transactions - Ledger entries for account, oldest first
+func (w *Wallet) Transactions(account string) ([]Transaction, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if account == "" {
+		return nil, ErrInvalidAccount
+	}
+
+	return w.store.List(account)
+}
+
+// Held - Check holdID is a hold still waiting to be released or debited
+func (w *Wallet) Held(holdID string) error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	hold, err := w.store.Get(holdID)
+	if err == ErrTransactionNotFound || (err == nil && hold.Type != TransactionHold) {
+		return ErrHoldNotFound
+	}
+	if err != nil {
+		return err
+	}
+	ledger, err := w.store.List(hold.Account)
+	if err != nil {
+		return err
+	}
+	for _, entry := range ledger {
+		if entry.HoldID == holdID {
+			return ErrHoldSettled
+		}
+	}
+
+	return nil
+}
+
+func (w *Wallet) settle(id string, holdID string, txType TransactionType) (Transaction, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	hold, err := w.store.Get(holdID)
+	if err == ErrTransactionNotFound || (err == nil && hold.Type != TransactionHold) {
+		return Transaction{}, ErrHoldNotFound
+	}
+	if err != nil {
+		return Transaction{}, err
+	}
+
+	tx := Transaction{
+		ID:        id,
+		Account:   hold.Account,
+		Type:      txType,
+		Amount:    hold.Amount,
+		HoldID:    holdID,
+		Reference: hold.Reference,
+	}
+
+	return w.append(tx)
+}
+
+func (w *Wallet) apply(tx Transaction) (Transaction, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	// Only staking opens an account, so looking one up never mints funds
+	if tx.Type == TransactionHold {
+		if err := w.open(tx.Account); err != nil {
+			return Transaction{}, err
+		}
+	}
+
+	return w.append(tx)
+}
+
+// append - Validate tx against the ledger and write it. Call with the lock held.
+func (w *Wallet) append(tx Transaction) (Transaction, error) {
+
+	if tx.ID == "" {
+		return tx, ErrInvalidTransactionID
+	}
+	if tx.Account == "" {
+		return tx, ErrInvalidAccount
+	}
+	if tx.Amount <= 0 {
+		return tx, ErrInvalidAmount
+	}
+
+	// Idempotent retries
+	existing, err := w.store.Get(tx.ID)
+	if err == nil {
+		if !existing.same(tx) {
+			return existing, ErrTransactionConflict
+		}
+		return existing, nil
+	}
+	if err != ErrTransactionNotFound {
+		return tx, err
+	}
+
+	ledger, err := w.store.List(tx.Account)
+	if err != nil {
+		return tx, err
+	}
+	balance := fold(tx.Account, ledger)
+
+	switch tx.Type {
+	case TransactionDebit, TransactionRelease:
+		if tx.HoldID != "" {
+			for _, entry := range ledger {
+				if entry.HoldID == tx.HoldID {
+					return tx, ErrHoldSettled
+				}
+			}
+		} else if balance.Available < tx.Amount {
+			return tx, ErrInsufficientFunds
+		}
+	case TransactionHold:
+		if balance.Available < tx.Amount {
+			return tx, ErrInsufficientFunds
+		}
+	}
+
+	tx.CreatedAt = time.Now().UTC()
+	if err := w.store.Append(tx); err != nil {
+		return tx, err
+	}
+
+	return tx, nil
+}
+
+// open - Grant the starting balance the first time an account is seen. Call with the lock held.
+func (w *Wallet) open(account string) error {
+	if account == "" {
+		return ErrInvalidAccount
+	}
+	if w.StartingBalance <= 0 {
+		return nil
+	}
+
+	id := "grant:" + account
+	if _, err := w.store.Get(id); err != ErrTransactionNotFound {
+		return err
+	}
+
+	return w.store.Append(Transaction{
+		ID:        id,
+		Account:   account,
+		Type:      TransactionCredit,
+		Amount:    w.StartingBalance,
+		Reference: "starting balance",
+		CreatedAt: time.Now().UTC(),
+	})
+}
+
+// random - n random bytes, hex encoded
+func random(n int) (string, error) {
+	b := make([]byte, n)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+
+	return hex.EncodeToString(b), nil
+}
+
+// hash - What's kept of a token, so a copy of the ledger can't be used to play as anyone
+func hash(token string) string {
+	sum := sha256.Sum256([]byte(token))
+
+	return hex.EncodeToString(sum[:])
+}
+
+func (w *Wallet) balance(account string) (Balance, error) {
+	ledger, err := w.store.List(account)
+	if err != nil {
+		return Balance{}, err
+	}
+
+	return fold(account, ledger), nil
+}
+
+// fold - Replay the ledger into a balance
+func fold(account string, ledger []Transaction) Balance {
+	balance := Balance{Account: account}
+	for _, tx := range ledger {
+		switch tx.Type {
+		case TransactionCredit:
+			balance.Available += tx.Amount
+		case TransactionHold:
+			balance.Available -= tx.Amount
+			balance.Held += tx.Amount
+		case TransactionRelease:
+			balance.Available += tx.Amount
+			balance.Held -= tx.Amount
+		case TransactionDebit:
+			if tx.HoldID != "" {
+				balance.Held -= tx.Amount
+			} else {
+				balance.Available -= tx.Amount
+			}
+		}
+	}
+
+	return balance
+}
@@ -0,0 +1,169 @@
+package wallet
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func testWalletLedger(t *testing.T, store Store) {
+	assert := assert.New(t)
+
+	wallet := NewWallet(store, 0)
+
+	// Nothing to hold yet
+	_, err := wallet.Hold("h1", "Steve", 10, "")
+	assert.Equal(ErrInsufficientFunds, err)
+
+	_, err = wallet.Credit("c1", "Steve", 100, "top up")
+	assert.Nil(err)
+	_, err = wallet.Debit("d1", "Steve", 30, "")
+	assert.Nil(err)
+	_, err = wallet.Hold("h1", "Steve", 50, "game")
+	assert.Nil(err)
+
+	balance, _ := wallet.Balance("Steve")
+	assert.Equal(int64(20), balance.Available)
+	assert.Equal(int64(50), balance.Held)
+
+	// Held funds can't be spent
+	_, err = wallet.Debit("d2", "Steve", 30, "")
+	assert.Equal(ErrInsufficientFunds, err)
+
+	_, err = wallet.Release("r1", "h1")
+	assert.Nil(err)
+	// A hold can only be settled once
+	_, err = wallet.Settle("s1", "h1")
+	assert.Equal(ErrHoldSettled, err)
+	_, err = wallet.Release("r2", "nope")
+	assert.Equal(ErrHoldNotFound, err)
+
+	balance, _ = wallet.Balance("Steve")
+	assert.Equal(int64(70), balance.Available)
+	assert.Equal(int64(0), balance.Held)
+
+	transactions, _ := wallet.Transactions("Steve")
+	assert.Len(transactions, 4)
+	assert.Equal("c1", transactions[0].ID)
+	assert.Equal(TransactionRelease, transactions[3].Type)
+	assert.Equal("h1", transactions[3].HoldID)
+}
+
+func testWalletIdempotent(t *testing.T, store Store) {
+	assert := assert.New(t)
+
+	wallet := NewWallet(store, 0)
+
+	first, err := wallet.Credit("c1", "Steve", 100, "")
+	assert.Nil(err)
+	// Retry is a no-op
+	again, err := wallet.Credit("c1", "Steve", 100, "")
+	assert.Nil(err)
+	assert.Equal(first, again)
+	// Reusing the ID for something else is not
+	_, err = wallet.Credit("c1", "Steve", 200, "")
+	assert.Equal(ErrTransactionConflict, err)
+
+	balance, _ := wallet.Balance("Steve")
+	assert.Equal(int64(100), balance.Available)
+}
+
+func testWalletAccounts(t *testing.T, store Store) {
+	assert := assert.New(t)
+
+	wallet := NewWallet(store, 100)
+
+	steve, err := wallet.Open()
+	assert.Nil(err)
+	sarah, err := wallet.Open()
+	assert.Nil(err)
+	assert.NotEqual(steve.Account, sarah.Account)
+	assert.NotEqual(steve.Token, sarah.Token)
+
+	account, err := wallet.Authenticate(steve.Token)
+	assert.Nil(err)
+	assert.Equal(steve.Account, account)
+	_, err = wallet.Authenticate(steve.Account)
+	assert.Equal(ErrInvalidToken, err)
+	_, err = wallet.Authenticate("")
+	assert.Equal(ErrInvalidToken, err)
+
+	// Only the token's hash is kept
+	_, err = store.Account(steve.Token)
+	assert.Equal(ErrInvalidToken, err)
+	assert.Equal(ErrDuplicateAccount, store.AddAccount(steve.Account, "another"))
+}
+
+func TestMemoryStore(t *testing.T) {
+	testWalletLedger(t, NewMemoryStore())
+	testWalletIdempotent(t, NewMemoryStore())
+	testWalletAccounts(t, NewMemoryStore())
+}
+
+func TestSQLiteStore(t *testing.T) {
+	dir, err := ioutil.TempDir("", "wallet")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	ledger, err := NewSQLiteStore(filepath.Join(dir, "ledger.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer ledger.Close()
+	testWalletLedger(t, ledger)
+
+	idempotent, err := NewSQLiteStore(filepath.Join(dir, "idempotent.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer idempotent.Close()
+	testWalletIdempotent(t, idempotent)
+
+	accounts, err := NewSQLiteStore(filepath.Join(dir, "accounts.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer accounts.Close()
+	testWalletAccounts(t, accounts)
+}
+
+func TestStartingBalance(t *testing.T) {
+	assert := assert.New(t)
+
+	wallet := NewWallet(NewMemoryStore(), 1000)
+
+	// Looking an account up leaves the ledger alone
+	balance, err := wallet.Balance("Steve")
+	assert.Nil(err)
+	assert.Equal(Balance{Account: "Steve"}, balance)
+	transactions, err := wallet.Transactions("Steve")
+	assert.Nil(err)
+	assert.Empty(transactions)
+
+	// Nor does paying in
+	_, err = wallet.Credit("c1", "house", 5, "rake")
+	assert.Nil(err)
+	balance, _ = wallet.Balance("house")
+	assert.Equal(int64(5), balance.Available)
+
+	// Granted the first time it holds a stake, and only then
+	_, err = wallet.Hold("h1", "Steve", 10, "game")
+	assert.Nil(err)
+	_, err = wallet.Hold("h2", "Steve", 10, "game")
+	assert.Nil(err)
+	balance, _ = wallet.Balance("Steve")
+	assert.Equal(int64(980), balance.Available)
+	assert.Equal(int64(20), balance.Held)
+	transactions, _ = wallet.Transactions("Steve")
+	assert.Len(transactions, 3)
+
+	_, err = wallet.Balance("")
+	assert.Equal(ErrInvalidAccount, err)
+	_, err = wallet.Transactions("")
+	assert.Equal(ErrInvalidAccount, err)
+}
@@ -0,0 +1,81 @@
+package webhook
+
+import (
+	"encoding/json"
+	"sync"
+	"time"
+)
+
+// DeadLetter - A delivery that ran out of attempts, kept with its payload so it can be sent again
+type DeadLetter struct {
+	Delivery
+	Payload json.RawMessage `json:"payload"`
+	DiedAt  time.Time       `json:"died_at"`
+}
+
+// DeadLetterStore - Where failed deliveries are kept until they're redelivered
+type DeadLetterStore interface {
+	// Put - Keep a dead letter, replacing any with the same delivery ID
+	Put(letter DeadLetter) error
+	// List - A subscription's dead letters, oldest first
+	List(subscription string) ([]DeadLetter, error)
+	// Take - Remove and return a dead letter, ErrDeadLetterNotFound if there isn't one
+	Take(subscription string, id string) (DeadLetter, error)
+}
+
+// MemoryDeadLetters - Dead letters kept in memory, lost on restart
+type MemoryDeadLetters struct {
+	letters []DeadLetter
+	mu      sync.Mutex
+}
+
+// NewMemoryDeadLetters - No dead letters yet
+func NewMemoryDeadLetters() *MemoryDeadLetters {
+	return &MemoryDeadLetters{letters: make([]DeadLetter, 0)}
+}
+
+// Put - See DeadLetterStore
+func (md *MemoryDeadLetters) Put(letter DeadLetter) error {
+	md.mu.Lock()
+	defer md.mu.Unlock()
+
+	for i, kept := range md.letters {
+		if kept.ID == letter.ID {
+			md.letters[i] = letter
+			return nil
+		}
+	}
+	md.letters = append(md.letters, letter)
+
+	return nil
+}
+
+// List - See DeadLetterStore
+func (md *MemoryDeadLetters) List(subscription string) ([]DeadLetter, error) {
+	md.mu.Lock()
+	defer md.mu.Unlock()
+
+	letters := []DeadLetter{}
+	for _, letter := range md.letters {
+		if letter.Subscription == subscription {
+			letters = append(letters, letter)
+		}
+	}
+
+	return letters, nil
+}
+
+// Take - See DeadLetterStore
+func (md *MemoryDeadLetters) Take(subscription string, id string) (DeadLetter, error) {
+	md.mu.Lock()
+	defer md.mu.Unlock()
+
+	for i, letter := range md.letters {
+		if letter.Subscription == subscription && letter.ID == id {
+			md.letters = append(md.letters[:i], md.letters[i+1:]...)
+			return letter, nil
+		}
+	}
+
+	return DeadLetter{}, ErrDeadLetterNotFound
+}
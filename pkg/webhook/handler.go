@@ -0,0 +1,140 @@
+package webhook
+
+import (
+	"encoding/json"
+	"log"
+	"net/http"
+
+	"github.com/go-chi/chi"
+	"networkgaming.co.uk/techtest/pkg/game"
+	"networkgaming.co.uk/techtest/pkg/problem"
+)
+
+// WebhookHandler - REST resource for /webhooks, every request needs the admin token
+type WebhookHandler struct {
+	webhooks *Webhooks
+	admin    *game.AdminHandler
+}
+
+func NewWebhookHandler(webhooks *Webhooks, admin *game.AdminHandler) *WebhookHandler {
+	return &WebhookHandler{webhooks, admin}
+}
+
+type SubscribeRequest struct {
+	URL    string   `json:"url"`
+	Events []string `json:"events"`
+	Secret string   `json:"secret"`
+}
+
+// SubscribeResponse - The secret is only ever sent here
+type SubscribeResponse struct {
+	Subscription
+	Secret string `json:"secret"`
+}
+
+// Subscribe - POST /webhooks {url,events,secret}
+func (h *WebhookHandler) Subscribe(w http.ResponseWriter, r *http.Request) {
+	if !h.start(w, r) {
+		return
+	}
+
+	request := new(SubscribeRequest)
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		log.Printf("Subscribe Webhook - Error decoding json %s", err.Error())
+		problem.Write(w, problem.InvalidJSON(err))
+		return
+	}
+
+	subscription, err := h.webhooks.Subscribe(request.URL, request.Events, request.Secret)
+	if err != nil {
+		problem.Write(w, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(SubscribeResponse{*subscription, subscription.Secret})
+}
+
+// List - GET /webhooks
+func (h *WebhookHandler) List(w http.ResponseWriter, r *http.Request) {
+	if !h.start(w, r) {
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(h.webhooks.List())
+}
+
+// Unsubscribe - DELETE /webhooks/{id}
+func (h *WebhookHandler) Unsubscribe(w http.ResponseWriter, r *http.Request) {
+	if !h.start(w, r) {
+		return
+	}
+
+	if err := h.webhooks.Unsubscribe(chi.URLParam(r, "id")); err != nil {
+		problem.Write(w, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// Deliveries - GET /webhooks/{id}/deliveries, the delivery log
+func (h *WebhookHandler) Deliveries(w http.ResponseWriter, r *http.Request) {
+	if !h.start(w, r) {
+		return
+	}
+
+	deliveries, err := h.webhooks.Deliveries(chi.URLParam(r, "id"))
+	if err != nil {
+		problem.Write(w, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(deliveries)
+}
+
+// DeadLetters - GET /webhooks/{id}/dead-letters
+func (h *WebhookHandler) DeadLetters(w http.ResponseWriter, r *http.Request) {
+	if !h.start(w, r) {
+		return
+	}
+
+	letters, err := h.webhooks.DeadLetters(chi.URLParam(r, "id"))
+	if err != nil {
+		problem.Write(w, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(letters)
+}
+
+// Redeliver - POST /webhooks/{id}/dead-letters/{delivery}/redeliver
+func (h *WebhookHandler) Redeliver(w http.ResponseWriter, r *http.Request) {
+	if !h.start(w, r) {
+		return
+	}
+
+	delivery, err := h.webhooks.Redeliver(chi.URLParam(r, "id"), chi.URLParam(r, "delivery"))
+	if err != nil {
+		problem.Write(w, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusAccepted)
+	json.NewEncoder(w).Encode(delivery)
+}
+
+// start - Check the admin token and set the response headers
+func (h *WebhookHandler) start(w http.ResponseWriter, r *http.Request) bool {
+	if !h.admin.Authorized(w, r) {
+		return false
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Server", "NG: Small Browser Based Game Server")
+
+	return true
+}
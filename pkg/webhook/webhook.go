@@ -0,0 +1,155 @@
+package webhook
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+
+	"networkgaming.co.uk/techtest/pkg/game"
+	"networkgaming.co.uk/techtest/pkg/problem"
+)
+
+const (
+	// SignatureHeader - sha256= then the hex HMAC of the timestamp, a dot and the body
+	SignatureHeader = "X-Webhook-Signature"
+	// TimestampHeader - Unix seconds when the attempt was signed
+	TimestampHeader = "X-Webhook-Timestamp"
+	// DeliveryHeader - The same on every attempt, so receivers can drop repeats
+	DeliveryHeader = "X-Webhook-Delivery"
+	// EventHeader - The event's type
+	EventHeader = "X-Webhook-Event"
+)
+
+var (
+	ErrInvalidURL           = problem.New("invalid_url", http.StatusUnprocessableEntity, "Invalid webhook: URL must be an absolute http or https URL")
+	ErrUnknownEvent         = problem.New("unknown_event", http.StatusUnprocessableEntity, "Invalid webhook: No event with that type")
+	ErrSubscriptionNotFound = problem.New("subscription_not_found", http.StatusNotFound, "Invalid request: No webhook with that ID")
+	ErrDeadLetterNotFound   = problem.New("dead_letter_not_found", http.StatusNotFound, "Invalid request: No dead letter with that ID")
+)
+
+// Subscription - Where to send events, and which. No events means every event.
+// The secret signs every delivery and is only shown when the subscription is made.
+type Subscription struct {
+	ID        string    `json:"id"`
+	URL       string    `json:"url"`
+	Events    []string  `json:"events"`
+	Secret    string    `json:"-"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// wants - Whether the subscription is sent events of eventType
+func (s *Subscription) wants(eventType string) bool {
+	if len(s.Events) == 0 {
+		return true
+	}
+	for _, want := range s.Events {
+		if want == eventType {
+			return true
+		}
+	}
+
+	return false
+}
+
+// validate - An absolute http(s) URL and events that exist
+func (s *Subscription) validate() error {
+	u, err := url.Parse(s.URL)
+	if err != nil || !u.IsAbs() || u.Host == "" || (u.Scheme != "http" && u.Scheme != "https") {
+		return ErrInvalidURL
+	}
+	known := make(map[string]bool)
+	for _, name := range game.EventTypeNames() {
+		known[name] = true
+	}
+	for _, event := range s.Events {
+		if !known[event] {
+			return ErrUnknownEvent
+		}
+	}
+
+	return nil
+}
+
+// Payload - The body of every delivery
+type Payload struct {
+	ID           string          `json:"id"`
+	Subscription string          `json:"subscription"`
+	Room         string          `json:"room"`
+	Type         string          `json:"type"`
+	At           time.Time       `json:"at"`
+	Event        json.RawMessage `json:"event"`
+}
+
+// Status - Where a delivery has got to
+type Status string
+
+const (
+	StatusPending   Status = "pending"
+	StatusDelivered Status = "delivered"
+	StatusDead      Status = "dead"
+)
+
+// Attempt - One try at a delivery, StatusCode is zero if there was no response
+type Attempt struct {
+	At         time.Time `json:"at"`
+	StatusCode int       `json:"status_code,omitempty"`
+	Error      string    `json:"error,omitempty"`
+}
+
+// Delivery - An event on its way to a subscription, and how it's gone so far
+type Delivery struct {
+	ID           string    `json:"id"`
+	Subscription string    `json:"subscription"`
+	Room         string    `json:"room"`
+	Type         string    `json:"type"`
+	Status       Status    `json:"status"`
+	Attempts     []Attempt `json:"attempts"`
+	CreatedAt    time.Time `json:"created_at"`
+	body         []byte
+}
+
+// copy - Safe to hand out while the original is still being delivered
+func (d *Delivery) copy() Delivery {
+	delivery := *d
+	delivery.Attempts = append([]Attempt{}, d.Attempts...)
+
+	return delivery
+}
+
+// Sign - The SignatureHeader for body sent at timestamp
+func Sign(secret string, timestamp int64, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(strconv.FormatInt(timestamp, 10)))
+	mac.Write([]byte("."))
+	mac.Write(body)
+
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+// Verify - Whether a delivery's signature and timestamp headers match its body,
+// for receivers to check before trusting it
+func Verify(secret string, timestamp string, body []byte, signature string) bool {
+	ts, err := strconv.ParseInt(timestamp, 10, 64)
+	if err != nil {
+		return false
+	}
+
+	return hmac.Equal([]byte(Sign(secret, ts, body)), []byte(signature))
+}
+
+// randomID - A random hex string, prefixed
+func randomID(prefix string) (string, error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+
+	return fmt.Sprintf("%s%s", prefix, hex.EncodeToString(b)), nil
+}
@@ -0,0 +1,322 @@
+package webhook
+
+import (
+	"context"
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/go-chi/chi"
+	"github.com/stretchr/testify/assert"
+	"networkgaming.co.uk/techtest/pkg/game"
+)
+
+// receiver - A local endpoint that answers each delivery with the next status, 200 once they run out
+type receiver struct {
+	secret   string
+	statuses []int
+	received []Payload
+	verified []bool
+	mu       sync.Mutex
+}
+
+func (rc *receiver) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	body, _ := ioutil.ReadAll(r.Body)
+	payload := Payload{}
+	json.Unmarshal(body, &payload)
+
+	rc.mu.Lock()
+	defer rc.mu.Unlock()
+	rc.received = append(rc.received, payload)
+	rc.verified = append(rc.verified, Verify(rc.secret, r.Header.Get(TimestampHeader), body, r.Header.Get(SignatureHeader)) &&
+		r.Header.Get(DeliveryHeader) == payload.ID && r.Header.Get(EventHeader) == payload.Type)
+	status := http.StatusOK
+	if len(rc.statuses) > 0 {
+		status, rc.statuses = rc.statuses[0], rc.statuses[1:]
+	}
+	w.WriteHeader(status)
+}
+
+func (rc *receiver) count() int {
+	rc.mu.Lock()
+	defer rc.mu.Unlock()
+
+	return len(rc.received)
+}
+
+// testWebhooks - Webhooks delivering until ctx is done, retrying quickly, and a receiver to deliver to
+func testWebhooks(ctx context.Context) (*Webhooks, *receiver, *httptest.Server) {
+	rc := &receiver{secret: "shh"}
+	server := httptest.NewServer(rc)
+
+	webhooks := NewWebhooks(&Config{
+		Workers:     2,
+		QueueSize:   10,
+		MaxAttempts: 3,
+		Backoff:     1 * time.Millisecond,
+		MaxBackoff:  4 * time.Millisecond,
+		Timeout:     1 * time.Second,
+	}, NewMemoryDeadLetters())
+	webhooks.Start(ctx)
+
+	return webhooks, rc, server
+}
+
+// settled - Wait until none of the subscription's deliveries are pending
+func settled(t *testing.T, webhooks *Webhooks, id string) []Delivery {
+	var deliveries []Delivery
+	assert.Eventually(t, func() bool {
+		deliveries, _ = webhooks.Deliveries(id)
+		for _, delivery := range deliveries {
+			if delivery.Status == StatusPending {
+				return false
+			}
+		}
+		return true
+	}, time.Second, time.Millisecond)
+
+	return deliveries
+}
+
+func TestSign(t *testing.T) {
+	assert := assert.New(t)
+
+	body := []byte(`{"type":"Game Started"}`)
+	signature := Sign("shh", 1600000000, body)
+	assert.True(strings.HasPrefix(signature, "sha256="))
+	assert.True(Verify("shh", "1600000000", body, signature))
+	assert.False(Verify("shh", "1600000001", body, signature))
+	assert.False(Verify("other", "1600000000", body, signature))
+	assert.False(Verify("shh", "1600000000", []byte(`{"type":"Game Completed"}`), signature))
+	assert.False(Verify("shh", "soon", body, signature))
+}
+
+func TestSubscribe(t *testing.T) {
+	assert := assert.New(t)
+	webhooks := NewWebhooks(&Config{}, NewMemoryDeadLetters())
+
+	_, err := webhooks.Subscribe("ftp://crm.example.com", nil, "")
+	assert.Equal(ErrInvalidURL, err)
+	_, err = webhooks.Subscribe("/hooks", nil, "")
+	assert.Equal(ErrInvalidURL, err)
+	_, err = webhooks.Subscribe("https://crm.example.com/hooks", []string{"Game Begun"}, "")
+	assert.Equal(ErrUnknownEvent, err)
+
+	subscription, err := webhooks.Subscribe("https://crm.example.com/hooks", nil, "")
+	assert.Nil(err)
+	assert.Equal("webhook-1", subscription.ID)
+	assert.Len(subscription.Secret, 32)
+	assert.Empty(subscription.Events)
+
+	assert.Nil(webhooks.Unsubscribe(subscription.ID))
+	assert.Equal(ErrSubscriptionNotFound, webhooks.Unsubscribe(subscription.ID))
+	assert.Empty(webhooks.List())
+}
+
+func TestDeliver(t *testing.T) {
+	assert := assert.New(t)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	webhooks, rc, server := testWebhooks(ctx)
+	defer server.Close()
+
+	subscription, err := webhooks.Subscribe(server.URL, []string{game.GameStarted.String(), game.GameCompleted.String()}, rc.secret)
+	assert.Nil(err)
+
+	webhooks.GameEvent("main", game.NewEvent(game.GameStarted, 10))
+	webhooks.GameEvent("main", game.NewEvent(game.PlayedRound, nil))
+	webhooks.GameEvent("main", game.NewEvent(game.GameCompleted, game.GamePlayer{Name: "Steve"}))
+
+	deliveries := settled(t, webhooks, subscription.ID)
+	assert.Len(deliveries, 2)
+	for _, delivery := range deliveries {
+		assert.Equal(StatusDelivered, delivery.Status)
+		assert.Len(delivery.Attempts, 1)
+		assert.Equal(http.StatusOK, delivery.Attempts[0].StatusCode)
+	}
+
+	assert.Equal(2, rc.count())
+	types := []string{}
+	for i, payload := range rc.received {
+		assert.True(rc.verified[i])
+		assert.Equal("main", payload.Room)
+		assert.Equal(subscription.ID, payload.Subscription)
+		types = append(types, payload.Type)
+	}
+	assert.ElementsMatch([]string{"Game Started", "Game Completed"}, types)
+}
+
+func TestRetryAndDeadLetter(t *testing.T) {
+	assert := assert.New(t)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	webhooks, rc, server := testWebhooks(ctx)
+	defer server.Close()
+	subscription, _ := webhooks.Subscribe(server.URL, nil, rc.secret)
+
+	// Fails twice, then gets through on the last attempt
+	rc.statuses = []int{http.StatusInternalServerError, http.StatusServiceUnavailable}
+	webhooks.GameEvent("main", game.NewEvent(game.GameStarted, 10))
+	deliveries := settled(t, webhooks, subscription.ID)
+	assert.Equal(StatusDelivered, deliveries[0].Status)
+	assert.Len(deliveries[0].Attempts, 3)
+	assert.Equal(http.StatusInternalServerError, deliveries[0].Attempts[0].StatusCode)
+	assert.Equal("Webhook answered 500 Internal Server Error", deliveries[0].Attempts[0].Error)
+
+	// Never gets through
+	rc.mu.Lock()
+	rc.statuses = []int{http.StatusBadGateway, http.StatusBadGateway, http.StatusBadGateway}
+	rc.mu.Unlock()
+	webhooks.GameEvent("main", game.NewEvent(game.GameCompleted, nil))
+	deliveries = settled(t, webhooks, subscription.ID)
+	assert.Equal(StatusDead, deliveries[1].Status)
+	assert.Len(deliveries[1].Attempts, 3)
+
+	letters, err := webhooks.DeadLetters(subscription.ID)
+	assert.Nil(err)
+	assert.Len(letters, 1)
+	assert.Equal(deliveries[1].ID, letters[0].ID)
+	assert.Equal(6, rc.count())
+
+	// Sent again, just as it was
+	redelivery, err := webhooks.Redeliver(subscription.ID, letters[0].ID)
+	assert.Nil(err)
+	assert.Equal(letters[0].ID, redelivery.ID)
+	deliveries = settled(t, webhooks, subscription.ID)
+	assert.Equal(StatusDelivered, deliveries[2].Status)
+	assert.Equal(7, rc.count())
+	assert.Equal(rc.received[5], rc.received[6])
+	assert.True(rc.verified[6])
+
+	letters, _ = webhooks.DeadLetters(subscription.ID)
+	assert.Empty(letters)
+	_, err = webhooks.Redeliver(subscription.ID, redelivery.ID)
+	assert.Equal(ErrDeadLetterNotFound, err)
+}
+
+func TestRetriesDontHoldUpWorkers(t *testing.T) {
+	assert := assert.New(t)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	// One worker, and a dead endpoint that would keep it waiting an hour between attempts
+	webhooks := NewWebhooks(&Config{
+		Workers:     1,
+		QueueSize:   10,
+		MaxAttempts: 3,
+		Backoff:     1 * time.Hour,
+		MaxBackoff:  1 * time.Hour,
+		Timeout:     1 * time.Second,
+	}, NewMemoryDeadLetters())
+	webhooks.Start(ctx)
+	dead := &receiver{secret: "shh", statuses: []int{500, 500, 500, 500, 500}}
+	deadServer := httptest.NewServer(dead)
+	defer deadServer.Close()
+	healthy := &receiver{secret: "shh"}
+	healthyServer := httptest.NewServer(healthy)
+	defer healthyServer.Close()
+	deadSubscription, _ := webhooks.Subscribe(deadServer.URL, nil, "shh")
+	healthySubscription, _ := webhooks.Subscribe(healthyServer.URL, nil, "shh")
+
+	for i := 0; i < 5; i++ {
+		webhooks.GameEvent("main", game.NewEvent(game.PlayedRound, nil))
+	}
+	deliveries := settled(t, webhooks, healthySubscription.ID)
+	assert.Len(deliveries, 5)
+	for _, delivery := range deliveries {
+		assert.Equal(StatusDelivered, delivery.Status)
+	}
+	assert.Eventually(func() bool { return dead.count() == 5 }, time.Second, time.Millisecond)
+
+	// Stopping dead letters what's waiting to be retried, and anything sent after
+	cancel()
+	webhooks.GameEvent("main", game.NewEvent(game.GameCompleted, nil))
+	deliveries = settled(t, webhooks, deadSubscription.ID)
+	assert.Len(deliveries, 6)
+	for _, delivery := range deliveries {
+		assert.Equal(StatusDead, delivery.Status)
+		assert.Equal("Webhooks stopped", delivery.Attempts[len(delivery.Attempts)-1].Error)
+	}
+	letters, _ := webhooks.DeadLetters(deadSubscription.ID)
+	assert.Len(letters, 6)
+	assert.Equal(5, dead.count())
+}
+
+func TestBackoff(t *testing.T) {
+	assert := assert.New(t)
+	webhooks := NewWebhooks(&Config{Backoff: 1 * time.Second, MaxBackoff: 10 * time.Second}, NewMemoryDeadLetters())
+
+	for attempt, want := range []time.Duration{1, 2, 4, 8, 10, 10} {
+		assert.Equal(want*time.Second, webhooks.backoff(attempt+1))
+	}
+}
+
+func TestWebhookHandler(t *testing.T) {
+	assert := assert.New(t)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	webhooks, rc, server := testWebhooks(ctx)
+	defer server.Close()
+	handler := NewWebhookHandler(webhooks, game.NewAdminHandler(nil, "admin-token"))
+
+	router := chi.NewRouter()
+	router.Post("/webhooks", handler.Subscribe)
+	router.Get("/webhooks", handler.List)
+	router.Delete("/webhooks/{id}", handler.Unsubscribe)
+	router.Get("/webhooks/{id}/deliveries", handler.Deliveries)
+	router.Get("/webhooks/{id}/dead-letters", handler.DeadLetters)
+	router.Post("/webhooks/{id}/dead-letters/{delivery}/redeliver", handler.Redeliver)
+	request := func(method string, path string, body string, token string) *httptest.ResponseRecorder {
+		r := httptest.NewRequest(method, path, strings.NewReader(body))
+		if token != "" {
+			r.Header.Set("Authorization", "Bearer "+token)
+		}
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, r)
+		return w
+	}
+
+	w := request("POST", "/webhooks", `{"url":"`+server.URL+`"}`, "")
+	assert.Equal(http.StatusUnauthorized, w.Code)
+	w = request("POST", "/webhooks", `{"url":"crm"}`, "admin-token")
+	assert.Equal(http.StatusUnprocessableEntity, w.Code)
+	assert.Contains(w.Body.String(), "invalid_url")
+
+	w = request("POST", "/webhooks", `{"url":"`+server.URL+`","events":["Game Started"],"secret":"shh"}`, "admin-token")
+	assert.Equal(http.StatusCreated, w.Code)
+	created := SubscribeResponse{}
+	json.NewDecoder(w.Body).Decode(&created)
+	assert.Equal("webhook-1", created.ID)
+	assert.Equal("shh", created.Secret)
+
+	// The secret isn't shown again
+	w = request("GET", "/webhooks", "", "admin-token")
+	assert.Equal(http.StatusOK, w.Code)
+	assert.NotContains(w.Body.String(), "shh")
+
+	webhooks.GameEvent("main", game.NewEvent(game.GameStarted, 10))
+	settled(t, webhooks, created.ID)
+	w = request("GET", "/webhooks/webhook-1/deliveries", "", "admin-token")
+	assert.Equal(http.StatusOK, w.Code)
+	deliveries := []Delivery{}
+	json.NewDecoder(w.Body).Decode(&deliveries)
+	assert.Len(deliveries, 1)
+	assert.Equal(StatusDelivered, deliveries[0].Status)
+	assert.Equal(1, rc.count())
+
+	w = request("GET", "/webhooks/webhook-1/dead-letters", "", "admin-token")
+	assert.Equal(http.StatusOK, w.Code)
+	assert.Equal("[]\n", w.Body.String())
+	w = request("POST", "/webhooks/webhook-1/dead-letters/delivery-x/redeliver", "", "admin-token")
+	assert.Equal(http.StatusNotFound, w.Code)
+
+	w = request("DELETE", "/webhooks/webhook-1", "", "admin-token")
+	assert.Equal(http.StatusNoContent, w.Code)
+	w = request("GET", "/webhooks/webhook-1/deliveries", "", "admin-token")
+	assert.Equal(http.StatusNotFound, w.Code)
+}
@@ -0,0 +1,444 @@
+package webhook
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"log"
+	"net/http"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+
+	"networkgaming.co.uk/techtest/pkg/game"
+)
+
+const (
+	// MaxDeliveries - Deliveries kept in each subscription's log, the oldest go first
+	MaxDeliveries = 100
+)
+
+// Config - How deliveries are made.
+// Workers make one attempt at a time from a queue of QueueSize, deliveries that
+// find it full go straight to the dead letters. Each attempt gets Timeout to be
+// answered with a 2xx, failures wait off the queue for Backoff, doubling each time
+// up to MaxBackoff, then queue again until MaxAttempts have been made.
+type Config struct {
+	Workers     int
+	QueueSize   int
+	MaxAttempts int
+	Backoff     time.Duration
+	MaxBackoff  time.Duration
+	Timeout     time.Duration
+}
+
+// Webhooks - Sends the events rooms broadcast to every subscription that wants them.
+// Implements game.EventListener, add it to the watchers of every room to hear from.
+type Webhooks struct {
+	Config        *Config
+	Client        *http.Client
+	Now           func() time.Time
+	deadLetters   DeadLetterStore
+	subscriptions map[string]*Subscription
+	deliveries    map[string][]*Delivery
+	queue         chan *Delivery
+	retrying      map[*Delivery]*time.Timer
+	stopped       bool
+	next          int
+	mu            sync.Mutex
+}
+
+// NewWebhooks - Deliveries that run out of attempts are kept in deadLetters
+func NewWebhooks(config *Config, deadLetters DeadLetterStore) *Webhooks {
+	return &Webhooks{
+		Config:        config,
+		Client:        &http.Client{},
+		Now:           time.Now,
+		deadLetters:   deadLetters,
+		subscriptions: make(map[string]*Subscription),
+		deliveries:    make(map[string][]*Delivery),
+		queue:         make(chan *Delivery, config.QueueSize),
+		retrying:      make(map[*Delivery]*time.Timer),
+	}
+}
+
+// Start - Deliver until ctx is done, anything still queued or waiting to be retried
+// then is dead lettered, as is anything sent after
+func (wh *Webhooks) Start(ctx context.Context) {
+	for i := 0; i < wh.Config.Workers; i++ {
+		go func() {
+			for {
+				select {
+				case delivery := <-wh.queue:
+					wh.deliver(ctx, delivery)
+				case <-ctx.Done():
+					return
+				}
+			}
+		}()
+	}
+	go func() {
+		<-ctx.Done()
+		wh.stop()
+	}()
+}
+
+// stop - Dead letter every delivery waiting to be retried or still queued
+func (wh *Webhooks) stop() {
+	wh.mu.Lock()
+	wh.stopped = true
+	retrying := wh.retrying
+	wh.retrying = make(map[*Delivery]*time.Timer)
+	wh.mu.Unlock()
+
+	for delivery, timer := range retrying {
+		timer.Stop()
+		wh.bury(delivery, "Webhooks stopped")
+	}
+	for {
+		select {
+		case delivery := <-wh.queue:
+			wh.bury(delivery, "Webhooks stopped")
+		default:
+			return
+		}
+	}
+}
+
+// Subscribe - Send events of the given types to url, every event if there are none.
+// A secret is made up if one isn't given.
+func (wh *Webhooks) Subscribe(url string, events []string, secret string) (*Subscription, error) {
+	if events == nil {
+		events = []string{}
+	}
+	subscription := &Subscription{URL: url, Events: events, Secret: secret}
+	if err := subscription.validate(); err != nil {
+		return nil, err
+	}
+	if subscription.Secret == "" {
+		generated, err := randomID("")
+		if err != nil {
+			return nil, err
+		}
+		subscription.Secret = generated
+	}
+
+	wh.mu.Lock()
+	defer wh.mu.Unlock()
+
+	wh.next++
+	subscription.ID = fmt.Sprintf("webhook-%d", wh.next)
+	subscription.CreatedAt = wh.Now().UTC()
+	wh.subscriptions[subscription.ID] = subscription
+
+	return subscription, nil
+}
+
+// Unsubscribe - Stop sending to a subscription, deliveries already being retried are dropped
+func (wh *Webhooks) Unsubscribe(id string) error {
+	wh.mu.Lock()
+	defer wh.mu.Unlock()
+
+	if _, exists := wh.subscriptions[id]; !exists {
+		return ErrSubscriptionNotFound
+	}
+	delete(wh.subscriptions, id)
+	delete(wh.deliveries, id)
+
+	return nil
+}
+
+// Get - Look up a subscription
+func (wh *Webhooks) Get(id string) (Subscription, error) {
+	wh.mu.Lock()
+	defer wh.mu.Unlock()
+
+	subscription, exists := wh.subscriptions[id]
+	if !exists {
+		return Subscription{}, ErrSubscriptionNotFound
+	}
+
+	return *subscription, nil
+}
+
+// List - Every subscription, oldest first
+func (wh *Webhooks) List() []Subscription {
+	wh.mu.Lock()
+	defer wh.mu.Unlock()
+
+	list := make([]Subscription, 0, len(wh.subscriptions))
+	for _, subscription := range wh.subscriptions {
+		list = append(list, *subscription)
+	}
+	sort.Slice(list, func(i, j int) bool {
+		if !list[i].CreatedAt.Equal(list[j].CreatedAt) {
+			return list[i].CreatedAt.Before(list[j].CreatedAt)
+		}
+		return list[i].ID < list[j].ID
+	})
+
+	return list
+}
+
+// Deliveries - A subscription's latest deliveries, oldest first
+func (wh *Webhooks) Deliveries(id string) ([]Delivery, error) {
+	wh.mu.Lock()
+	defer wh.mu.Unlock()
+
+	if _, exists := wh.subscriptions[id]; !exists {
+		return nil, ErrSubscriptionNotFound
+	}
+	deliveries := make([]Delivery, 0, len(wh.deliveries[id]))
+	for _, delivery := range wh.deliveries[id] {
+		deliveries = append(deliveries, delivery.copy())
+	}
+
+	return deliveries, nil
+}
+
+// DeadLetters - A subscription's deliveries that ran out of attempts
+func (wh *Webhooks) DeadLetters(id string) ([]DeadLetter, error) {
+	if _, err := wh.Get(id); err != nil {
+		return nil, err
+	}
+
+	return wh.deadLetters.List(id)
+}
+
+// Redeliver - Take a dead letter out of the store and queue it again with fresh attempts
+func (wh *Webhooks) Redeliver(id string, delivery string) (Delivery, error) {
+	if _, err := wh.Get(id); err != nil {
+		return Delivery{}, err
+	}
+	letter, err := wh.deadLetters.Take(id, delivery)
+	if err != nil {
+		return Delivery{}, err
+	}
+
+	redelivery := letter.Delivery
+	redelivery.Status = StatusPending
+	redelivery.Attempts = []Attempt{}
+	redelivery.body = letter.Payload
+	wh.enqueue(&redelivery)
+
+	return wh.snapshot(&redelivery), nil
+}
+
+// GameEvent - Queue event for every subscription that wants it, see game.EventListener.
+// The event is encoded now, its data may change once the engine moves on.
+func (wh *Webhooks) GameEvent(room string, event *game.Event) {
+	subscriptions := wh.wanting(event.Type)
+	if len(subscriptions) == 0 {
+		return
+	}
+	encoded, err := json.Marshal(event)
+	if err != nil {
+		log.Printf("Webhooks - Unable to encode %s event: %s\n", event.Type, err.Error())
+		return
+	}
+
+	at := wh.Now().UTC()
+	for _, subscription := range subscriptions {
+		id, err := randomID("delivery-")
+		if err != nil {
+			log.Printf("Webhooks - Unable to create delivery: %s\n", err.Error())
+			return
+		}
+		body, err := json.Marshal(Payload{
+			ID:           id,
+			Subscription: subscription.ID,
+			Room:         room,
+			Type:         event.Type,
+			At:           at,
+			Event:        encoded,
+		})
+		if err != nil {
+			log.Printf("Webhooks - Unable to encode delivery: %s\n", err.Error())
+			return
+		}
+		wh.enqueue(&Delivery{
+			ID:           id,
+			Subscription: subscription.ID,
+			Room:         room,
+			Type:         event.Type,
+			Status:       StatusPending,
+			Attempts:     []Attempt{},
+			CreatedAt:    at,
+			body:         body,
+		})
+	}
+}
+
+// wanting - Every subscription sent events of eventType
+func (wh *Webhooks) wanting(eventType string) []Subscription {
+	wh.mu.Lock()
+	defer wh.mu.Unlock()
+
+	subscriptions := []Subscription{}
+	for _, subscription := range wh.subscriptions {
+		if subscription.wants(eventType) {
+			subscriptions = append(subscriptions, *subscription)
+		}
+	}
+
+	return subscriptions
+}
+
+// enqueue - Log the delivery and queue it, never blocks
+func (wh *Webhooks) enqueue(delivery *Delivery) {
+	wh.mu.Lock()
+	logged := append(wh.deliveries[delivery.Subscription], delivery)
+	if len(logged) > MaxDeliveries {
+		logged = logged[len(logged)-MaxDeliveries:]
+	}
+	wh.deliveries[delivery.Subscription] = logged
+	wh.mu.Unlock()
+
+	wh.push(delivery)
+}
+
+// push - Queue a delivery for the next free worker, never blocks.
+// Held under the lock so nothing is queued once stop has drained the queue.
+func (wh *Webhooks) push(delivery *Delivery) {
+	wh.mu.Lock()
+	queued := false
+	if !wh.stopped {
+		select {
+		case wh.queue <- delivery:
+			queued = true
+		default:
+		}
+	}
+	stopped := wh.stopped
+	wh.mu.Unlock()
+
+	if stopped {
+		wh.bury(delivery, "Webhooks stopped")
+	} else if !queued {
+		wh.bury(delivery, "Webhook queue full")
+	}
+}
+
+// deliver - Make one attempt, a failure is retried after its backoff unless the attempts have run out
+func (wh *Webhooks) deliver(ctx context.Context, delivery *Delivery) {
+	subscription, err := wh.Get(delivery.Subscription)
+	if err != nil {
+		// Unsubscribed since, nobody's listening
+		return
+	}
+
+	status, err := wh.post(ctx, subscription, delivery)
+	wh.mu.Lock()
+	result := Attempt{At: wh.Now().UTC(), StatusCode: status}
+	if err != nil {
+		result.Error = err.Error()
+	} else {
+		delivery.Status = StatusDelivered
+	}
+	delivery.Attempts = append(delivery.Attempts, result)
+	attempts := len(delivery.Attempts)
+	wh.mu.Unlock()
+
+	if err == nil {
+		return
+	}
+	if attempts >= wh.Config.MaxAttempts {
+		wh.bury(delivery, "")
+		return
+	}
+	wh.retry(delivery, wh.backoff(attempts))
+}
+
+// retry - Queue the delivery again after wait, the workers are free in the meantime
+func (wh *Webhooks) retry(delivery *Delivery, wait time.Duration) {
+	wh.mu.Lock()
+	if wh.stopped {
+		wh.mu.Unlock()
+		wh.bury(delivery, "")
+		return
+	}
+	wh.retrying[delivery] = time.AfterFunc(wait, func() {
+		wh.mu.Lock()
+		_, waiting := wh.retrying[delivery]
+		delete(wh.retrying, delivery)
+		wh.mu.Unlock()
+		// Not waiting any more if stop has already dead lettered it
+		if waiting {
+			wh.push(delivery)
+		}
+	})
+	wh.mu.Unlock()
+}
+
+// post - Sign and send the delivery, an error unless it's answered with a 2xx
+func (wh *Webhooks) post(ctx context.Context, subscription Subscription, delivery *Delivery) (int, error) {
+	ctx, cancel := context.WithTimeout(ctx, wh.Config.Timeout)
+	defer cancel()
+
+	request, err := http.NewRequest(http.MethodPost, subscription.URL, bytes.NewReader(delivery.body))
+	if err != nil {
+		return 0, err
+	}
+	timestamp := wh.Now().Unix()
+	request.Header.Set("Content-Type", "application/json")
+	request.Header.Set("User-Agent", "NG: Small Browser Based Game Server")
+	request.Header.Set(SignatureHeader, Sign(subscription.Secret, timestamp, delivery.body))
+	request.Header.Set(TimestampHeader, strconv.FormatInt(timestamp, 10))
+	request.Header.Set(DeliveryHeader, delivery.ID)
+	request.Header.Set(EventHeader, delivery.Type)
+
+	response, err := wh.Client.Do(request.WithContext(ctx))
+	if err != nil {
+		return 0, err
+	}
+	defer response.Body.Close()
+	// Read it all so the connection can be used again
+	io.Copy(ioutil.Discard, response.Body)
+
+	if response.StatusCode < 200 || response.StatusCode > 299 {
+		return response.StatusCode, fmt.Errorf("Webhook answered %s", response.Status)
+	}
+
+	return response.StatusCode, nil
+}
+
+// backoff - How long to wait after attempt failed
+func (wh *Webhooks) backoff(attempt int) time.Duration {
+	wait := wh.Config.Backoff
+	for i := 1; i < attempt && wait < wh.Config.MaxBackoff; i++ {
+		wait *= 2
+	}
+	if wait > wh.Config.MaxBackoff {
+		wait = wh.Config.MaxBackoff
+	}
+
+	return wait
+}
+
+// bury - Give up on a delivery and keep it in the dead letters, reason is
+// recorded as a last attempt that was never made
+func (wh *Webhooks) bury(delivery *Delivery, reason string) {
+	wh.mu.Lock()
+	if reason != "" {
+		delivery.Attempts = append(delivery.Attempts, Attempt{At: wh.Now().UTC(), Error: reason})
+	}
+	delivery.Status = StatusDead
+	letter := DeadLetter{Delivery: delivery.copy(), Payload: delivery.body, DiedAt: wh.Now().UTC()}
+	wh.mu.Unlock()
+
+	if err := wh.deadLetters.Put(letter); err != nil {
+		log.Printf("Webhooks - Unable to keep dead letter %s: %s\n", delivery.ID, err.Error())
+	}
+}
+
+// snapshot - Copy of a delivery that may be in flight
+func (wh *Webhooks) snapshot(delivery *Delivery) Delivery {
+	wh.mu.Lock()
+	defer wh.mu.Unlock()
+
+	return delivery.copy()
+}